@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"GoopBot/storage/db"
+)
+
+const backupUsage = `usage: goopbot backup [dir] [flags]
+
+Backs up the SQLite database into dir, or BACKUP_DIR, while the bot keeps running, then deletes
+all but the newest BACKUP_KEEP backups there.`
+
+const restoreUsage = `usage: goopbot restore <backup> [flags]
+
+Replaces the SQLite database with a backup after checking its integrity and schema version. The
+current database is kept next to it with a .before-restore suffix. Stop the bot first.`
+
+// runBackup implements the backup command and returns the process exit code
+func runBackup(args []string) int {
+	positional, flags := splitArgs(args)
+	if len(positional) > 1 {
+		fmt.Fprintln(os.Stderr, backupUsage)
+		return 2
+	}
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	dir := cfg.BackupDir
+	if len(positional) == 1 {
+		dir = positional[0]
+	}
+	if dir == "" {
+		fmt.Fprintln(os.Stderr, "backup directory is required (give one, or set BACKUP_DIR or -backup-dir)")
+		return 2
+	}
+
+	conn, closeDB, err := openDatabase(cfg, false)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	defer closeDB()
+
+	backup, err := db.Backup(context.Background(), conn, dir, time.Now())
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	fmt.Printf("Backed up the database to %s (%d bytes)\n", backup.Path, backup.Size)
+
+	if cfg.BackupKeep > 0 {
+		deleted, err := db.PruneBackups(dir, cfg.BackupKeep)
+		if err != nil {
+			logger.Errorf("%v", err)
+			return 1
+		}
+		if deleted > 0 {
+			fmt.Printf("Deleted %d old backup(s)\n", deleted)
+		}
+	}
+	return 0
+}
+
+// runRestore implements the restore command and returns the process exit code
+func runRestore(args []string) int {
+	positional, flags := splitArgs(args)
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, restoreUsage)
+		return 2
+	}
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.DatabaseURL != "" {
+		fmt.Fprintln(os.Stderr, "restore replaces a SQLite database; restore Postgres with pg_restore")
+		return 2
+	}
+	if cfg.DBPath == "" {
+		fmt.Fprintln(os.Stderr, errNoDatabase)
+		return 2
+	}
+
+	restored, err := db.Restore(context.Background(), positional[0], cfg.DBPath, time.Now())
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	fmt.Printf("Restored %s into %s at schema version %d\n", positional[0], cfg.DBPath, restored.SchemaVersion)
+	if restored.Previous != "" {
+		fmt.Printf("The replaced database was moved to %s\n", restored.Previous)
+	}
+	if restored.Pending > 0 {
+		fmt.Printf("The bot applies the %d pending migration(s) when it starts\n", restored.Pending)
+	}
+	return 0
+}
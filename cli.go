@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"GoopBot/internal/config"
+	"GoopBot/internal/logging"
+	"GoopBot/storage/db"
+
+	"gorm.io/gorm"
+)
+
+// splitArgs separates the arguments of a command from the configuration flags after them
+func splitArgs(args []string) (positional, flags []string) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return args[:i], args[i:]
+		}
+	}
+	return args, nil
+}
+
+// setup reads the configuration from flags for a command and creates a logger on stderr,
+// leaving the configuration unvalidated since most commands only need part of it
+func setup(flags []string) (config.Config, logging.Logger, error) {
+	cfg, err := config.Read(flags)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	return cfg, logger, nil
+}
+
+// errNoDatabase is returned when the configuration names no database
+var errNoDatabase = errors.New("database path is required (set DB_PATH or -db, or DATABASE_URL for Postgres)")
+
+// openDatabase opens the configured database and returns a function that closes it. Unless
+// create is set, a SQLite file that does not exist is an error instead of being created.
+func openDatabase(cfg config.Config, create bool) (*gorm.DB, func(), error) {
+	dsn := cfg.Database()
+	if dsn == "" {
+		return nil, nil, errNoDatabase
+	}
+	if !create && !config.IsPostgresURL(dsn) {
+		if _, err := os.Stat(dsn); err != nil {
+			return nil, nil, fmt.Errorf("failed to find database: %w", err)
+		}
+	}
+
+	conn, err := db.Open(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeDB := func() {}
+	if sqlDB, err := conn.DB(); err == nil {
+		closeDB = func() { sqlDB.Close() }
+	}
+	return conn, closeDB, nil
+}
+
+// requireLatestSchema fails unless every migration has been applied to the database
+func requireLatestSchema(ctx context.Context, conn *gorm.DB) error {
+	migrator, err := db.NewMigrator(conn, db.Migrations(), nil)
+	if err != nil {
+		return err
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version != migrator.Latest() {
+		return fmt.Errorf("database schema is at version %d but this build needs version %d; run goopbot migrate up", version, migrator.Latest())
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"GoopBot/internal/models"
+)
+
+const listCreatorsUsage = `usage: goopbot list-creators [guild] [flags]
+
+Lists the Twitch accounts linked with !linktwitch, in every server or only in guild.`
+
+// runListCreators implements the list-creators command and returns the process exit code
+func runListCreators(args []string) int {
+	positional, flags := splitArgs(args)
+	if len(positional) > 1 {
+		fmt.Fprintln(os.Stderr, listCreatorsUsage)
+		return 2
+	}
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	conn, closeDB, err := openDatabase(cfg, false)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	if err := requireLatestSchema(ctx, conn); err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+
+	query := conn.WithContext(ctx).Order("guild_id, username")
+	if len(positional) == 1 {
+		query = query.Where("guild_id = ?", positional[0])
+	}
+	var creators []models.GoopCreator
+	if err := query.Find(&creators).Error; err != nil {
+		logger.Errorf("failed to list creators: %v", err)
+		return 1
+	}
+
+	if len(creators) == 0 {
+		fmt.Println("No Twitch accounts are linked")
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "GUILD\tDISCORD ID\tUSERNAME\tTWITCH\tNOTIFICATIONS")
+	for _, c := range creators {
+		notifications := "off"
+		if c.IsActive {
+			notifications = "on"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.GuildID, c.DiscordID, c.Username, c.TwitchUsername, notifications)
+	}
+	w.Flush()
+	return 0
+}
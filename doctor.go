@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/config"
+	"GoopBot/internal/features"
+	"GoopBot/internal/features/twitchlive"
+	"GoopBot/internal/twitch"
+	"GoopBot/redis/redisutil"
+	"GoopBot/storage/db"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const doctorUsage = `usage: goopbot doctor [flags]
+
+Checks the configuration, the Discord token and gateway intents, the database schema, Redis and
+the Twitch credentials, and explains how to fix whatever is wrong.`
+
+// doctorTimeout bounds each check that talks to another service
+const doctorTimeout = 10 * time.Second
+
+// checkStatus is how a doctor check went
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarning
+	checkFailed
+	checkSkipped
+)
+
+// checkResult is what a doctor check found, and how to fix it when something is wrong
+type checkResult struct {
+	status  checkStatus
+	message string
+	fix     string
+}
+
+func passed(format string, args ...interface{}) checkResult {
+	return checkResult{status: checkOK, message: fmt.Sprintf(format, args...)}
+}
+
+func skipped(format string, args ...interface{}) checkResult {
+	return checkResult{status: checkSkipped, message: fmt.Sprintf(format, args...)}
+}
+
+func warning(fix, format string, args ...interface{}) checkResult {
+	return checkResult{status: checkWarning, message: fmt.Sprintf(format, args...), fix: fix}
+}
+
+func failed(fix, format string, args ...interface{}) checkResult {
+	return checkResult{status: checkFailed, message: fmt.Sprintf(format, args...), fix: fix}
+}
+
+// doctor runs the checks of the doctor command
+type doctor struct {
+	cfg     config.Config
+	discord *discordgo.Session // Set once the Discord token is known to work
+}
+
+// runDoctor implements the doctor command and returns the process exit code
+func runDoctor(args []string) int {
+	if positional, _ := splitArgs(args); len(positional) > 0 {
+		fmt.Fprintln(os.Stderr, doctorUsage)
+		return 2
+	}
+	cfg, err := config.Read(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	d := &doctor{cfg: cfg}
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) checkResult
+	}{
+		{"Configuration", d.checkConfig},
+		{"Discord token", d.checkDiscordToken},
+		{"Gateway intents", d.checkIntents},
+		{"Database", d.checkDatabase},
+		{"Cache", d.checkCache},
+		{"Twitch API", d.checkTwitch},
+	}
+
+	problems := 0
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+		result := check.run(ctx)
+		cancel()
+
+		icon := map[checkStatus]string{checkOK: "✅", checkWarning: "⚠️", checkFailed: "❌", checkSkipped: "⏭️"}[result.status]
+		fmt.Printf("%s %s: %s\n", icon, check.name, result.message)
+		if result.fix != "" {
+			fmt.Printf("   ↳ %s\n", result.fix)
+		}
+		if result.status == checkFailed {
+			problems++
+		}
+	}
+
+	fmt.Println()
+	if problems > 0 {
+		fmt.Printf("Found %d problem(s) that will stop the bot from working\n", problems)
+		return 1
+	}
+	fmt.Println("Everything needed to run the bot looks fine 🩺")
+	return 0
+}
+
+// checkConfig validates the configuration like the bot does on startup
+func (d *doctor) checkConfig(context.Context) checkResult {
+	if err := d.cfg.Validate(); err != nil {
+		return failed("Fix these in .env, the config file or the flags; see .env.example", "%v", err)
+	}
+	if _, err := features.Select(d.cfg.Modules); err != nil {
+		return failed("Fix MODULES; leave it empty to run every module", "%v", err)
+	}
+	return passed("valid")
+}
+
+// checkDiscordToken logs in to the Discord API with the bot token
+func (d *doctor) checkDiscordToken(ctx context.Context) checkResult {
+	if d.cfg.DiscordToken == "" {
+		return failed("Copy the token from Bot > Token in the Discord Developer Portal into DISCORD_TOKEN",
+			"no token is configured")
+	}
+	session, err := discordgo.New("Bot " + d.cfg.DiscordToken)
+	if err != nil {
+		return failed("", "failed to create a Discord session: %v", err)
+	}
+
+	user, err := session.User("@me", discordgo.WithContext(ctx))
+	if err != nil {
+		var restErr *discordgo.RESTError
+		if errors.As(err, &restErr) && restErr.Response.StatusCode == http.StatusUnauthorized {
+			return failed("Reset the token under Bot in the Discord Developer Portal and update DISCORD_TOKEN; don't include the \"Bot \" prefix",
+				"Discord rejected the token")
+		}
+		return failed("Check that this machine can reach discord.com", "failed to reach Discord: %v", err)
+	}
+	d.discord = session
+	return passed("logged in as %s (%s)", user.Username, user.ID)
+}
+
+// privilegedIntents are the gateway intents Discord only sends to applications that enabled them
+// in the Developer Portal, with the application flags that show they are: one for verified bots
+// and one for bots in fewer than 100 servers
+var privilegedIntents = []struct {
+	intent discordgo.Intent
+	name   string
+	flags  int
+}{
+	{discordgo.IntentsGuildPresences, "Presence Intent", 1<<12 | 1<<13},
+	{discordgo.IntentsGuildMembers, "Server Members Intent", 1<<14 | 1<<15},
+	{discordgo.IntentsMessageContent, "Message Content Intent", 1<<18 | 1<<19},
+}
+
+// missingIntents returns the names of the privileged intents in intents that the application
+// flags don't enable
+func missingIntents(intents discordgo.Intent, appFlags int) []string {
+	var missing []string
+	for _, p := range privilegedIntents {
+		if intents&p.intent != 0 && appFlags&p.flags == 0 {
+			missing = append(missing, p.name)
+		}
+	}
+	return missing
+}
+
+// checkIntents checks that the privileged intents the bot asks for are enabled. Without them
+// the gateway connection is refused.
+func (d *doctor) checkIntents(context.Context) checkResult {
+	if d.discord == nil {
+		return skipped("needs a working Discord token")
+	}
+	app, err := d.discord.Application("@me")
+	if err != nil {
+		return failed("Check that this machine can reach discord.com", "failed to read the application: %v", err)
+	}
+	if missing := missingIntents(bot.GatewayIntents(d.cfg), app.Flags); len(missing) > 0 {
+		return failed(fmt.Sprintf("Turn on %s under Bot > Privileged Gateway Intents at https://discord.com/developers/applications/%s/bot",
+			strings.Join(missing, " and "), app.ID),
+			"%s is not enabled for %s", strings.Join(missing, " and "), app.Name)
+	}
+	return passed("every intent the bot needs is enabled")
+}
+
+// checkDatabase opens the database and compares its schema with the migrations of this build
+func (d *doctor) checkDatabase(ctx context.Context) checkResult {
+	dsn := d.cfg.Database()
+	if dsn == "" {
+		return failed("Set DB_PATH, or DATABASE_URL for Postgres", "no database is configured")
+	}
+	if !config.IsPostgresURL(dsn) {
+		if _, err := os.Stat(dsn); errors.Is(err, os.ErrNotExist) {
+			return warning("It is created on first start; if you expected existing data, check DB_PATH",
+				"%s does not exist yet", dsn)
+		}
+	}
+
+	conn, closeDB, err := openDatabase(d.cfg, false)
+	if err != nil {
+		return failed("Check DB_PATH or DATABASE_URL, and that the database server is running", "%v", err)
+	}
+	defer closeDB()
+
+	migrator, err := db.NewMigrator(conn, db.Migrations(), nil)
+	if err != nil {
+		return failed("", "%v", err)
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return failed("Upgrade GoopBot, or check that DB_PATH or DATABASE_URL names the right database", "%v", err)
+	}
+	if version < migrator.Latest() {
+		return warning("They are applied when the bot starts, or run goopbot migrate up now",
+			"schema is at version %d, %d migration(s) pending", version, migrator.Latest()-version)
+	}
+	return passed("schema is up to date at version %d", version)
+}
+
+// checkCache pings Redis unless the cache is kept in memory
+func (d *doctor) checkCache(ctx context.Context) checkResult {
+	if d.cfg.Cache == config.CacheMemory {
+		return passed("kept in process memory, Redis is not used")
+	}
+	client := redisutil.NewClient(redisutil.Config{
+		Addr:     d.cfg.Redis.Addr,
+		Password: d.cfg.Redis.Password,
+		DB:       d.cfg.Redis.DB,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		fix := "Start Redis or fix REDIS_ADDR and REDIS_PASSWORD, or set CACHE_BACKEND=memory for a single instance"
+		if d.cfg.LeaderElection {
+			return failed(fix, "Redis at %s is unreachable and leader election needs it: %v", d.cfg.Redis.Addr, err)
+		}
+		return warning(fix, "Redis at %s is unreachable, the bot runs without a shared cache until it is back: %v", d.cfg.Redis.Addr, err)
+	}
+	return passed("Redis at %s answers", d.cfg.Redis.Addr)
+}
+
+// checkTwitch gets an access token with the Twitch credentials
+func (d *doctor) checkTwitch(context.Context) checkResult {
+	if !d.cfg.RunsModule(twitchlive.Name) {
+		return skipped("the twitch module is not enabled")
+	}
+	if d.cfg.Twitch.ClientID == "" || d.cfg.Twitch.ClientSecret == "" {
+		return failed("Register an application at https://dev.twitch.tv/console and set TWITCH_CLIENT_ID and TWITCH_CLIENT_SECRET",
+			"no credentials are configured")
+	}
+	if _, err := twitch.NewClient(twitch.Config{ClientID: d.cfg.Twitch.ClientID, ClientSecret: d.cfg.Twitch.ClientSecret}); err != nil {
+		return failed("Check TWITCH_CLIENT_ID and TWITCH_CLIENT_SECRET against https://dev.twitch.tv/console; create a new secret if needed",
+			"%v", err)
+	}
+	return passed("credentials accepted")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/config"
+	"GoopBot/storage/db"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestMissingIntents(t *testing.T) {
+	if missing := missingIntents(bot.Intents, 0); !reflect.DeepEqual(missing, []string{"Message Content Intent"}) {
+		t.Fatalf("unexpected missing intents %v", missing)
+	}
+	// Either the verified or the limited flag enables an intent
+	if missing := missingIntents(bot.Intents, 1<<18); len(missing) != 0 {
+		t.Fatalf("limited message content flag not accepted: %v", missing)
+	}
+	if missing := missingIntents(bot.Intents|discordgo.IntentsGuildMembers, 1<<19); !reflect.DeepEqual(missing, []string{"Server Members Intent"}) {
+		t.Fatalf("unexpected missing intents %v", missing)
+	}
+}
+
+func TestDoctorDatabase(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.DBPath = filepath.Join(t.TempDir(), "goopbot.db")
+	d := &doctor{cfg: cfg}
+
+	if result := d.checkDatabase(ctx); result.status != checkWarning {
+		t.Fatalf("missing database: got %+v, want a warning", result)
+	}
+
+	conn, closeDB, err := openDatabase(cfg, true)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer closeDB()
+	if result := d.checkDatabase(ctx); result.status != checkWarning || result.fix == "" {
+		t.Fatalf("pending migrations: got %+v, want a warning with a fix", result)
+	}
+
+	if err := db.Migrate(ctx, conn, nil); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if result := d.checkDatabase(ctx); result.status != checkOK {
+		t.Fatalf("migrated database: got %+v", result)
+	}
+
+	// A migration this build doesn't know means a newer build migrated the database
+	conn.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (999, 'future', CURRENT_TIMESTAMP)")
+	if result := d.checkDatabase(ctx); result.status != checkFailed {
+		t.Fatalf("newer database: got %+v, want a failure", result)
+	}
+}
+
+func TestDoctorCache(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	cfg := config.Default()
+	cfg.Redis.Addr = srv.Addr()
+	d := &doctor{cfg: cfg}
+
+	if result := d.checkCache(ctx); result.status != checkOK {
+		t.Fatalf("Redis up: got %+v", result)
+	}
+
+	srv.Close()
+	if result := d.checkCache(ctx); result.status != checkWarning {
+		t.Fatalf("Redis down: got %+v, want a warning", result)
+	}
+	d.cfg.LeaderElection = true
+	if result := d.checkCache(ctx); result.status != checkFailed {
+		t.Fatalf("Redis down with leader election: got %+v, want a failure", result)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"GoopBot/internal/logging"
+	"GoopBot/storage/db"
+)
+
+const exportUsage = `usage: goopbot export [file] [flags]
+
+Writes every record, soft-deleted ones included, as JSON to file or to stdout. Dashboard logins
+are not exported.`
+
+const importUsage = `usage: goopbot import <file> [flags]
+
+Loads a file written by goopbot export. The database is migrated first and must be empty, and
+the export must be from the same schema version.`
+
+// runExport implements the export command and returns the process exit code
+func runExport(args []string) int {
+	positional, flags := splitArgs(args)
+	if len(positional) > 1 {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		return 2
+	}
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	conn, closeDB, err := openDatabase(cfg, false)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	defer closeDB()
+
+	if len(positional) == 0 || positional[0] == "-" {
+		if _, err := db.WriteExport(context.Background(), conn, os.Stdout); err != nil {
+			logger.Errorf("%v", err)
+			return 1
+		}
+		return 0
+	}
+
+	path := positional[0]
+	file, err := os.Create(path)
+	if err != nil {
+		logger.Errorf("failed to create export file: %v", err)
+		return 1
+	}
+	counts, err := db.WriteExport(context.Background(), conn, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export file: %w", closeErr)
+	}
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Remove(path)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d rows to %s\n", total(counts), path)
+	return 0
+}
+
+// runImport implements the import command and returns the process exit code
+func runImport(args []string) int {
+	positional, flags := splitArgs(args)
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	file, err := os.Open(positional[0])
+	if err != nil {
+		logger.Errorf("failed to open export: %v", err)
+		return 1
+	}
+	defer file.Close()
+
+	conn, closeDB, err := openDatabase(cfg, true)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	if err := db.Migrate(ctx, conn, logger.With(logging.KeyComponent, "migrations")); err != nil {
+		logger.Errorf("failed to migrate database: %v", err)
+		return 1
+	}
+	counts, err := db.Import(ctx, conn, file, logger)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	fmt.Printf("Imported %d rows from %s\n", total(counts), positional[0])
+	return 0
+}
+
+// total adds up per-table row counts
+func total(counts map[string]int64) int64 {
+	var n int64
+	for _, c := range counts {
+		n += c
+	}
+	return n
+}
@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Where audit entries come from
+const (
+	AuditViaCommand   = "command"
+	AuditViaDashboard = "dashboard"
+	AuditViaAPI       = "api"
+)
+
+// How many entries !auditlog shows by default and at most
+const (
+	defaultAuditLogEntries = 10
+	maxAuditLogEntries     = 25
+)
+
+// maxAuditLogLength is where !auditlog stops adding entries, leaving room under Discord's
+// 2000-character message limit for the line that counts the rest
+const maxAuditLogLength = 1900
+
+// maxAuditValueLength shortens long before and after values in !auditlog
+const maxAuditValueLength = 100
+
+// Audit records an admin-level change in the guild's audit log and mirrors it to the guild's
+// mod-log channel, if it has one. The change has already been made, so failures are logged
+// rather than returned.
+func (b *Bot) Audit(ctx context.Context, entry models.AuditEntry) {
+	log := b.log.With(logging.KeyGuildID, entry.GuildID, logging.KeyUserID, entry.ActorID)
+	if err := b.store.AuditLog.Add(ctx, entry); err != nil {
+		log.With(logging.KeyError, err).Errorf("Failed to record audit entry for %s", entry.Action)
+	}
+
+	settings, err := b.GetGuildSettings(ctx, entry.GuildID)
+	if err != nil || settings.ModLogChannelID == "" {
+		return
+	}
+	if _, err := b.session.ChannelMessageSendEmbed(settings.ModLogChannelID, auditEmbed(entry, time.Now())); err != nil {
+		log.With(logging.KeyError, err).Warnf("Failed to post to the mod-log channel")
+	}
+}
+
+// Audit records an admin-level change made by this command in the guild's audit log
+func (c *CommandContext) Audit(action, before, after string) {
+	c.Bot.Audit(c.Context(), models.AuditEntry{
+		GuildID:   c.GuildID,
+		ActorID:   c.Author.ID,
+		ActorName: c.Author.Username,
+		Action:    action,
+		Before:    before,
+		After:     after,
+		Via:       AuditViaCommand,
+	})
+}
+
+// auditEmbed renders an audit entry for the mod-log channel
+func auditEmbed(entry models.AuditEntry, at time.Time) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title: "📝 " + entry.Action,
+		Color: 0x5865F2, // Discord blurple
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "By", Value: auditActor(entry), Inline: true},
+			{Name: "Via", Value: entry.Via, Inline: true},
+			{Name: "Before", Value: auditValue(entry.Before)},
+			{Name: "After", Value: auditValue(entry.After)},
+		},
+		Timestamp: at.Format(time.RFC3339),
+	}
+}
+
+// auditActor names who made a change, mentioning them when they are a Discord user
+func auditActor(entry models.AuditEntry) string {
+	if entry.ActorID != "" {
+		return fmt.Sprintf("<@%s>", entry.ActorID)
+	}
+	if entry.ActorName != "" {
+		return entry.ActorName
+	}
+	return "unknown"
+}
+
+// auditValue formats a before or after value, which may be empty
+func auditValue(value string) string {
+	if value == "" {
+		return "none"
+	}
+	if runes := []rune(value); len(runes) > maxAuditValueLength {
+		return string(runes[:maxAuditValueLength-1]) + "…"
+	}
+	return value
+}
+
+// channelMention formats a channel ID as a mention, or "" for no channel
+func channelMention(channelID string) string {
+	if channelID == "" {
+		return ""
+	}
+	return fmt.Sprintf("<#%s>", channelID)
+}
+
+func (b *Bot) cmdAuditLog(ctx *CommandContext) error {
+	count := defaultAuditLogEntries
+	if arg := ctx.Arg("count"); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > maxAuditLogEntries {
+			return fmt.Errorf("count must be a number from 1 to %d", maxAuditLogEntries)
+		}
+		count = n
+	}
+
+	entries, err := b.store.AuditLog.ListRecent(ctx.Context(), ctx.GuildID, count)
+	if err != nil {
+		return fmt.Errorf("failed to load the audit log: %w", err)
+	}
+	if len(entries) == 0 {
+		ctx.Reply("📝 No admin changes have been recorded on this server yet")
+		return nil
+	}
+
+	var response strings.Builder
+	fmt.Fprintf(&response, "📝 **Audit log** (latest %d):\n", len(entries))
+	for i, entry := range entries {
+		via := ""
+		if entry.Via != AuditViaCommand {
+			via = " via " + entry.Via
+		}
+		line := fmt.Sprintf("• <t:%d:R> %s%s **%s**: %s → %s\n", entry.CreatedAt.Unix(), auditActor(entry), via,
+			entry.Action, auditValue(entry.Before), auditValue(entry.After))
+		if utf8.RuneCountInString(response.String())+utf8.RuneCountInString(line) > maxAuditLogLength {
+			fmt.Fprintf(&response, "…and %d more", len(entries)-i)
+			break
+		}
+		response.WriteString(line)
+	}
+	ctx.Reply(response.String())
+	return nil
+}
+
+func (b *Bot) cmdSetModLog(ctx *CommandContext) error {
+	channelID := ctx.Arg("channel")
+	var before string
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		before, s.ModLogChannelID = s.ModLogChannelID, channelID
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to set mod-log channel: %w", err)
+	}
+	ctx.Audit("set mod-log channel", channelMention(before), channelMention(channelID))
+	ctx.Replyf("✅ Admin changes will now be posted to <#%s>", channelID)
+	return nil
+}
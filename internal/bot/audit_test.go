@@ -0,0 +1,73 @@
+package bot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/discordtest"
+	"GoopBot/internal/models"
+)
+
+func TestAuditLog(t *testing.T) {
+	b, fake := bottest.New(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!auditlog"), "No admin changes")
+
+	bottest.Send(b, fake, bottest.AdminID, "!setprefix ?")
+	entries, _ := b.Store().AuditLog.ListRecent(context.Background(), bottest.GuildID, 10)
+	if len(entries) != 1 || entries[0].Action != "set prefix" || entries[0].Before != "!" || entries[0].After != "?" || entries[0].ActorID != bottest.AdminID {
+		t.Fatalf("unexpected audit entries %+v", entries)
+	}
+
+	// Changes are mirrored into the mod-log channel once one is set
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "?setmodlog <#201>"), "posted to <#201>")
+	bottest.Send(b, fake, bottest.AdminID, "?setmemberrole <@&400>")
+	var titles []string
+	for _, m := range fake.SentTo(bottest.LiveChannelID) {
+		if m.Embed != nil {
+			titles = append(titles, m.Embed.Title)
+		}
+	}
+	if len(titles) != 1 || !strings.Contains(titles[0], "set member role") {
+		t.Fatalf("unexpected mod-log posts %q", titles)
+	}
+
+	replies := bottest.Send(b, fake, bottest.AdminID, "?auditlog 2")
+	bottest.ExpectReply(t, replies, "latest 2")
+	bottest.ExpectReply(t, replies, "**set member role**: member → Goop Creator")
+	if strings.Contains(replies[0], "set prefix") {
+		t.Fatalf("expected only the latest 2 entries, got %q", replies[0])
+	}
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "?auditlog 100"), "count must be a number from 1 to 25")
+}
+
+func TestAuditLogFitsDiscordLimit(t *testing.T) {
+	b, fake := bottest.New(t)
+
+	for i := 0; i < 25; i++ {
+		if err := b.Store().AuditLog.Add(context.Background(), models.AuditEntry{
+			GuildID:   bottest.GuildID,
+			ActorID:   "301234567890123456",
+			ActorName: "someadmin",
+			Action:    "set birthday notification channel",
+			Before:    "<#123456789012345678>",
+			After:     "<#876543210987654321>",
+			Via:       "dashboard",
+		}); err != nil {
+			t.Fatalf("failed to add audit entry: %v", err)
+		}
+	}
+
+	replies := bottest.Send(b, fake, bottest.AdminID, "!auditlog 25")
+	bottest.ExpectReply(t, replies, "latest 25")
+	if n := utf8.RuneCountInString(replies[0]); n > discordtest.MaxMessageLength {
+		t.Fatalf("audit log reply is %d characters", n)
+	}
+	if !strings.HasSuffix(replies[0], "more") || !strings.Contains(replies[0], "…and ") {
+		t.Fatalf("expected the entries that didn't fit to be counted, got %q", replies[0])
+	}
+}
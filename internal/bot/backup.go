@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/scheduler"
+	"GoopBot/storage/db"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BackupJobName is the name of the scheduled database backup job
+const BackupJobName = "database backup"
+
+// backupStatus remembers the last failed backup, which the backup directory can't show
+type backupStatus struct {
+	mu       sync.Mutex
+	failedAt time.Time
+	err      error
+}
+
+// backupJob returns the scheduled backup job. The first run waits until a backup is due,
+// counting from the newest one on disk, so restarts don't take a backup each time.
+func (b *Bot) backupJob() Job {
+	interval := b.cfg.BackupInterval.Duration
+	var delay time.Duration
+	if backups, err := db.ListBackups(b.cfg.BackupDir); err == nil && len(backups) > 0 {
+		delay = backups[0].Time.Add(interval).Sub(b.clock.Now())
+	}
+	return Job{
+		Name:         BackupJobName,
+		Schedule:     scheduler.Every(interval),
+		InitialDelay: max(delay, 0),
+		// The backups on disk, not the job history, say when one is due
+		RunAtStart: true,
+		Run: func(ctx context.Context) {
+			b.BackupDatabase(ctx)
+		},
+	}
+}
+
+// BackupDatabase backs the database up into the backup directory and deletes the backups
+// beyond the configured number to keep
+func (b *Bot) BackupDatabase(ctx context.Context) (db.BackupFile, error) {
+	log := b.log.With(logging.KeyComponent, "backup")
+	backup, err := db.Backup(ctx, b.dbConn, b.cfg.BackupDir, b.clock.Now())
+	b.metrics.BackupDone(backup.Size, err)
+
+	b.backups.mu.Lock()
+	if err != nil {
+		b.backups.failedAt, b.backups.err = b.clock.Now(), err
+	} else {
+		b.backups.err = nil
+	}
+	b.backups.mu.Unlock()
+
+	if err != nil {
+		log.With(logging.KeyError, err).Errorf("Database backup failed")
+		return db.BackupFile{}, err
+	}
+	log.Infof("Backed up the database to %s (%s)", backup.Path, formatBytes(backup.Size))
+
+	deleted, err := db.PruneBackups(b.cfg.BackupDir, b.cfg.BackupKeep)
+	if err != nil {
+		log.With(logging.KeyError, err).Warnf("Failed to delete old backups")
+	} else if deleted > 0 {
+		log.Infof("Deleted %d old backup(s)", deleted)
+	}
+	return backup, nil
+}
+
+// cmdBackup reports the latest database backup
+func (b *Bot) cmdBackup(ctx *CommandContext) error {
+	if b.cfg.BackupDir == "" {
+		return errors.New("database backups are not enabled on this bot (BACKUP_DIR is not set)")
+	}
+	backups, err := db.ListBackups(b.cfg.BackupDir)
+	if err != nil {
+		return err
+	}
+
+	var response strings.Builder
+	if len(backups) == 0 {
+		response.WriteString("💾 No database backup has been taken yet")
+	} else {
+		latest := backups[0]
+		fmt.Fprintf(&response, "💾 **Latest database backup:** %s (%s), taken <t:%d:R>\n",
+			filepath.Base(latest.Path), formatBytes(latest.Size), latest.Time.Unix())
+		fmt.Fprintf(&response, "%d backup(s) on disk, one every %s, keeping the newest %d",
+			len(backups), b.cfg.BackupInterval, b.cfg.BackupKeep)
+	}
+
+	b.backups.mu.Lock()
+	failedAt, lastErr := b.backups.failedAt, b.backups.err
+	b.backups.mu.Unlock()
+	if lastErr != nil {
+		fmt.Fprintf(&response, "\n⚠️ The last backup attempt <t:%d:R> failed: %v", failedAt.Unix(), lastErr)
+	}
+	ctx.Reply(response.String())
+	return nil
+}
+
+// formatBytes formats a size in bytes for people
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,73 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+	"GoopBot/internal/scheduler"
+	"GoopBot/storage/db"
+)
+
+func TestBackupCommand(t *testing.T) {
+	cfg := config.Default()
+	cfg.BackupDir = t.TempDir()
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Config: cfg})
+	if db.IsPostgres(b.DB()) {
+		t.Skip("backups are SQLite only")
+	}
+
+	if names := b.JobNames(); len(names) != 1 || names[0] != bot.BackupJobName {
+		t.Fatalf("expected the backup job, got %v", names)
+	}
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!backup"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!backup"), "No database backup has been taken yet")
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.BackupDatabase(context.Background()); err != nil {
+			t.Fatalf("backup: %v", err)
+		}
+	}
+	replies := bottest.Send(b, fake, bottest.AdminID, "!backup")
+	bottest.ExpectReply(t, replies, "Latest database backup:** goopbot-")
+	bottest.ExpectReply(t, replies, "keeping the newest 7")
+
+	// Without a backup directory there is no job and the command says so
+	b, fake = bottest.New(t)
+	if names := b.JobNames(); len(names) != 0 {
+		t.Fatalf("unexpected jobs %v", names)
+	}
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!backup"), "not enabled")
+}
+
+func TestBackupJobWaitsForTheNextBackup(t *testing.T) {
+	clock := scheduler.NewFakeClock(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	cfg := config.Default()
+	cfg.BackupDir = t.TempDir()
+	b, _ := bottest.NewWithDeps(t, bot.Deps{Config: cfg, Clock: clock})
+	if db.IsPostgres(b.DB()) {
+		t.Skip("backups are SQLite only")
+	}
+	if _, err := b.BackupDatabase(context.Background()); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	// An hour later a restarted bot waits for the daily backup to be due again
+	clock.Advance(time.Hour)
+	b, _ = bottest.NewWithDeps(t, bot.Deps{Config: cfg, Clock: clock})
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go b.Run(ctx)
+	due := time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC)
+	waitFor(t, "the backup to be scheduled", func() bool { return b.JobStatus(ctx)[0].NextRun.Equal(due) })
+
+	clock.WaitForTimers(1)
+	clock.Set(due)
+	waitFor(t, "the scheduled backup", func() bool {
+		backups, _ := db.ListBackups(cfg.BackupDir)
+		return len(backups) == 2 && backups[0].Time.Equal(due)
+	})
+}
@@ -0,0 +1,234 @@
+package bot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+	"GoopBot/internal/discordtest"
+	"GoopBot/internal/features/birthdays"
+	"GoopBot/internal/features/rolemessages"
+	"GoopBot/internal/features/twitchlive"
+	"GoopBot/internal/twitch"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// offline is a stream source that reports every creator as offline
+type offline struct{}
+
+func (offline) GetMultipleStreams(context.Context, []string) ([]twitch.StreamData, error) {
+	return nil, nil
+}
+
+// newTestBot builds a bot running every built-in module without touching the Twitch API
+func newTestBot(t *testing.T) (*bot.Bot, *discordtest.Session) {
+	t.Helper()
+	return bottest.New(t, twitchlive.New(offline{}), birthdays.New(), rolemessages.New())
+}
+
+// pingModule is a minimal module used to exercise module registration
+type pingModule struct {
+	name       string
+	permission bot.Permission // Who may run !ping; everyone by default
+	handled    int
+}
+
+func (m *pingModule) Name() string                                        { return m.name }
+func (m *pingModule) Init(*bot.Bot) error                                 { return nil }
+func (m *pingModule) Jobs() []bot.Job                                     { return nil }
+func (m *pingModule) Handlers() []interface{}                             { return []interface{}{m.onTyping} }
+func (m *pingModule) onTyping(*discordgo.Session, *discordgo.TypingStart) { m.handled++ }
+
+func (m *pingModule) Commands() []*bot.Command {
+	return []*bot.Command{{
+		Name:        "ping",
+		Description: "Reply with pong",
+		Permission:  m.permission,
+		Handler: func(ctx *bot.CommandContext) error {
+			ctx.Reply("🏓 pong")
+			return nil
+		},
+	}}
+}
+
+func TestHelpIsGeneratedFromRegistry(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	replies := bottest.Send(b, fake, bottest.StrangerID, "!help")
+	bottest.ExpectFirstReply(t, replies, "**Available commands:**")
+	if len(replies) < 2 {
+		t.Fatalf("expected one help message per category, got %q", replies)
+	}
+	help := strings.Join(replies, "\n")
+	for _, cmd := range b.Commands().Commands() {
+		if !strings.Contains(help, cmd.Usage("!")) {
+			t.Errorf("help text is missing %q", cmd.Usage("!"))
+		}
+	}
+
+	// Aliases resolve to the same command
+	if aliased := bottest.Send(b, fake, bottest.StrangerID, "!commands"); len(aliased) != len(replies) {
+		t.Fatalf("expected !commands to match !help, got %q", aliased)
+	}
+}
+
+func TestHelpRepliesFitDiscordLimit(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	replies := bottest.Send(b, fake, bottest.StrangerID, "!help")
+	if len(replies) == 0 {
+		t.Fatal("expected help replies")
+	}
+	for _, reply := range replies {
+		if n := utf8.RuneCountInString(reply); n > discordtest.MaxMessageLength {
+			t.Errorf("help reply is %d characters: %q", n, reply)
+		}
+	}
+}
+
+func TestIgnoresOwnAndUnknownMessages(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	if replies := bottest.Send(b, fake, bottest.BotID, "!help"); len(replies) != 0 {
+		t.Fatalf("bot replied to itself: %q", replies)
+	}
+	if replies := bottest.Send(b, fake, bottest.StrangerID, "!nosuchcommand"); len(replies) != 0 {
+		t.Fatalf("bot replied to an unknown command: %q", replies)
+	}
+	if replies := bottest.Send(b, fake, bottest.StrangerID, "hello !help"); len(replies) != 0 {
+		t.Fatalf("bot replied to a plain message: %q", replies)
+	}
+}
+
+func TestModuleCommandsAndHandlers(t *testing.T) {
+	ping := &pingModule{name: "ping"}
+	b, fake := bottest.New(t, ping)
+
+	if got := b.Modules(); len(got) != 1 || got[0] != "ping" {
+		t.Fatalf("unexpected modules: %v", got)
+	}
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!ping"), "pong")
+
+	// Commands of modules that are not loaded are not registered
+	if replies := bottest.Send(b, fake, bottest.CreatorID, "!linktwitch goopy"); len(replies) != 0 {
+		t.Fatalf("unloaded module answered: %q", replies)
+	}
+
+	b.Dispatch(&discordgo.TypingStart{GuildID: bottest.GuildID, UserID: bottest.MemberID})
+	if ping.handled != 1 {
+		t.Fatalf("module handler ran %d times, want 1", ping.handled)
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!disablemodule ping"), "Disabled the ping module")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!ping"), "ping module is disabled")
+}
+
+func TestNewRejectsInvalidModules(t *testing.T) {
+	deps := func() bot.Deps {
+		return bot.Deps{Config: config.Default(), Session: bottest.NewSession(), DB: bottest.NewDB(t)}
+	}
+
+	if _, err := bot.New(deps(), &pingModule{name: "ping"}, &pingModule{name: "ping"}); err == nil {
+		t.Fatal("expected an error for duplicate module names")
+	}
+	if _, err := bot.New(deps(), &pingModule{name: "Bad Name"}); err == nil {
+		t.Fatal("expected an error for an invalid module name")
+	}
+	if _, err := bot.New(bot.Deps{Config: config.Default(), DB: bottest.NewDB(t)}); err == nil {
+		t.Fatal("expected an error without a session")
+	}
+}
+
+func TestSlashCommandUsesTypedOptions(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	b.Dispatch(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "7000",
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   bottest.GuildID,
+		ChannelID: bottest.ChannelID,
+		Member:    &discordgo.Member{User: &discordgo.User{ID: bottest.AdminID, Username: "admin"}},
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name: "setnotifications",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: bottest.LiveChannelID},
+			},
+		},
+	}})
+
+	replies := fake.InteractionReplies()
+	if len(replies) != 1 || !strings.Contains(replies[0].Content, "<#"+bottest.LiveChannelID+">") {
+		t.Fatalf("unexpected interaction replies: %+v", replies)
+	}
+}
+
+func TestApplicationCommandsMirrorRegistry(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	if err := b.SyncApplicationCommands(bottest.BotID, bottest.GuildID); err != nil {
+		t.Fatalf("sync application commands: %v", err)
+	}
+	appCommands := fake.ApplicationCommands(bottest.GuildID)
+	if len(appCommands) != len(b.Commands().Commands()) {
+		t.Fatalf("expected %d slash commands, got %d", len(b.Commands().Commands()), len(appCommands))
+	}
+	for _, appCmd := range appCommands {
+		if appCmd.Name == "setnotifications" && appCmd.Options[0].Type != discordgo.ApplicationCommandOptionChannel {
+			t.Fatalf("setnotifications should take a channel option, got %v", appCmd.Options[0].Type)
+		}
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	r := bot.NewCommandRegistry()
+	noop := func(*bot.CommandContext) error { return nil }
+
+	if err := r.Register(&bot.Command{Name: "ping", Aliases: []string{"p"}, Handler: noop}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := r.Register(&bot.Command{Name: "p", Handler: noop}); err == nil {
+		t.Fatal("expected an error when a name collides with an alias")
+	}
+	if err := r.Register(&bot.Command{Name: "Bad Name", Handler: noop}); err == nil {
+		t.Fatal("expected an error for a name Discord would reject")
+	}
+}
+
+func TestShardFor(t *testing.T) {
+	// Discord's sharding formula: (guild_id >> 22) % shard_count
+	for _, tc := range []struct {
+		guildID string
+		count   int
+		want    int
+	}{
+		{"41771983423143937", 1, 0},
+		{"41771983423143937", 4, 2},
+		{"41771983423143937", 16, 6},
+		{"not-a-snowflake", 4, 0},
+	} {
+		if got := bot.ShardFor(tc.guildID, tc.count); got != tc.want {
+			t.Errorf("ShardFor(%s, %d) = %d, want %d", tc.guildID, tc.count, got, tc.want)
+		}
+	}
+}
+
+func TestHealthCommand(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!health"), "Administrator permissions or server ownership")
+
+	replies := bottest.Send(b, fake, bottest.AdminID, "!health")
+	bottest.ExpectReply(t, replies, "• Database: ✅")
+	bottest.ExpectReply(t, replies, "• Cache: ✅")
+	bottest.ExpectReply(t, replies, "• Shards: no gateway connection")
+
+	// Without shards this process handles every guild
+	if !b.HandlesGuild(bottest.GuildID) {
+		t.Fatalf("an unsharded bot should handle every guild")
+	}
+}
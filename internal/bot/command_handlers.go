@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/metrics"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Help sections. Modules may use these or name their own.
+const (
+	CategoryGeneral  = ""
+	categorySettings = "Server Settings (Admin only)"
+	categoryStatus   = "Bot Status (Admin only)"
+)
+
+// registerCommands registers the core commands. Feature commands come from modules.
+func (b *Bot) registerCommands() error {
+	help := &Command{
+		Name:        "help",
+		Aliases:     []string{"commands"},
+		Description: "Show this help message",
+		Category:    CategoryGeneral,
+		Handler:     b.cmdHelp,
+	}
+	health := &Command{
+		Name:        "health",
+		Description: "Show the bot's database, cache and gateway shard status",
+		Category:    categoryStatus,
+		Permission:  PermissionAdmin,
+		Handler:     b.cmdHealth,
+	}
+	backup := &Command{
+		Name:        "backup",
+		Description: "Show the latest database backup",
+		Category:    categoryStatus,
+		Permission:  PermissionAdmin,
+		Handler:     b.cmdBackup,
+	}
+	dashboard := &Command{
+		Name:        "dashboard",
+		Description: "DM you a login link for the web dashboard, or revoke every link",
+		Category:    categorySettings,
+		Args: []Arg{{Name: "action", Description: "\"revoke\" to sign out every dashboard link", Type: ArgString,
+			Complete: func(_, _, _ string) []string { return []string{"revoke"} }}},
+		Permission: PermissionAdmin,
+		Handler:    b.cmdDashboard,
+	}
+	commands := append([]*Command{help, health, backup, dashboard}, b.settingsCommands()...)
+	commands = append(commands, b.permissionCommands()...)
+	commands = append(commands, b.jobCommands()...)
+	return b.commands.Register(append(commands, b.privacyCommands()...)...)
+}
+
+// handleCommands dispatches incoming messages to registered commands
+func (b *Bot) handleCommands(_ *discordgo.Session, m *discordgo.MessageCreate) {
+	// Ignore messages from the bot itself
+	if b.IsSelf(m.Author.ID) {
+		return
+	}
+
+	// Commands are only available inside guilds
+	if m.GuildID == "" {
+		return
+	}
+
+	settings := b.GuildConfig(m.GuildID)
+	cmd, rawArgs, ok := b.commands.Match(settings.CommandPrefix, m.Content)
+	if !ok {
+		return
+	}
+
+	ctx := &CommandContext{
+		Bot:       b,
+		Session:   b.session,
+		Command:   cmd,
+		GuildID:   m.GuildID,
+		ChannelID: m.ChannelID,
+		Author:    m.Author,
+		Settings:  settings,
+		prefix:    settings.CommandPrefix,
+		respond: func(content string) error {
+			_, err := b.session.ChannelMessageSend(m.ChannelID, content)
+			return err
+		},
+	}
+
+	b.runCommand(ctx, func() (map[string]string, error) {
+		return parseArgs(cmd, rawArgs)
+	})
+}
+
+// runCommand checks permissions, parses arguments and invokes the handler.
+// It is shared by prefix commands and slash commands, which differ only in how arguments are parsed.
+func (b *Bot) runCommand(ctx *CommandContext, parse func() (map[string]string, error)) {
+	cmd := ctx.Command
+	prefix := ctx.prefix
+	ctx.Log = b.log.With(
+		logging.KeyCommand, cmd.Name,
+		logging.KeyGuildID, ctx.GuildID,
+		logging.KeyChannelID, ctx.ChannelID,
+		logging.KeyUserID, ctx.Author.ID,
+	)
+
+	if !ctx.Settings.ModuleEnabled(cmd.Module) {
+		b.metrics.CommandHandled(cmd.Name, metrics.ResultDenied)
+		ctx.Replyf("❌ The %s module is disabled on this server", cmd.Module)
+		return
+	}
+
+	if allowed, reason := b.authorize(ctx.Context(), ctx.Settings, cmd, ctx.ChannelID, ctx.Author.ID); !allowed {
+		b.metrics.CommandHandled(cmd.Name, metrics.ResultDenied)
+		ctx.Log.Debugf("Permission denied: %s", reason)
+		ctx.Replyf("❌ %s to use %s%s!", reason, prefix, cmd.Name)
+		return
+	}
+
+	if !b.allowRateLimited(ctx) {
+		b.metrics.CommandHandled(cmd.Name, metrics.ResultLimited)
+		return
+	}
+
+	args, err := parse()
+	if err != nil {
+		b.metrics.CommandHandled(cmd.Name, metrics.ResultError)
+		ctx.Replyf("❌ %v\nUsage: %s", err, cmd.Usage(prefix))
+		return
+	}
+	ctx.args = args
+
+	ctx.Log.Debugf("Running %s%s", prefix, cmd.Name)
+	if err := cmd.Handler(ctx); err != nil {
+		b.metrics.CommandHandled(cmd.Name, metrics.ResultError)
+		ctx.Log.With(logging.KeyError, err).Debugf("Command failed")
+		ctx.Replyf("❌ %v", err)
+		return
+	}
+	b.metrics.CommandHandled(cmd.Name, metrics.ResultOK)
+}
+
+// checkPermission reports whether a user may run a command with the given permission
+// under the guild's role settings, and if not, a short description of what is missing
+func (b *Bot) checkPermission(settings GuildConfig, perm Permission, channelID, userID string) (bool, string) {
+	guildID := settings.GuildID
+	switch perm {
+	case PermissionAdmin:
+		if !b.isUserAdmin(userID, channelID) {
+			return false, "You need Administrator permissions or server ownership"
+		}
+	case PermissionCreator, PermissionMember:
+		roleName := settings.MemberRole
+		if perm == PermissionCreator {
+			roleName = settings.CreatorRole
+		}
+
+		member, err := b.session.GuildMember(guildID, userID)
+		if err != nil {
+			b.log.With(logging.KeyGuildID, guildID, logging.KeyUserID, userID, logging.KeyError, err).Errorf("Failed to get guild member")
+			return false, "Could not verify your roles"
+		}
+		if !b.HasRole(guildID, member.Roles, roleName) {
+			return false, fmt.Sprintf("You need the '%s' role", roleName)
+		}
+	}
+	return true, ""
+}
+
+func (b *Bot) cmdHelp(ctx *CommandContext) error {
+	for _, section := range b.commands.HelpText(ctx.Settings) {
+		ctx.Reply(section)
+	}
+	return nil
+}
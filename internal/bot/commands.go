@@ -0,0 +1,339 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permission describes who is allowed to run a command
+type Permission int
+
+const (
+	// PermissionEveryone allows anyone in the guild to run the command
+	PermissionEveryone Permission = iota
+	// PermissionMember requires the member role ("member" by default)
+	PermissionMember
+	// PermissionCreator requires the creator role ("Goop Creator" by default)
+	PermissionCreator
+	// PermissionAdmin requires Administrator permission or server ownership
+	PermissionAdmin
+)
+
+// ArgType describes how a command argument is parsed
+type ArgType int
+
+const (
+	// ArgString is a single whitespace-delimited word
+	ArgString ArgType = iota
+	// ArgText consumes the rest of the message and must be the last argument
+	ArgText
+	// ArgChannel is a channel mention (<#id>) or a raw channel ID
+	ArgChannel
+	// ArgRole is a role mention (<@&id>), role ID or role name
+	ArgRole
+	// ArgUser is a user mention (<@id>) or a raw user ID
+	ArgUser
+)
+
+// Arg describes a single command argument
+type Arg struct {
+	Name        string
+	Description string
+	Type        ArgType
+	Required    bool
+	Default     string // Used when an optional argument is omitted
+
+	// Complete returns suggestions for a partially typed value (slash command autocomplete).
+	// Only used for ArgString and ArgText arguments.
+	Complete func(guildID, userID, partial string) []string
+}
+
+// CommandHandler runs a command. A returned error is reported back to the user.
+type CommandHandler func(ctx *CommandContext) error
+
+// Command describes a chat command and how to run it
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Category    string // Help section the command is listed under
+	Module      string // Module the command belongs to; empty for core commands that can't be disabled
+	Args        []Arg
+	Permission  Permission
+	Handler     CommandHandler
+}
+
+// Usage returns the usage line for the command, e.g. "!setrolemessage <message_id> [role_name]"
+func (c *Command) Usage(prefix string) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteString(c.Name)
+	for _, arg := range c.Args {
+		if arg.Required {
+			fmt.Fprintf(&sb, " <%s>", arg.Name)
+		} else {
+			fmt.Fprintf(&sb, " [%s]", arg.Name)
+		}
+	}
+	return sb.String()
+}
+
+// CommandContext carries everything a handler needs to run a single command invocation
+type CommandContext struct {
+	Bot       *Bot
+	Session   Session
+	Command   *Command
+	GuildID   string
+	ChannelID string
+	Author    *discordgo.User
+	Settings  GuildConfig // Effective settings of the guild the command was invoked in
+	Log       Logger      // Tagged with the command, guild, channel and user
+
+	prefix  string // "!" for chat commands, "/" for slash commands
+	args    map[string]string
+	respond func(content string) error
+}
+
+// Context returns the context for storage and API calls made while handling the command
+func (c *CommandContext) Context() context.Context {
+	return c.Bot.WorkContext()
+}
+
+// Arg returns the parsed value of the named argument, or its default when omitted
+func (c *CommandContext) Arg(name string) string {
+	return c.args[name]
+}
+
+// Reply sends a message back to where the command was invoked
+func (c *CommandContext) Reply(content string) {
+	if err := c.respond(content); err != nil {
+		c.Log.With(logging.KeyError, err).Errorf("Failed to send reply")
+	}
+}
+
+// Replyf formats and sends a message back to where the command was invoked
+func (c *CommandContext) Replyf(format string, args ...interface{}) {
+	c.Reply(fmt.Sprintf(format, args...))
+}
+
+// slashNamePattern matches names Discord accepts for application commands and options
+var slashNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// CommandRegistry holds all known commands and resolves names and aliases
+type CommandRegistry struct {
+	commands []*Command
+	lookup   map[string]*Command
+}
+
+// NewCommandRegistry creates an empty registry
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		lookup: make(map[string]*Command),
+	}
+}
+
+// Register adds commands to the registry. Names and aliases must be unique.
+func (r *CommandRegistry) Register(cmds ...*Command) error {
+	for _, cmd := range cmds {
+		if cmd.Name == "" || cmd.Handler == nil {
+			return fmt.Errorf("command %q must have a name and a handler", cmd.Name)
+		}
+		if !slashNamePattern.MatchString(cmd.Name) {
+			return fmt.Errorf("command %q: name must be lowercase letters, digits, '-' or '_'", cmd.Name)
+		}
+		for i, arg := range cmd.Args {
+			if !slashNamePattern.MatchString(arg.Name) {
+				return fmt.Errorf("command %q: argument %q must be lowercase letters, digits, '-' or '_'", cmd.Name, arg.Name)
+			}
+			if arg.Type == ArgText && i != len(cmd.Args)-1 {
+				return fmt.Errorf("command %q: text argument %q must be last", cmd.Name, arg.Name)
+			}
+			if arg.Required && i > 0 && !cmd.Args[i-1].Required {
+				return fmt.Errorf("command %q: required argument %q follows an optional one", cmd.Name, arg.Name)
+			}
+		}
+
+		names := append([]string{cmd.Name}, cmd.Aliases...)
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if existing, ok := r.lookup[key]; ok {
+				return fmt.Errorf("command name %q is already used by %q", name, existing.Name)
+			}
+		}
+		for _, name := range names {
+			r.lookup[strings.ToLower(name)] = cmd
+		}
+		r.commands = append(r.commands, cmd)
+	}
+	return nil
+}
+
+// Lookup finds a command by name or alias
+func (r *CommandRegistry) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.lookup[strings.ToLower(name)]
+	return cmd, ok
+}
+
+// Commands returns all registered commands in registration order
+func (r *CommandRegistry) Commands() []*Command {
+	return r.commands
+}
+
+// Match splits a message into a registered command and its raw argument string.
+// It returns false when the message does not start with prefix or is not a known command.
+func (r *CommandRegistry) Match(prefix, content string) (*Command, string, bool) {
+	if !strings.HasPrefix(content, prefix) {
+		return nil, "", false
+	}
+
+	rest := strings.TrimPrefix(content, prefix)
+	name, rawArgs, _ := strings.Cut(rest, " ")
+	if name == "" {
+		return nil, "", false
+	}
+
+	cmd, ok := r.Lookup(strings.TrimSpace(name))
+	if !ok {
+		return nil, "", false
+	}
+	return cmd, strings.TrimSpace(rawArgs), true
+}
+
+// HelpText builds the !help messages from the commands enabled in a guild, using the
+// guild's prefix and naming the roles that creator- and member-only commands require.
+// Each category is its own message, so the help stays within Discord's message length
+// however many commands there are.
+func (r *CommandRegistry) HelpText(settings GuildConfig) []string {
+	var categories []string
+	byCategory := make(map[string][]*Command)
+	for _, cmd := range r.commands {
+		if !settings.ModuleEnabled(cmd.Module) {
+			continue
+		}
+		if _, seen := byCategory[cmd.Category]; !seen {
+			categories = append(categories, cmd.Category)
+		}
+		byCategory[cmd.Category] = append(byCategory[cmd.Category], cmd)
+	}
+
+	var sections []string
+	for _, category := range categories {
+		var sb strings.Builder
+		if category == "" {
+			sb.WriteString("**Available commands:**\n")
+		} else {
+			fmt.Fprintf(&sb, "**%s:**\n", category)
+		}
+		for _, cmd := range byCategory[category] {
+			fmt.Fprintf(&sb, "%s - %s%s", cmd.Usage(settings.CommandPrefix), cmd.Description,
+				permissionNote(cmd.Permission, settings.CreatorRole, settings.MemberRole))
+			if len(cmd.Aliases) > 0 {
+				aliases := append([]string(nil), cmd.Aliases...)
+				sort.Strings(aliases)
+				fmt.Fprintf(&sb, " (aliases: %s%s)", settings.CommandPrefix, strings.Join(aliases, ", "+settings.CommandPrefix))
+			}
+			sb.WriteString("\n")
+		}
+		sections = append(sections, sb.String())
+	}
+	return sections
+}
+
+// permissionNote returns the help suffix describing who may run a command
+func permissionNote(p Permission, creatorRole, memberRole string) string {
+	switch p {
+	case PermissionMember:
+		return fmt.Sprintf(" (%s role required)", memberRole)
+	case PermissionCreator:
+		return fmt.Sprintf(" (%s role required)", creatorRole)
+	case PermissionAdmin:
+		return " (Admin only)"
+	default:
+		return ""
+	}
+}
+
+// usageError is returned when a command is invoked with missing or malformed arguments
+type usageError struct {
+	reason string
+}
+
+func (e *usageError) Error() string {
+	return e.reason
+}
+
+// parseArgs parses the raw argument string according to the command's argument schema
+func parseArgs(cmd *Command, raw string) (map[string]string, error) {
+	fields := strings.Fields(raw)
+	args := make(map[string]string, len(cmd.Args))
+
+	for i, arg := range cmd.Args {
+		var value string
+		if arg.Type == ArgText {
+			value = strings.Join(fields[min(i, len(fields)):], " ")
+		} else if i < len(fields) {
+			value = fields[i]
+		}
+
+		if value == "" {
+			if arg.Required {
+				return nil, &usageError{reason: fmt.Sprintf("missing %s", arg.Name)}
+			}
+			args[arg.Name] = arg.Default
+			continue
+		}
+
+		normalized, err := normalizeArg(arg, value)
+		if err != nil {
+			return nil, &usageError{reason: err.Error()}
+		}
+		args[arg.Name] = normalized
+	}
+
+	return args, nil
+}
+
+// normalizeArg strips Discord mention syntax and validates IDs
+func normalizeArg(arg Arg, value string) (string, error) {
+	switch arg.Type {
+	case ArgChannel:
+		id := strings.TrimSuffix(strings.TrimPrefix(value, "<#"), ">")
+		if !isSnowflake(id) {
+			return "", fmt.Errorf("%s must be a channel mention (e.g., #general)", arg.Name)
+		}
+		return id, nil
+	case ArgUser:
+		id := strings.TrimSuffix(strings.TrimPrefix(value, "<@"), ">")
+		id = strings.TrimPrefix(id, "!")
+		if !isSnowflake(id) {
+			return "", fmt.Errorf("%s must be a user mention (e.g., @someone)", arg.Name)
+		}
+		return id, nil
+	case ArgRole:
+		if strings.HasPrefix(value, "<@&") && strings.HasSuffix(value, ">") {
+			return value[3 : len(value)-1], nil
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// isSnowflake reports whether s looks like a Discord ID
+func isSnowflake(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"GoopBot/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// dashboardTokenTTL is how long a dashboard login link stays valid
+const dashboardTokenTTL = 24 * time.Hour
+
+// errNoDM is returned by commands that answer in a direct message the user doesn't accept
+var errNoDM = errors.New("couldn't send you a direct message; allow DMs from server members and try again")
+
+// HashDashboardToken returns what is stored for a dashboard token, so a leaked database
+// doesn't hand out logins
+func HashDashboardToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueDashboardToken creates a dashboard login for a guild on behalf of one of its admins.
+// The token is returned once and can't be recovered later.
+func (b *Bot) IssueDashboardToken(ctx context.Context, guildID, userID string) (string, time.Time, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate dashboard token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+	expires := time.Now().Add(dashboardTokenTTL)
+
+	err := b.store.DashboardTokens.Create(ctx, models.DashboardToken{
+		GuildID:   guildID,
+		TokenHash: HashDashboardToken(token),
+		IssuedBy:  userID,
+		ExpiresAt: expires,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save dashboard token: %w", err)
+	}
+	return token, expires, nil
+}
+
+// DashboardLogin returns the login a dashboard token stands for, with the guild it logs in
+// to and the admin it was issued to, or an error if the token is unknown, expired or revoked
+func (b *Bot) DashboardLogin(ctx context.Context, token string) (models.DashboardToken, error) {
+	if token == "" {
+		return models.DashboardToken{}, errors.New("no dashboard token")
+	}
+	return b.store.DashboardTokens.FindValid(ctx, HashDashboardToken(token), time.Now())
+}
+
+// DashboardURL returns the address users open the dashboard at
+func (b *Bot) DashboardURL() string {
+	if b.cfg.PublicURL != "" {
+		return strings.TrimSuffix(b.cfg.PublicURL, "/") + "/dashboard/"
+	}
+	host, port, err := net.SplitHostPort(b.cfg.HTTPAddr)
+	if err != nil {
+		return "http://" + b.cfg.HTTPAddr + "/dashboard/"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/dashboard/"
+}
+
+func (b *Bot) cmdDashboard(ctx *CommandContext) error {
+	if b.cfg.HTTPAddr == "" {
+		return errors.New("the web dashboard is not enabled on this bot (HTTP_ADDR is not set)")
+	}
+
+	switch action := ctx.Arg("action"); action {
+	case "":
+	case "revoke":
+		if err := b.store.DashboardTokens.RevokeGuild(ctx.Context(), ctx.GuildID); err != nil {
+			return fmt.Errorf("failed to revoke dashboard links: %w", err)
+		}
+		ctx.Audit("revoke dashboard links", "", "")
+		ctx.Reply("✅ Every dashboard link for this server has been revoked")
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q, use revoke or leave it out", action)
+	}
+
+	token, expires, err := b.IssueDashboardToken(ctx.Context(), ctx.GuildID, ctx.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	// The link logs anyone in, so it goes to the admin privately rather than into the channel
+	dm, err := b.session.UserChannelCreate(ctx.Author.ID)
+	if err == nil {
+		_, err = b.session.ChannelMessageSend(dm.ID, fmt.Sprintf(
+			"🔑 Your dashboard login link (keep it private, valid until %s):\n%slogin?token=%s",
+			expires.Format(time.RFC822), b.DashboardURL(), token))
+	}
+	if err != nil {
+		// The undelivered token is unknown to anyone and simply expires
+		return errNoDM
+	}
+	ctx.Audit("issue dashboard link", "", fmt.Sprintf("valid until <t:%d:f>", expires.Unix()))
+	ctx.Reply("📬 I've sent you a dashboard login link in a direct message")
+	return nil
+}
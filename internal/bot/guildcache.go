@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/metrics"
+	"GoopBot/storage/cache"
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long Discord objects stay cached. Guilds, channels and roles are kept current by
+// gateway events, so they only expire to bound staleness after a missed event. Member
+// updates only arrive with the Server Members intent, so members expire sooner.
+const (
+	guildCacheTTL  = time.Hour
+	memberCacheTTL = 5 * time.Minute
+)
+
+// Kinds of cached Discord objects, used in cache keys and metrics
+const (
+	cacheKindGuild   = "guild"
+	cacheKindChannel = "channel"
+	cacheKindRoles   = "roles"
+	cacheKindMember  = "member"
+)
+
+// guildCache keeps the guilds, channels, roles and members the bot looks up on every command
+// in the cache, so they are shared by every instance using the same Redis server. Gateway
+// events update it; lookups that miss fall through to the Discord API.
+type guildCache struct {
+	ctx     context.Context
+	cache   cache.Cache
+	metrics *metrics.Metrics
+	log     Logger
+}
+
+// discordKey is the cache key of a Discord object, e.g. discord:member:<guild>:<user>
+func discordKey(kind string, ids ...string) string {
+	key := "discord:" + kind
+	for _, id := range ids {
+		key += ":" + id
+	}
+	return key
+}
+
+// cachedLookup returns the object cached under key, or fetches and caches it. Cache
+// errors count as misses, so lookups keep working while Redis is down.
+func cachedLookup[T any](g guildCache, kind, key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	var value T
+	found, err := cache.GetJSON(g.ctx, g.cache, key, &value)
+	if err != nil {
+		g.log.With(logging.KeyError, err).Debugf("Failed to read cached %s", kind)
+	}
+	g.metrics.DiscordCacheLookup(kind, found)
+	if found {
+		return value, nil
+	}
+
+	value, err = fetch()
+	if err != nil {
+		return value, err
+	}
+	g.store(kind, key, value, ttl)
+	return value, nil
+}
+
+// store caches a Discord object, logging failures since the API stays the source of truth
+func (g guildCache) store(kind, key string, value interface{}, ttl time.Duration) {
+	if err := cache.SetJSON(g.ctx, g.cache, key, value, ttl); err != nil {
+		g.log.With(logging.KeyError, err).Debugf("Failed to cache %s", kind)
+	}
+}
+
+// forget drops cached Discord objects after they change or go away
+func (g guildCache) forget(keys ...string) {
+	if err := g.cache.Delete(g.ctx, keys...); err != nil {
+		g.log.With(logging.KeyError, err).Warnf("Failed to invalidate cached Discord objects")
+	}
+}
+
+// cachedGuild keeps the fields of a guild the bot uses; the full object includes every
+// channel, role and member
+func cachedGuild(guild *discordgo.Guild) *discordgo.Guild {
+	return &discordgo.Guild{ID: guild.ID, Name: guild.Name, Icon: guild.Icon, OwnerID: guild.OwnerID}
+}
+
+// cachedChannel keeps the fields of a channel the bot uses
+func cachedChannel(channel *discordgo.Channel) *discordgo.Channel {
+	return &discordgo.Channel{ID: channel.ID, GuildID: channel.GuildID, Name: channel.Name, Type: channel.Type, ParentID: channel.ParentID}
+}
+
+// cachedSession answers guild, channel, role and member lookups from a guildCache and passes
+// every other call on. Cached guilds and channels only have the fields cachedGuild and
+// cachedChannel keep.
+type cachedSession struct {
+	Session
+	cache guildCache
+}
+
+func (s cachedSession) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	return cachedLookup(s.cache, cacheKindGuild, discordKey(cacheKindGuild, guildID), guildCacheTTL, func() (*discordgo.Guild, error) {
+		guild, err := s.Session.Guild(guildID, options...)
+		if err != nil {
+			return nil, err
+		}
+		return cachedGuild(guild), nil
+	})
+}
+
+func (s cachedSession) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return cachedLookup(s.cache, cacheKindChannel, discordKey(cacheKindChannel, channelID), guildCacheTTL, func() (*discordgo.Channel, error) {
+		channel, err := s.Session.Channel(channelID, options...)
+		if err != nil {
+			return nil, err
+		}
+		return cachedChannel(channel), nil
+	})
+}
+
+func (s cachedSession) GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error) {
+	return cachedLookup(s.cache, cacheKindRoles, discordKey(cacheKindRoles, guildID), guildCacheTTL, func() ([]*discordgo.Role, error) {
+		return s.Session.GuildRoles(guildID, options...)
+	})
+}
+
+func (s cachedSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	return cachedLookup(s.cache, cacheKindMember, discordKey(cacheKindMember, guildID, userID), memberCacheTTL, func() (*discordgo.Member, error) {
+		return s.Session.GuildMember(guildID, userID, options...)
+	})
+}
+
+func (s cachedSession) GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	if err := s.Session.GuildMemberRoleAdd(guildID, userID, roleID, options...); err != nil {
+		return err
+	}
+	// The member update event may not arrive without the Server Members intent
+	s.cache.forget(discordKey(cacheKindMember, guildID, userID))
+	return nil
+}
+
+// handlers returns the gateway event handlers that keep the cache up to date
+func (g guildCache) handlers() []interface{} {
+	return []interface{}{
+		g.onGuildCreate, g.onGuildUpdate, g.onGuildDelete,
+		g.onChannelCreate, g.onChannelUpdate, g.onChannelDelete,
+		g.onRoleCreate, g.onRoleUpdate, g.onRoleDelete,
+		g.onMemberUpdate, g.onMemberRemove,
+	}
+}
+
+// onGuildCreate caches a guild and its channels and roles as the gateway makes it available
+func (g guildCache) onGuildCreate(_ *discordgo.Session, e *discordgo.GuildCreate) {
+	g.store(cacheKindGuild, discordKey(cacheKindGuild, e.ID), cachedGuild(e.Guild), guildCacheTTL)
+	if len(e.Roles) > 0 {
+		g.store(cacheKindRoles, discordKey(cacheKindRoles, e.ID), e.Roles, guildCacheTTL)
+	}
+	for _, channel := range e.Channels {
+		channel.GuildID = e.ID
+		g.store(cacheKindChannel, discordKey(cacheKindChannel, channel.ID), cachedChannel(channel), guildCacheTTL)
+	}
+}
+
+func (g guildCache) onGuildUpdate(_ *discordgo.Session, e *discordgo.GuildUpdate) {
+	g.store(cacheKindGuild, discordKey(cacheKindGuild, e.ID), cachedGuild(e.Guild), guildCacheTTL)
+	if len(e.Roles) > 0 {
+		g.store(cacheKindRoles, discordKey(cacheKindRoles, e.ID), e.Roles, guildCacheTTL)
+	}
+}
+
+// onGuildDelete drops a guild the bot left or that became unavailable. Its channels and
+// members expire on their own.
+func (g guildCache) onGuildDelete(_ *discordgo.Session, e *discordgo.GuildDelete) {
+	g.forget(discordKey(cacheKindGuild, e.ID), discordKey(cacheKindRoles, e.ID))
+}
+
+func (g guildCache) onChannelCreate(_ *discordgo.Session, e *discordgo.ChannelCreate) {
+	g.store(cacheKindChannel, discordKey(cacheKindChannel, e.ID), cachedChannel(e.Channel), guildCacheTTL)
+}
+
+func (g guildCache) onChannelUpdate(_ *discordgo.Session, e *discordgo.ChannelUpdate) {
+	g.store(cacheKindChannel, discordKey(cacheKindChannel, e.ID), cachedChannel(e.Channel), guildCacheTTL)
+}
+
+func (g guildCache) onChannelDelete(_ *discordgo.Session, e *discordgo.ChannelDelete) {
+	g.forget(discordKey(cacheKindChannel, e.ID))
+}
+
+// Role events carry a single role, so they drop the guild's cached role list rather than
+// edit it, which could race with another instance doing the same; the next lookup refetches it
+
+func (g guildCache) onRoleCreate(_ *discordgo.Session, e *discordgo.GuildRoleCreate) {
+	g.forget(discordKey(cacheKindRoles, e.GuildID))
+}
+
+func (g guildCache) onRoleUpdate(_ *discordgo.Session, e *discordgo.GuildRoleUpdate) {
+	g.forget(discordKey(cacheKindRoles, e.GuildID))
+}
+
+func (g guildCache) onRoleDelete(_ *discordgo.Session, e *discordgo.GuildRoleDelete) {
+	g.forget(discordKey(cacheKindRoles, e.GuildID))
+}
+
+// onMemberUpdate caches a member's new roles. It needs the Server Members intent.
+func (g guildCache) onMemberUpdate(_ *discordgo.Session, e *discordgo.GuildMemberUpdate) {
+	if e.Member == nil || e.User == nil {
+		return
+	}
+	g.store(cacheKindMember, discordKey(cacheKindMember, e.GuildID, e.User.ID), e.Member, memberCacheTTL)
+}
+
+func (g guildCache) onMemberRemove(_ *discordgo.Session, e *discordgo.GuildMemberRemove) {
+	if e.Member == nil || e.User == nil {
+		return
+	}
+	g.forget(discordKey(cacheKindMember, e.GuildID, e.User.ID))
+}
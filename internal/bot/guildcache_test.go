@@ -0,0 +1,46 @@
+package bot_test
+
+import (
+	"testing"
+
+	"GoopBot/internal/bottest"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestGuildCacheFollowsGatewayEvents(t *testing.T) {
+	b, fake := bottest.New(t)
+	bottest.Send(b, fake, bottest.AdminID, "!perms allow auditlog member")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!auditlog"), "Administrator")
+
+	// Lookups are answered from the cache until Discord tells the bot something changed
+	fake.AddMember(bottest.GuildID, bottest.StrangerID, "stranger", bottest.MemberRoleID)
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!auditlog"), "Administrator")
+	b.Dispatch(&discordgo.GuildMemberUpdate{Member: &discordgo.Member{
+		GuildID: bottest.GuildID,
+		User:    &discordgo.User{ID: bottest.StrangerID, Username: "stranger"},
+		Roles:   []string{bottest.MemberRoleID},
+	}})
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!auditlog"), "set permission rule")
+
+	if id := b.RoleID(bottest.GuildID, "member"); id != bottest.MemberRoleID {
+		t.Fatalf("RoleID(member) = %q, want %q", id, bottest.MemberRoleID)
+	}
+	fake.AddRole(bottest.GuildID, "402", "moderator")
+	if id := b.RoleID(bottest.GuildID, "moderator"); id != "" {
+		t.Fatalf("expected the cached roles before the role event, got %q", id)
+	}
+	b.Dispatch(&discordgo.GuildRoleCreate{GuildRole: &discordgo.GuildRole{GuildID: bottest.GuildID, Role: &discordgo.Role{ID: "402", Name: "moderator"}}})
+	if id := b.RoleID(bottest.GuildID, "moderator"); id != "402" {
+		t.Fatalf("RoleID(moderator) = %q after the role event, want 402", id)
+	}
+
+	// Roles the bot hands out apply straight away, without waiting for the member event
+	if err := b.Session().GuildMemberRoleAdd(bottest.GuildID, bottest.StrangerID, "402"); err != nil {
+		t.Fatalf("failed to add role: %v", err)
+	}
+	member, err := b.Session().GuildMember(bottest.GuildID, bottest.StrangerID)
+	if err != nil || len(member.Roles) != 2 {
+		t.Fatalf("expected the member's new role, got %+v (err %v)", member, err)
+	}
+}
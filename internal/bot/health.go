@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// healthCheckTimeout bounds each dependency check in Health
+const healthCheckTimeout = 2 * time.Second
+
+// Health is a snapshot of the bot's connections
+type Health struct {
+	Shards   []ShardStatus // Empty without a gateway connection
+	Database error         // Nil when the database answered a ping
+	Cache    error         // Nil when the cache answered a ping; the bot keeps running without it
+}
+
+// Health checks the database and cache and reports on every shard
+func (b *Bot) Health(ctx context.Context) Health {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	health := Health{Shards: b.ShardStatuses()}
+	if sqlDB, err := b.dbConn.DB(); err != nil {
+		health.Database = err
+	} else {
+		health.Database = sqlDB.PingContext(ctx)
+	}
+	health.Cache = b.cache.Ping(ctx)
+	return health
+}
+
+func (b *Bot) cmdHealth(ctx *CommandContext) error {
+	health := b.Health(ctx.Context())
+
+	check := func(err error) string {
+		if err != nil {
+			return fmt.Sprintf("❌ %v", err)
+		}
+		return "✅"
+	}
+
+	message := "🩺 **Bot Health:**\n"
+	message += fmt.Sprintf("• Database: %s\n", check(health.Database))
+	message += fmt.Sprintf("• Cache: %s\n", check(health.Cache))
+	if len(health.Shards) == 0 {
+		message += "• Shards: no gateway connection\n"
+	}
+	for _, shard := range health.Shards {
+		state := "❌ disconnected"
+		if shard.Connected {
+			state = "✅ connected"
+		}
+		message += fmt.Sprintf("• Shard %d/%d: %s since %s, %d guilds, %s heartbeat",
+			shard.ID, shard.Count, state, shard.Since.Format(time.RFC822), shard.Guilds, shard.Latency.Round(time.Millisecond))
+		if ShardFor(ctx.GuildID, shard.Count) == shard.ID {
+			message += " (this server)"
+		}
+		message += "\n"
+	}
+	ctx.Reply(message)
+	return nil
+}
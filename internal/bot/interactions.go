@@ -0,0 +1,223 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxAutocompleteChoices is the most choices Discord accepts in an autocomplete response
+const maxAutocompleteChoices = 25
+
+// applicationCommands converts the registered commands into Discord application command definitions
+func (b *Bot) applicationCommands() []*discordgo.ApplicationCommand {
+	dmPermission := false
+
+	var appCommands []*discordgo.ApplicationCommand
+	for _, cmd := range b.commands.Commands() {
+		// No DefaultMemberPermissions: permission rules can grant any command to members, so
+		// Discord mustn't hide them. runCommand checks permissions as it does for chat commands.
+		appCmd := &discordgo.ApplicationCommand{
+			Name:         cmd.Name,
+			Description:  truncate(cmd.Description, 100),
+			DMPermission: &dmPermission,
+		}
+		for _, arg := range cmd.Args {
+			appCmd.Options = append(appCmd.Options, applicationCommandOption(arg))
+		}
+		appCommands = append(appCommands, appCmd)
+	}
+	return appCommands
+}
+
+// applicationCommandOption converts a command argument into a typed slash command option
+func applicationCommandOption(arg Arg) *discordgo.ApplicationCommandOption {
+	description := arg.Description
+	if description == "" {
+		description = arg.Name
+	}
+
+	opt := &discordgo.ApplicationCommandOption{
+		Name:        arg.Name,
+		Description: truncate(description, 100),
+		Required:    arg.Required,
+	}
+
+	switch arg.Type {
+	case ArgChannel:
+		opt.Type = discordgo.ApplicationCommandOptionChannel
+		opt.ChannelTypes = []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews}
+	case ArgRole:
+		opt.Type = discordgo.ApplicationCommandOptionRole
+	case ArgUser:
+		opt.Type = discordgo.ApplicationCommandOptionUser
+	default:
+		opt.Type = discordgo.ApplicationCommandOptionString
+		opt.Autocomplete = arg.Complete != nil
+	}
+	return opt
+}
+
+// SyncApplicationCommands registers the slash commands with Discord, replacing any stale definitions.
+// Commands are registered for a single guild when guildID is set (instant updates), otherwise globally.
+func (b *Bot) SyncApplicationCommands(appID, guildID string) error {
+	created, err := b.session.ApplicationCommandBulkOverwrite(appID, guildID, b.applicationCommands())
+	if err != nil {
+		return err
+	}
+
+	if guildID != "" {
+		b.log.With(logging.KeyGuildID, guildID).Infof("Synced %d slash commands to guild", len(created))
+	} else {
+		b.log.Infof("Synced %d global slash commands", len(created))
+	}
+	return nil
+}
+
+// handleInteractionCreate handles slash commands and their autocomplete requests
+func (b *Bot) handleInteractionCreate(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Slash commands are only registered for guilds
+	if i.GuildID == "" || i.Member == nil {
+		return
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleSlashCommand(i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.handleAutocomplete(i)
+	}
+}
+
+// handleSlashCommand runs the registered command matching a slash command interaction
+func (b *Bot) handleSlashCommand(i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	cmd, ok := b.commands.Lookup(data.Name)
+	if !ok {
+		b.log.With(logging.KeyCommand, data.Name, logging.KeyGuildID, i.GuildID).Warnf("Received unknown slash command")
+		return
+	}
+
+	ctx := &CommandContext{
+		Bot:       b,
+		Session:   b.session,
+		Command:   cmd,
+		GuildID:   i.GuildID,
+		ChannelID: i.ChannelID,
+		Author:    i.Member.User,
+		Settings:  b.GuildConfig(i.GuildID),
+		prefix:    "/",
+		respond:   interactionResponder(b.session, i.Interaction),
+	}
+
+	b.runCommand(ctx, func() (map[string]string, error) {
+		return interactionArgs(cmd, data.Options)
+	})
+}
+
+// interactionResponder returns a reply function that answers the interaction the first time
+// and sends follow-up messages afterwards (e.g. when a background task finishes)
+func interactionResponder(s Session, interaction *discordgo.Interaction) func(string) error {
+	var (
+		mu        sync.Mutex
+		responded bool
+	)
+
+	return func(content string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !responded {
+			responded = true
+			return s.InteractionRespond(interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{Content: content},
+			})
+		}
+
+		_, err := s.FollowupMessageCreate(interaction, true, &discordgo.WebhookParams{Content: content})
+		return err
+	}
+}
+
+// interactionArgs maps slash command options onto the command's argument schema
+func interactionArgs(cmd *Command, options []*discordgo.ApplicationCommandInteractionDataOption) (map[string]string, error) {
+	values := make(map[string]string, len(options))
+	for _, opt := range options {
+		values[opt.Name] = optionValue(opt)
+	}
+
+	args := make(map[string]string, len(cmd.Args))
+	for _, arg := range cmd.Args {
+		value := strings.TrimSpace(values[arg.Name])
+		if value == "" {
+			if arg.Required {
+				return nil, &usageError{reason: fmt.Sprintf("missing %s", arg.Name)}
+			}
+			value = arg.Default
+		}
+		args[arg.Name] = value
+	}
+	return args, nil
+}
+
+// optionValue returns an option's value as a string. Channel, role and user options are IDs.
+func optionValue(opt *discordgo.ApplicationCommandInteractionDataOption) string {
+	switch v := opt.Value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// handleAutocomplete answers autocomplete requests for arguments that provide suggestions
+func (b *Bot) handleAutocomplete(i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	cmd, ok := b.commands.Lookup(data.Name)
+	if !ok {
+		return
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, opt := range data.Options {
+		if !opt.Focused {
+			continue
+		}
+		for _, arg := range cmd.Args {
+			if arg.Name != opt.Name || arg.Complete == nil {
+				continue
+			}
+			for _, suggestion := range arg.Complete(i.GuildID, i.Member.User.ID, optionValue(opt)) {
+				if len(choices) == maxAutocompleteChoices {
+					break
+				}
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+					Name:  truncate(suggestion, 100),
+					Value: suggestion,
+				})
+			}
+		}
+	}
+
+	if err := b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}); err != nil {
+		b.log.With(logging.KeyCommand, cmd.Name, logging.KeyGuildID, i.GuildID, logging.KeyError, err).Errorf("Failed to send autocomplete choices")
+	}
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
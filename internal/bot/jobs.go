@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"GoopBot/storage/cache"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jobHistory keeps when each job last ran in the cache, so with Redis every instance knows
+// and a restarted or new leader only catches up on runs that were really missed. With the
+// memory cache the history is lost on restart, and jobs then wait until they are next due.
+type jobHistory struct {
+	cache cache.Cache
+}
+
+func jobRunKey(job string) string {
+	return "job_last_run:" + job
+}
+
+func (h jobHistory) LastRun(ctx context.Context, job string) (time.Time, error) {
+	var last time.Time
+	if _, err := cache.GetJSON(ctx, h.cache, jobRunKey(job), &last); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read last run of %s: %w", job, err)
+	}
+	return last, nil
+}
+
+func (h jobHistory) SetLastRun(ctx context.Context, job string, t time.Time) error {
+	return cache.SetJSON(ctx, h.cache, jobRunKey(job), t, 0)
+}
+
+func (b *Bot) jobCommands() []*Command {
+	return []*Command{{
+		Name:        "jobs",
+		Description: "Show when each background job last ran and runs next",
+		Category:    categoryStatus,
+		Permission:  PermissionAdmin,
+		Handler:     b.cmdJobs,
+	}}
+}
+
+func (b *Bot) cmdJobs(ctx *CommandContext) error {
+	statuses := b.JobStatus(ctx.Context())
+	if len(statuses) == 0 {
+		ctx.Reply("⏱️ No background jobs are scheduled")
+		return nil
+	}
+
+	var response strings.Builder
+	response.WriteString("⏱️ **Background jobs:**\n")
+	for _, st := range statuses {
+		fmt.Fprintf(&response, "• **%s** (%s): ", st.Name, st.Schedule)
+		if st.LastRun.IsZero() {
+			response.WriteString("never ran")
+		} else {
+			fmt.Fprintf(&response, "last run <t:%d:R>", st.LastRun.Unix())
+			if st.Runs > 0 && !st.Running {
+				fmt.Fprintf(&response, " took %s", st.Duration.Round(time.Millisecond))
+			}
+		}
+		switch {
+		case st.Running:
+			response.WriteString(", 🔄 running now")
+		case !st.Active:
+			response.WriteString(", 💤 another instance runs it")
+		case !st.NextRun.IsZero():
+			fmt.Fprintf(&response, ", next <t:%d:R>", st.NextRun.Unix())
+		}
+		if st.Skipped > 0 {
+			fmt.Fprintf(&response, " ⚠️ %d run(s) skipped while the previous one was still going", st.Skipped)
+		}
+		response.WriteString("\n")
+	}
+	fmt.Fprintf(&response, "Cron schedules use the %s time zone", b.location)
+	ctx.Reply(response.String())
+	return nil
+}
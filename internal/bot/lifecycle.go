@@ -0,0 +1,237 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/scheduler"
+	"GoopBot/storage/cache"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Run starts the module jobs and blocks until ctx is cancelled.
+// Call Close afterwards to wait for in-flight work and release resources.
+func (b *Bot) Run(ctx context.Context) {
+	b.log.Infof("Bot is running!")
+
+	for _, job := range b.jobs {
+		b.leadJob(ctx, job)
+	}
+	b.scheduler.Start(ctx)
+
+	<-ctx.Done()
+	b.log.Infof("Shutdown requested, stopping background work...")
+}
+
+// scheduleJobs adds the module jobs to the scheduler. Runs get the work context so an
+// in-flight run can finish during shutdown.
+func (b *Bot) scheduleJobs() error {
+	b.scheduler = scheduler.New(scheduler.Options{
+		Clock:   b.clock,
+		History: jobHistory{b.cache},
+		Logger:  b.log.With(logging.KeyComponent, "scheduler"),
+		Context: b.workCtx,
+		Go:      b.StartTask,
+	})
+	for _, job := range b.jobs {
+		err := b.scheduler.Add(scheduler.Job{
+			Name:         job.Name,
+			Schedule:     job.Schedule,
+			InitialDelay: job.InitialDelay,
+			Jitter:       job.Jitter,
+			RunAtStart:   job.RunAtStart,
+			Active:       func() bool { return b.leadsJob(job.Name) },
+			Run:          job.Run,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leadJob campaigns for a job until ctx is cancelled. With an elector, scheduled runs are
+// skipped while another instance leads the job, and an instance that takes over a job
+// catches up on a run the previous leader missed.
+func (b *Bot) leadJob(ctx context.Context, job Job) {
+	leading, gained := b.campaign(ctx, b.leaseName(job.Name), b.log.With("job", job.Name))
+	b.leading.Store(job.Name, leading)
+	if gained == nil {
+		return
+	}
+	b.StartTask(func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-gained:
+				b.scheduler.CatchUp(job.Name)
+			}
+		}
+	})
+}
+
+// leadsJob reports whether this instance runs a job's scheduled runs
+func (b *Bot) leadsJob(name string) bool {
+	leading, ok := b.leading.Load(name)
+	return !ok || leading.(*atomic.Bool).Load()
+}
+
+var (
+	// ErrUnknownJob is returned by RunJob for a name no module registered
+	ErrUnknownJob = scheduler.ErrUnknownJob
+	// ErrJobRunning is returned by RunJob while the job is still running
+	ErrJobRunning = scheduler.ErrRunning
+)
+
+// JobNames returns the names of the module jobs, in registration order
+func (b *Bot) JobNames() []string {
+	return b.scheduler.Names()
+}
+
+// JobStatus returns the status of every job, in registration order
+func (b *Bot) JobStatus(ctx context.Context) []scheduler.Status {
+	return b.scheduler.Status(ctx)
+}
+
+// RunJob starts one run of a job in the background straight away, outside its schedule.
+// It runs on this instance whoever holds the job's lease, like the manual check commands,
+// but not while the job is already running.
+func (b *Bot) RunJob(name string) error {
+	err := b.scheduler.RunNow(name)
+	if errors.Is(err, scheduler.ErrStopped) {
+		return errors.New("the bot is shutting down")
+	}
+	if err != nil {
+		return err
+	}
+	b.log.With("job", name).Infof("Running %s on request", name)
+	return nil
+}
+
+// campaign holds or waits for the lease on a job until ctx is cancelled, then releases it.
+// leading reports whether this instance runs the job right now; gained receives when the
+// lease passes to this instance. Without an elector this instance always leads.
+func (b *Bot) campaign(ctx context.Context, name string, log Logger) (leading *atomic.Bool, gained <-chan struct{}) {
+	leading = new(atomic.Bool)
+	if b.elector == nil {
+		leading.Store(true)
+		return leading, nil
+	}
+
+	gainedCh := make(chan struct{}, 1)
+	// try renews or takes the lease and reports whether this instance just became the leader
+	try := func() bool {
+		held, err := b.elector.Acquire(ctx, name)
+		if err != nil {
+			// Without the lease store nobody can tell who leads, so step down rather than risk running twice
+			log.With(logging.KeyError, err).Warnf("Failed to renew the lease on %s", name)
+			held = false
+		}
+		was := leading.Swap(held)
+		switch {
+		case held && !was:
+			log.Infof("This instance now runs %s", name)
+		case !held && was:
+			log.Warnf("This instance no longer runs %s", name)
+		}
+		return held && !was
+	}
+	try()
+
+	b.StartTask(func() {
+		ticker := time.NewTicker(b.elector.RenewInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if leading.Load() {
+					// Hand the job over now instead of when the lease expires
+					if err := b.elector.Release(b.workCtx, name); err != nil {
+						log.With(logging.KeyError, err).Warnf("Failed to release the lease on %s", name)
+					}
+				}
+				return
+			case <-ticker.C:
+				if try() {
+					select {
+					case gainedCh <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	})
+	return leading, gainedCh
+}
+
+// StartTask runs fn in a goroutine that Close waits for. It returns false without
+// running fn once the bot has started shutting down.
+func (b *Bot) StartTask(fn func()) bool {
+	b.tasksMu.Lock()
+	defer b.tasksMu.Unlock()
+
+	if b.closing {
+		return false
+	}
+
+	b.tasks.Add(1)
+	go func() {
+		defer b.tasks.Done()
+		fn()
+	}()
+	return true
+}
+
+// Close waits for in-flight stream checks and notification sends to finish, then closes
+// the gateway sessions, the cache and the database. If ctx expires first, in-flight work is
+// cancelled and resources are closed anyway.
+func (b *Bot) Close(ctx context.Context) error {
+	b.tasksMu.Lock()
+	b.closing = true
+	b.tasksMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.tasks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		b.log.Infof("All background work finished")
+	case <-ctx.Done():
+		b.log.Warnf("Timed out waiting for background work, cancelling: %v", ctx.Err())
+		b.cancelWork()
+		// Give cancelled work a moment to notice before pulling connections out from under it
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+		}
+	}
+	b.cancelWork()
+
+	errs := b.closeShards()
+	errs = append(errs, closeStorage(b.dbConn, b.cache)...)
+	return errors.Join(errs...)
+}
+
+// closeStorage closes the database connection and, if it was opened, the cache
+func closeStorage(dbConn *gorm.DB, c cache.Cache) []error {
+	var errs []error
+	if c != nil {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close cache: %w", err))
+		}
+	}
+	if sqlDB, err := dbConn.DB(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get database handle: %w", err))
+	} else if err := sqlDB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+	}
+	return errs
+}
@@ -0,0 +1,97 @@
+package bot_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/scheduler"
+	"GoopBot/redis/leader"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// jobModule has one hourly job that counts its runs and runs at start
+type jobModule struct {
+	runs atomic.Int32
+}
+
+func (m *jobModule) Name() string             { return "counter" }
+func (m *jobModule) Init(*bot.Bot) error      { return nil }
+func (m *jobModule) Commands() []*bot.Command { return nil }
+func (m *jobModule) Handlers() []interface{}  { return nil }
+func (m *jobModule) Jobs() []bot.Job {
+	return []bot.Job{{Name: "count", Schedule: scheduler.Every(time.Hour), RunAtStart: true, Run: func(context.Context) { m.runs.Add(1) }}}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestJobsRunOnTheLeaderOnly(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	// Two instances sharing one Redis server
+	first, second := &jobModule{}, &jobModule{}
+	a, _ := bottest.NewWithDeps(t, bot.Deps{Elector: leader.New(client, 300*time.Millisecond)}, first)
+	b, _ := bottest.NewWithDeps(t, bot.Deps{Elector: leader.New(client, 300*time.Millisecond)}, second)
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	go a.Run(ctxA)
+	waitFor(t, "the first instance to run the job", func() bool { return first.runs.Load() == 1 })
+	go b.Run(ctxB)
+
+	// The second instance stands by while the first holds the lease
+	time.Sleep(300 * time.Millisecond)
+	if runs := second.runs.Load(); runs != 0 {
+		t.Fatalf("the standby instance ran the job %d times", runs)
+	}
+
+	// Stopping the leader hands the job over, and the new leader runs it straight away
+	stopA()
+	waitFor(t, "the job to fail over", func() bool { return second.runs.Load() == 1 })
+	if runs := first.runs.Load(); runs != 1 {
+		t.Fatalf("the old leader ran the job %d times, want 1", runs)
+	}
+}
+
+func TestJobsCommand(t *testing.T) {
+	clock := scheduler.NewFakeClock(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	counter := &jobModule{}
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Clock: clock}, counter)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!jobs"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!jobs"), "**count** (every 1h): never ran")
+
+	// A job that runs at start and never ran runs straight away, then on its schedule
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go b.Run(ctx)
+	waitFor(t, "the job to catch up", func() bool {
+		status := b.JobStatus(ctx)[0]
+		return status.Runs == 1 && !status.Running && !status.NextRun.IsZero()
+	})
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!jobs"), "last run <t:1717236000:R> took 0s, next <t:1717239600:R>")
+
+	clock.Advance(time.Hour)
+	waitFor(t, "the scheduled run", func() bool { return counter.runs.Load() == 2 })
+	if err := b.RunJob("count"); err != nil {
+		t.Fatalf("RunJob: %v", err)
+	}
+	waitFor(t, "the manual run", func() bool { return counter.runs.Load() == 3 })
+}
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"GoopBot/internal/config"
+	"GoopBot/internal/metrics"
+	"GoopBot/internal/ratelimit"
+	"GoopBot/internal/repository"
+	"GoopBot/internal/scheduler"
+	"GoopBot/storage/cache"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"gorm.io/gorm"
+)
+
+// Module is a self-contained feature such as Twitch notifications or birthdays.
+// The bot registers its commands and event handlers and runs its jobs. Its tables are
+// created by the migrations in storage/db.
+type Module interface {
+	// Name identifies the module in configuration and per-guild settings, e.g. "twitch"
+	Name() string
+	// Init is called once, before the module's commands are registered
+	Init(b *Bot) error
+	// Commands returns the commands the module provides. They are only available in
+	// guilds that have the module enabled.
+	Commands() []*Command
+	// Handlers returns discordgo event handlers, e.g. func(*discordgo.Session, *discordgo.MessageReactionAdd).
+	// Handlers must check GuildConfig(guildID).ModuleEnabled themselves.
+	Handlers() []interface{}
+	// Jobs returns background work to run while the bot is up
+	Jobs() []Job
+}
+
+// Job is scheduled background work owned by a module. A job that missed a run while no
+// instance was up runs straight away, once InitialDelay has passed. A job with no recorded
+// run waits until it is due, unless RunAtStart is set.
+type Job struct {
+	Name         string
+	Schedule     scheduler.Schedule // e.g. scheduler.Every(5*time.Minute) or a cron expression from scheduler.ParseCron
+	InitialDelay time.Duration      // Wait after startup before the job may first run
+	Jitter       time.Duration      // Delay each scheduled run by a random duration of up to this
+	RunAtStart   bool               // Run once InitialDelay has passed when no earlier run is recorded, e.g. for polling
+	Run          func(ctx context.Context)
+}
+
+// Elector decides which of several bot instances runs each job. An instance runs a job only
+// while it holds the job's lease, renewing it every RenewInterval; a lease that is not renewed
+// expires and another instance takes over. See redis/leader.
+type Elector interface {
+	// Acquire takes or renews the lease on name and reports whether this instance holds it
+	Acquire(ctx context.Context, name string) (bool, error)
+	// Release gives up the lease on name if this instance holds it
+	Release(ctx context.Context, name string) error
+	// RenewInterval is how often Acquire must be called to keep a lease
+	RenewInterval() time.Duration
+}
+
+// Deps are the services a Bot runs on. NewBot connects them from the configuration;
+// tests supply an in-memory database and a fake session.
+type Deps struct {
+	Config  config.Config
+	Logger  Logger // Optional; nil discards log output
+	Session Session
+	DB      *gorm.DB          // Must already be migrated, see storage/db
+	Store   *repository.Store // Optional; defaults to a GORM store on DB
+	Cache   cache.Cache       // Optional; defaults to an in-memory cache
+	Elector Elector           // Optional; without one this instance runs every job
+	Metrics *metrics.Metrics  // Optional; defaults to a fresh set nobody serves
+	Limiter ratelimit.Limiter // Optional; defaults to limiting in process memory
+	Clock   scheduler.Clock   // Optional; defaults to the system clock
+}
+
+// sessionType is the first parameter of every event handler
+var sessionType = reflect.TypeOf((*discordgo.Session)(nil))
+
+// addHandler records an event handler after checking it has the shape discordgo expects
+func (b *Bot) addHandler(handler interface{}) error {
+	t := reflect.TypeOf(handler)
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 2 || t.In(0) != sessionType || t.In(1).Kind() != reflect.Ptr {
+		return fmt.Errorf("event handler %T must be a func(*discordgo.Session, *discordgo.Event)", handler)
+	}
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+// Dispatch delivers a gateway event, such as *discordgo.MessageCreate, to every handler
+// that accepts it. The live gateway calls handlers directly; Dispatch lets tests feed events.
+func (b *Bot) Dispatch(event interface{}) {
+	eventType := reflect.TypeOf(event)
+	for _, handler := range b.handlers {
+		h := reflect.ValueOf(handler)
+		if h.Type().In(1) == eventType {
+			h.Call([]reflect.Value{reflect.Zero(sessionType), reflect.ValueOf(event)})
+		}
+	}
+}
+
+// initModules registers the core and module commands, handlers and jobs
+func (b *Bot) initModules(modules []Module) error {
+	seen := make(map[string]bool)
+	for _, m := range modules {
+		if !slashNamePattern.MatchString(m.Name()) {
+			return fmt.Errorf("module name %q must be lowercase letters, digits, '-' or '_'", m.Name())
+		}
+		if seen[m.Name()] {
+			return fmt.Errorf("module %q is registered twice", m.Name())
+		}
+		seen[m.Name()] = true
+	}
+
+	core := []interface{}{b.handleReady, b.handleCommands, b.handleInteractionCreate}
+	for _, handler := range append(core, b.guilds.handlers()...) {
+		if err := b.addHandler(handler); err != nil {
+			return err
+		}
+	}
+	if err := b.registerCommands(); err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		if err := m.Init(b); err != nil {
+			return fmt.Errorf("failed to initialize module %s: %w", m.Name(), err)
+		}
+
+		cmds := m.Commands()
+		for _, cmd := range cmds {
+			cmd.Module = m.Name()
+		}
+		if err := b.commands.Register(cmds...); err != nil {
+			return fmt.Errorf("module %s: %w", m.Name(), err)
+		}
+
+		for _, handler := range m.Handlers() {
+			if err := b.addHandler(handler); err != nil {
+				return fmt.Errorf("module %s: %w", m.Name(), err)
+			}
+		}
+
+		for _, job := range m.Jobs() {
+			if job.Schedule == nil || job.Run == nil {
+				return fmt.Errorf("module %s: job %q needs a schedule and a run function", m.Name(), job.Name)
+			}
+			b.jobs = append(b.jobs, job)
+		}
+		b.modules = append(b.modules, m)
+	}
+	return nil
+}
+
+// Modules returns the names of the modules this bot runs, in registration order
+func (b *Bot) Modules() []string {
+	names := make([]string, len(b.modules))
+	for i, m := range b.modules {
+		names[i] = m.Name()
+	}
+	return names
+}
+
+// hasModule reports whether a module with this name is running
+func (b *Bot) hasModule(name string) bool {
+	for _, m := range b.modules {
+		if m.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Accessors for modules
+
+// Config returns the bot configuration
+func (b *Bot) Config() config.Config {
+	return b.cfg
+}
+
+// Logger returns the bot logger
+func (b *Bot) Logger() Logger {
+	return b.log
+}
+
+// Session returns the Discord API session
+func (b *Bot) Session() Session {
+	return b.session
+}
+
+// DB returns the database connection. Modules read and write their records through Store.
+func (b *Bot) DB() *gorm.DB {
+	return b.dbConn
+}
+
+// Store returns the repositories modules read and write their records through
+func (b *Bot) Store() *repository.Store {
+	return b.store
+}
+
+// Cache returns the cache for short-lived data. Its errors are not fatal: treat them as a
+// miss and read from Store.
+func (b *Bot) Cache() cache.Cache {
+	return b.cache
+}
+
+// Metrics returns the metrics to record on
+func (b *Bot) Metrics() *metrics.Metrics {
+	return b.metrics
+}
+
+// Commands returns the command registry
+func (b *Bot) Commands() *CommandRegistry {
+	return b.commands
+}
+
+// Location returns the time zone schedules and birthdays go by
+func (b *Bot) Location() *time.Location {
+	return b.location
+}
+
+// Now returns the current time in Location, from the clock the jobs are scheduled by
+func (b *Bot) Now() time.Time {
+	return b.clock.Now().In(b.location)
+}
+
+// WorkContext is the context for background work. It is only cancelled if shutdown times out.
+func (b *Bot) WorkContext() context.Context {
+	return b.workCtx
+}
@@ -0,0 +1,386 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"GoopBot/storage/cache"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// permissionRulesCacheTTL is how long a guild's permission rules stay cached
+const permissionRulesCacheTTL = time.Hour
+
+// Permission rule targets other than a single command
+const (
+	permissionTargetAll    = "all"     // Every command
+	permissionModulePrefix = "module:" // Every command of a module, e.g. module:twitch
+	coreModuleName         = "core"    // Stands in for the module of core commands
+)
+
+// Who a permission rule applies to
+const (
+	ruleSubjectRole = "role"
+	ruleSubjectUser = "user"
+)
+
+// permissionRulesKey is the cache key a guild's permission rules are cached under
+func permissionRulesKey(guildID string) string {
+	return fmt.Sprintf("permission_rules:%s", guildID)
+}
+
+// PermissionRules returns a guild's permission rules, from the cache when possible
+func (b *Bot) PermissionRules(ctx context.Context, guildID string) ([]models.PermissionRule, error) {
+	log := b.log.With(logging.KeyGuildID, guildID)
+	var rules []models.PermissionRule
+
+	found, err := cache.GetJSON(ctx, b.cache, permissionRulesKey(guildID), &rules)
+	if err != nil {
+		log.With(logging.KeyError, err).Debugf("Failed to read cached permission rules")
+	} else if found {
+		return rules, nil
+	}
+
+	rules, err = b.store.PermissionRules.ListByGuild(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permission rules: %w", err)
+	}
+
+	// Guilds without rules are cached too, so commands don't hit the database every time
+	if err := cache.SetJSON(ctx, b.cache, permissionRulesKey(guildID), rules, permissionRulesCacheTTL); err != nil {
+		log.With(logging.KeyError, err).Debugf("Failed to cache permission rules")
+	}
+	return rules, nil
+}
+
+// SetPermissionRule creates or replaces a guild's rule for the rule's target and subject
+func (b *Bot) SetPermissionRule(ctx context.Context, rule models.PermissionRule) error {
+	if err := b.store.PermissionRules.Set(ctx, rule); err != nil {
+		return fmt.Errorf("failed to save permission rule: %w", err)
+	}
+	b.invalidatePermissionRules(ctx, rule.GuildID)
+	return nil
+}
+
+// RemovePermissionRule deletes a guild's rule for a target and subject. It returns
+// repository.ErrNotFound if there is no such rule.
+func (b *Bot) RemovePermissionRule(ctx context.Context, guildID, target, subjectID string) error {
+	if err := b.store.PermissionRules.Remove(ctx, guildID, target, subjectID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to remove permission rule: %w", err)
+	}
+	b.invalidatePermissionRules(ctx, guildID)
+	return nil
+}
+
+// invalidatePermissionRules drops a guild's cached rules after they change
+func (b *Bot) invalidatePermissionRules(ctx context.Context, guildID string) {
+	if err := b.cache.Delete(ctx, permissionRulesKey(guildID)); err != nil {
+		b.log.With(logging.KeyGuildID, guildID, logging.KeyError, err).Warnf("Failed to invalidate cached permission rules")
+	}
+}
+
+// authorize reports whether a user may run a command, and if not, why. Administrators and
+// the server owner may run everything, so a rule can never lock them out. Otherwise the
+// guild's most specific matching permission rule decides, and without one the command's
+// default permission applies.
+func (b *Bot) authorize(ctx context.Context, settings GuildConfig, cmd *Command, channelID, userID string) (bool, string) {
+	guildID := settings.GuildID
+	log := b.log.With(logging.KeyGuildID, guildID, logging.KeyUserID, userID)
+
+	rules, err := b.PermissionRules(ctx, guildID)
+	if err != nil {
+		log.With(logging.KeyError, err).Errorf("Using default command permissions")
+	}
+	var relevant []models.PermissionRule
+	for _, rule := range rules {
+		if ruleTargetRank(rule.Target, cmd) > 0 {
+			relevant = append(relevant, rule)
+		}
+	}
+	if len(relevant) == 0 {
+		return b.checkPermission(settings, cmd.Permission, channelID, userID)
+	}
+
+	if b.isUserAdmin(userID, channelID) {
+		return true, ""
+	}
+	member, err := b.session.GuildMember(guildID, userID)
+	if err != nil {
+		log.With(logging.KeyError, err).Errorf("Failed to get guild member")
+		return false, "Could not verify your roles"
+	}
+
+	rule, ok := decidingRule(relevant, cmd, guildID, userID, member.Roles)
+	if !ok {
+		return b.checkPermission(settings, cmd.Permission, channelID, userID)
+	}
+	if !rule.Allow {
+		return false, "A permission rule on this server doesn't allow you"
+	}
+	return true, ""
+}
+
+// decidingRule picks the rule that decides whether a member may run a command: the one
+// with the most specific target (command, then module, then all) and, among those, the
+// most specific subject (the member, then one of their roles, then @everyone). A deny
+// wins over an allow that is just as specific.
+func decidingRule(rules []models.PermissionRule, cmd *Command, guildID, userID string, roles []string) (models.PermissionRule, bool) {
+	var (
+		best      models.PermissionRule
+		bestScore int
+	)
+	for _, rule := range rules {
+		targetRank := ruleTargetRank(rule.Target, cmd)
+		subjectRank := ruleSubjectRank(rule, guildID, userID, roles)
+		if targetRank == 0 || subjectRank == 0 {
+			continue
+		}
+		score := targetRank*10 + subjectRank
+		if score > bestScore || (score == bestScore && !rule.Allow) {
+			best, bestScore = rule, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// ruleTargetRank rates how specifically a rule target names a command, or 0 if it doesn't
+func ruleTargetRank(target string, cmd *Command) int {
+	switch target {
+	case cmd.Name:
+		return 3
+	case permissionModulePrefix + commandModule(cmd):
+		return 2
+	case permissionTargetAll:
+		return 1
+	}
+	return 0
+}
+
+// ruleSubjectRank rates how specifically a rule's subject names a member, or 0 if it doesn't
+func ruleSubjectRank(rule models.PermissionRule, guildID, userID string, roles []string) int {
+	if rule.SubjectType == ruleSubjectUser {
+		if rule.SubjectID == userID {
+			return 3
+		}
+		return 0
+	}
+	// The @everyone role has the guild's ID and isn't listed in a member's roles
+	if rule.SubjectID == guildID {
+		return 1
+	}
+	for _, roleID := range roles {
+		if roleID == rule.SubjectID {
+			return 2
+		}
+	}
+	return 0
+}
+
+// commandModule returns the module a command belongs to, naming core commands "core"
+func commandModule(cmd *Command) string {
+	if cmd.Module == "" {
+		return coreModuleName
+	}
+	return cmd.Module
+}
+
+// permissionTargets lists every target a rule can name: all, each module and each command
+func (b *Bot) permissionTargets() []string {
+	targets := []string{permissionTargetAll, permissionModulePrefix + coreModuleName}
+	for _, module := range b.Modules() {
+		targets = append(targets, permissionModulePrefix+module)
+	}
+	for _, cmd := range b.commands.Commands() {
+		targets = append(targets, cmd.Name)
+	}
+	return targets
+}
+
+// parsePermissionTarget resolves a rule target, turning command aliases into the command name
+func (b *Bot) parsePermissionTarget(value string) (string, error) {
+	target := strings.ToLower(value)
+	if target == permissionTargetAll {
+		return target, nil
+	}
+	if module, ok := strings.CutPrefix(target, permissionModulePrefix); ok {
+		if module != coreModuleName && !b.hasModule(module) {
+			return "", fmt.Errorf("unknown module %q, available modules: %s", module,
+				strings.Join(append([]string{coreModuleName}, b.Modules()...), ", "))
+		}
+		return target, nil
+	}
+	if cmd, ok := b.commands.Lookup(target); ok {
+		return cmd.Name, nil
+	}
+	return "", fmt.Errorf("unknown command %q; name a command, module:<name> or all", value)
+}
+
+// parsePermissionSubject resolves who a rule applies to: a role mention, name or ID,
+// "everyone", or a user mention or ID
+func (b *Bot) parsePermissionSubject(guildID, value string) (subjectType, subjectID string, err error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "everyone" || value == "@everyone":
+		return ruleSubjectRole, guildID, nil
+	case strings.HasPrefix(value, "<@&") && strings.HasSuffix(value, ">"):
+		value = value[3 : len(value)-1]
+	case strings.HasPrefix(value, "<@") && strings.HasSuffix(value, ">"):
+		id := strings.TrimPrefix(value[2:len(value)-1], "!")
+		if !isSnowflake(id) {
+			return "", "", fmt.Errorf("%q is not a user mention", value)
+		}
+		return ruleSubjectUser, id, nil
+	}
+
+	if role := b.FindRole(guildID, value); role != nil {
+		return ruleSubjectRole, role.ID, nil
+	}
+	if isSnowflake(value) {
+		return ruleSubjectUser, value, nil
+	}
+	return "", "", fmt.Errorf("role %q not found", value)
+}
+
+// describeSubject names who a rule applies to without pinging them as a role
+func (b *Bot) describeSubject(guildID, subjectType, subjectID string) string {
+	if subjectType == ruleSubjectUser {
+		return fmt.Sprintf("<@%s>", subjectID)
+	}
+	if subjectID == guildID {
+		return "everyone"
+	}
+	if role := b.FindRole(guildID, subjectID); role != nil {
+		return "@" + role.Name
+	}
+	return "role " + subjectID
+}
+
+// describeRule summarizes a rule, e.g. "allow checkstreams for @Mods"
+func (b *Bot) describeRule(rule models.PermissionRule) string {
+	verb := "deny"
+	if rule.Allow {
+		verb = "allow"
+	}
+	return fmt.Sprintf("%s %s for %s", verb, rule.Target, b.describeSubject(rule.GuildID, rule.SubjectType, rule.SubjectID))
+}
+
+// permissionCommands returns the commands that manage the guild's permission rules
+func (b *Bot) permissionCommands() []*Command {
+	return []*Command{
+		{
+			Name:        "perms",
+			Description: "List, allow, deny or remove who may run a command or module",
+			Category:    categorySettings,
+			Args: []Arg{
+				{Name: "action", Description: "list, allow, deny or remove", Type: ArgString, Default: "list",
+					Complete: func(_, _, partial string) []string {
+						return completeFrom([]string{"list", "allow", "deny", "remove"}, partial)
+					}},
+				{Name: "target", Description: "Command name, module:<name> or all", Type: ArgString,
+					Complete: func(_, _, partial string) []string {
+						return completeFrom(b.permissionTargets(), partial)
+					}},
+				{Name: "subject", Description: "Role, member or everyone", Type: ArgText},
+			},
+			Permission: PermissionAdmin,
+			Handler:    b.cmdPerms,
+		},
+	}
+}
+
+func (b *Bot) cmdPerms(ctx *CommandContext) error {
+	action := strings.ToLower(ctx.Arg("action"))
+	if action == "list" {
+		return b.listPermissionRules(ctx)
+	}
+	if action != "allow" && action != "deny" && action != "remove" {
+		return fmt.Errorf("unknown action %q, use list, allow, deny or remove", action)
+	}
+	if ctx.Arg("target") == "" || ctx.Arg("subject") == "" {
+		return fmt.Errorf("name a command and a role or member, e.g. %sperms %s checkstreams @Mods", ctx.prefix, action)
+	}
+
+	target, err := b.parsePermissionTarget(ctx.Arg("target"))
+	if err != nil {
+		return err
+	}
+	subjectType, subjectID, err := b.parsePermissionSubject(ctx.GuildID, ctx.Arg("subject"))
+	if err != nil {
+		return err
+	}
+	subject := b.describeSubject(ctx.GuildID, subjectType, subjectID)
+
+	var before string
+	rules, err := b.PermissionRules(ctx.Context(), ctx.GuildID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.Target == target && rule.SubjectID == subjectID {
+			before = b.describeRule(rule)
+		}
+	}
+
+	if action == "remove" {
+		err := b.RemovePermissionRule(ctx.Context(), ctx.GuildID, target, subjectID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("there is no rule for %s on `%s`", subject, target)
+		}
+		if err != nil {
+			return err
+		}
+		ctx.Audit("remove permission rule", before, "")
+		ctx.Replyf("✅ Removed the rule for %s on `%s`", subject, target)
+		return nil
+	}
+
+	rule := models.PermissionRule{
+		GuildID:     ctx.GuildID,
+		Target:      target,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Allow:       action == "allow",
+	}
+	if err := b.SetPermissionRule(ctx.Context(), rule); err != nil {
+		return err
+	}
+	ctx.Audit("set permission rule", before, b.describeRule(rule))
+	if rule.Allow {
+		ctx.Replyf("✅ %s may now use `%s`", subject, target)
+	} else {
+		ctx.Replyf("✅ %s may no longer use `%s`", subject, target)
+	}
+	return nil
+}
+
+// listPermissionRules replies with the guild's permission rules
+func (b *Bot) listPermissionRules(ctx *CommandContext) error {
+	rules, err := b.PermissionRules(ctx.Context(), ctx.GuildID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		ctx.Reply("🔐 No permission rules are set; every command uses its default permission")
+		return nil
+	}
+
+	var response strings.Builder
+	response.WriteString("🔐 **Permission rules:**\n")
+	for _, rule := range rules {
+		mark := "⛔"
+		if rule.Allow {
+			mark = "✅"
+		}
+		fmt.Fprintf(&response, "• %s %s\n", mark, b.describeRule(rule))
+	}
+	response.WriteString("Admins and the server owner can always use every command.")
+	ctx.Reply(response.String())
+	return nil
+}
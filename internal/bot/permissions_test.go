@@ -0,0 +1,96 @@
+package bot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPermissionRules(t *testing.T) {
+	b, fake := bottest.New(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!perms"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms"), "No permission rules")
+
+	// A role rule lets members run an admin command without admin rights
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms allow auditlog member"), "@member may now use `auditlog`")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "set permission rule")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!auditlog"), "Administrator")
+
+	// A rule for the member wins over one for their role
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms deny auditlog <@303>"), "<@303> may no longer use `auditlog`")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "A permission rule on this server doesn't allow you")
+
+	// A rule for a command wins over one for its module, which wins over one for every command
+	bottest.Send(b, fake, bottest.AdminID, "!perms deny all everyone")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!help"), "doesn't allow you to use !help")
+	bottest.Send(b, fake, bottest.AdminID, "!perms allow module:core <@&401>")
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.MemberID, "!help"), "Available commands")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!help"), "doesn't allow you")
+
+	// Administrators can't be locked out
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.AdminID, "!help"), "Available commands")
+
+	replies := bottest.Send(b, fake, bottest.AdminID, "!perms list")
+	for _, want := range []string{"✅ allow auditlog for @member", "⛔ deny auditlog for <@303>", "⛔ deny all for everyone", "✅ allow module:core for @member"} {
+		bottest.ExpectReply(t, replies, want)
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms remove all everyone"), "Removed the rule for everyone on `all`")
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.StrangerID, "!help"), "Available commands")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms remove all everyone"), "there is no rule")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms allow nosuchcommand member"), "unknown command")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms allow module:nosuchmodule member"), "unknown module")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms allow auditlog"), "name a command and a role or member")
+
+	entries, _ := b.Store().AuditLog.ListRecent(context.Background(), bottest.GuildID, 1)
+	if len(entries) != 1 || entries[0].Action != "remove permission rule" || entries[0].Before != "deny all for everyone" {
+		t.Fatalf("unexpected audit entries %+v", entries)
+	}
+}
+
+func TestPermissionRuleGrantsSlashCommand(t *testing.T) {
+	b, fake := bottest.New(t, &pingModule{name: "ping", permission: bot.PermissionAdmin})
+
+	// The slash command is visible to everyone, so the bot's own checks decide who runs it
+	if err := b.SyncApplicationCommands(bottest.BotID, bottest.GuildID); err != nil {
+		t.Fatalf("sync application commands: %v", err)
+	}
+	for _, appCmd := range fake.ApplicationCommands(bottest.GuildID) {
+		if appCmd.DefaultMemberPermissions != nil {
+			t.Fatalf("/%s is hidden from members that permission rules may allow", appCmd.Name)
+		}
+	}
+
+	slashPing := func() string {
+		fake.Reset()
+		b.Dispatch(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			ID:        "7000",
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   bottest.GuildID,
+			ChannelID: bottest.ChannelID,
+			Member:    &discordgo.Member{User: &discordgo.User{ID: bottest.MemberID, Username: "member"}, Roles: []string{bottest.MemberRoleID}},
+			Data:      discordgo.ApplicationCommandInteractionData{Name: "ping"},
+		}})
+		replies := fake.InteractionReplies()
+		if len(replies) == 0 {
+			t.Fatal("no reply to /ping")
+		}
+		return replies[0].Content
+	}
+
+	if reply := slashPing(); !strings.Contains(reply, "Administrator") {
+		t.Fatalf("a member ran /ping without a rule: %q", reply)
+	}
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!perms allow ping member"), "@member may now use `ping`")
+	if reply := slashPing(); !strings.Contains(reply, "pong") {
+		t.Fatalf("the rule didn't let the member run /ping: %q", reply)
+	}
+}
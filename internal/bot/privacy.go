@@ -0,0 +1,225 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/repository"
+	"GoopBot/storage/cache"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// UserData is a copy of everything the bot stores about one Discord user
+type UserData struct {
+	DiscordID  string    `json:"discord_id"`
+	ExportedAt time.Time `json:"exported_at"`
+	repository.UserRecords
+}
+
+// ExportUserData returns everything stored about a Discord user. A non-empty guildID limits
+// it to the records kept for that guild.
+func (b *Bot) ExportUserData(ctx context.Context, discordID, guildID string) (UserData, error) {
+	records, err := b.store.Users.Records(ctx, discordID, guildID)
+	if err != nil {
+		return UserData{}, fmt.Errorf("failed to gather user data: %w", err)
+	}
+	return UserData{DiscordID: discordID, ExportedAt: time.Now().UTC(), UserRecords: records}, nil
+}
+
+// ForgetUser permanently deletes everything stored about a Discord user, including the cached
+// status of their streams, their cached member objects, the cached permission rules naming
+// them and their rate limit windows, and
+// returns what it deleted. A non-empty guildID limits it to the records kept for that guild;
+// rate limits aren't kept per guild, so they are dropped either way.
+func (b *Bot) ForgetUser(ctx context.Context, discordID, guildID string) (repository.UserRecords, error) {
+	records, err := b.store.Users.Forget(ctx, discordID, guildID)
+	if err != nil {
+		return repository.UserRecords{}, fmt.Errorf("failed to delete user data: %w", err)
+	}
+
+	log := b.log.With(logging.KeyUserID, discordID)
+	if guildID != "" {
+		log = log.With(logging.KeyGuildID, guildID)
+	}
+	usernames := make(map[string]bool)
+	for _, creator := range records.Creators {
+		usernames[creator.TwitchUsername] = true
+	}
+	for _, stream := range records.Streams {
+		usernames[stream.TwitchUsername] = true
+	}
+	for username := range usernames {
+		// Cached entries expire on their own, so a cache outage doesn't fail the request
+		if err := cache.DeleteStream(ctx, b.cache, username); err != nil {
+			log.With(logging.KeyError, err).Warnf("Failed to delete the cached status of %s", username)
+		}
+	}
+	guilds := make(map[string]bool)
+	for _, rule := range records.PermissionRules {
+		guilds[rule.GuildID] = true
+	}
+	for ruleGuild := range guilds {
+		b.invalidatePermissionRules(ctx, ruleGuild)
+	}
+	memberGuild := guildID
+	if memberGuild == "" {
+		memberGuild = "*"
+	}
+	if err := b.cache.DeleteMatching(ctx, discordKey(cacheKindMember, memberGuild, discordID)); err != nil {
+		log.With(logging.KeyError, err).Warnf("Failed to delete the cached member")
+	}
+	if err := b.limiter.Forget(ctx, discordID); err != nil {
+		log.With(logging.KeyError, err).Warnf("Failed to delete the rate limits")
+	}
+	log.Infof("Deleted %d record(s) on request", records.Len())
+	return records, nil
+}
+
+// privacyCommands returns the commands that let members see and delete their data
+func (b *Bot) privacyCommands() []*Command {
+	return []*Command{
+		{
+			Name:        "mydata",
+			Description: "DM you a copy of everything the bot stores about you",
+			Category:    CategoryGeneral,
+			Handler:     b.cmdMyData,
+		},
+		{
+			Name:        "forgetme",
+			Description: "Delete everything the bot stores about you, in every server",
+			Category:    CategoryGeneral,
+			Args: []Arg{{Name: "confirm", Description: "\"confirm\" to delete your data for good", Type: ArgString,
+				Complete: func(_, _, _ string) []string { return []string{"confirm"} }}},
+			Handler: b.cmdForgetMe,
+		},
+		{
+			Name:        "forgetuser",
+			Description: "Delete everything the bot stores about a member in this server",
+			Category:    categorySettings,
+			Args:        []Arg{{Name: "user", Description: "Member whose data to delete", Type: ArgUser, Required: true}},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdForgetUser,
+		},
+	}
+}
+
+func (b *Bot) cmdMyData(ctx *CommandContext) error {
+	data, err := b.ExportUserData(ctx.Context(), ctx.Author.ID, "")
+	if err != nil {
+		return err
+	}
+	if data.Len() == 0 {
+		ctx.Reply("✅ GoopBot doesn't store anything about you")
+		return nil
+	}
+	contents, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode your data: %w", err)
+	}
+
+	// The export can hold a birth date, so it goes to the member privately
+	dm, err := b.session.UserChannelCreate(ctx.Author.ID)
+	if err == nil {
+		_, err = b.session.ChannelMessageSendComplex(dm.ID, &discordgo.MessageSend{
+			Content: fmt.Sprintf("📦 Here is everything GoopBot stores about you: %s. Use %sforgetme in a server to delete it.",
+				describeRecords(data.UserRecords), ctx.prefix),
+			Files: []*discordgo.File{{
+				Name:        "goopbot-data.json",
+				ContentType: "application/json",
+				Reader:      bytes.NewReader(contents),
+			}},
+		})
+	}
+	if err != nil {
+		return errNoDM
+	}
+	ctx.Reply("📬 I've sent you your data in a direct message")
+	return nil
+}
+
+func (b *Bot) cmdForgetMe(ctx *CommandContext) error {
+	action := ctx.Arg("confirm")
+	if action != "" && action != "confirm" {
+		return fmt.Errorf("unknown option %q, use confirm or leave it out", action)
+	}
+
+	records, err := b.store.Users.Records(ctx.Context(), ctx.Author.ID, "")
+	if err != nil {
+		return fmt.Errorf("failed to gather your data: %w", err)
+	}
+	if records.Len() == 0 {
+		ctx.Reply("✅ GoopBot doesn't store anything about you")
+		return nil
+	}
+	if action == "" {
+		ctx.Replyf("⚠️ GoopBot stores %s about you. `%sforgetme confirm` deletes all of it, in every server, "+
+			"and can't be undone; use %smydata first if you want a copy.%s", describeRecords(records), ctx.prefix, ctx.prefix,
+			auditRetentionNote(records, "your"))
+		return nil
+	}
+
+	deleted, err := b.ForgetUser(ctx.Context(), ctx.Author.ID, "")
+	if err != nil {
+		return err
+	}
+	ctx.Replyf("✅ Deleted everything GoopBot stored about you (%s)%s", describeRecords(deleted), auditRetentionNote(deleted, "your"))
+	return nil
+}
+
+func (b *Bot) cmdForgetUser(ctx *CommandContext) error {
+	userID := ctx.Arg("user")
+	deleted, err := b.ForgetUser(ctx.Context(), userID, ctx.GuildID)
+	if err != nil {
+		return err
+	}
+	if deleted.Len() == 0 {
+		ctx.Replyf("✅ GoopBot doesn't store anything about <@%s> in this server", userID)
+		return nil
+	}
+	// The forgotten ID itself stays out of the log
+	ctx.Audit("forget user", describeRecords(deleted), "")
+	ctx.Replyf("✅ Deleted everything GoopBot stored about <@%s> in this server (%s)%s", userID, describeRecords(deleted),
+		auditRetentionNote(deleted, "their"))
+	return nil
+}
+
+// auditRetentionNote explains that audit entries outlive a privacy request, or is "" if
+// records holds none
+func auditRetentionNote(records repository.UserRecords, whose string) string {
+	if len(records.AuditEntries) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nAudit log entries are kept with %s name taken out.", whose)
+}
+
+// describeRecords lists what records holds for people, e.g. "a linked Twitch account and a birthday"
+func describeRecords(records repository.UserRecords) string {
+	var parts []string
+	add := func(n int, one, many string) {
+		switch {
+		case n == 1:
+			parts = append(parts, one)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	add(len(records.Creators), "a linked Twitch account", "linked Twitch accounts")
+	add(len(records.Streams), "a Twitch stream status", "Twitch stream statuses")
+	add(len(records.Birthdays), "a birthday", "birthdays")
+	add(len(records.DashboardTokens), "a dashboard login", "dashboard logins")
+	add(len(records.PermissionRules), "a permission rule", "permission rules")
+	add(len(records.AuditEntries), "a mention in the audit log", "mentions in the audit log")
+
+	switch len(parts) {
+	case 0:
+		return "nothing"
+	case 1:
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
@@ -0,0 +1,136 @@
+package bot_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+	"GoopBot/internal/models"
+	"GoopBot/internal/ratelimit"
+	"GoopBot/storage/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMyDataAndForgetMe(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemory()
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Cache: c})
+	store := b.Store()
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!mydata"), "doesn't store anything about you")
+
+	now := time.Now()
+	store.Creators.Link(ctx, models.GoopCreator{DiscordID: bottest.MemberID, GuildID: bottest.GuildID, TwitchUsername: "goopy"})
+	store.Streams.Save(ctx, models.TwitchStream{TwitchUsername: "goopy", DiscordID: bottest.MemberID, LastChecked: &now})
+	store.Birthdays.Set(ctx, models.Birthday{DiscordID: bottest.MemberID, GuildID: bottest.GuildID, Month: 3, Day: 15})
+	cache.SetStreamStatus(ctx, c, "goopy", true)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!mydata"), "sent you your data")
+	dms := fake.SentToUser(bottest.MemberID)
+	if len(dms) != 1 || len(dms[0].Files) != 1 {
+		t.Fatalf("expected one DM with the export attached, got %+v", dms)
+	}
+	var export struct {
+		DiscordID string            `json:"discord_id"`
+		Birthdays []models.Birthday `json:"birthdays"`
+	}
+	if err := json.Unmarshal(dms[0].Files[0].Data, &export); err != nil || export.DiscordID != bottest.MemberID || len(export.Birthdays) != 1 {
+		t.Fatalf("unexpected export %s (err %v)", dms[0].Files[0].Data, err)
+	}
+
+	// Nothing is deleted without the confirmation
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!forgetme"), "a linked Twitch account, a Twitch stream status and a birthday")
+	if records, _ := store.Users.Records(ctx, bottest.MemberID, ""); records.Len() != 3 {
+		t.Fatalf("records deleted without confirmation: %+v", records)
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!forgetme confirm"), "✅ Deleted everything")
+	if records, _ := store.Users.Records(ctx, bottest.MemberID, ""); records.Len() != 0 {
+		t.Fatalf("records left after !forgetme: %+v", records)
+	}
+	if status, _ := cache.GetStreamStatus(ctx, c, "goopy"); status != nil {
+		t.Fatalf("stream status still cached: %+v", status)
+	}
+}
+
+func TestForgetMeClearsCachedKeys(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	cfg := config.Default()
+	cfg.RateLimits = config.RateLimits{"mydata." + config.RateLimitUser: {Count: 1, Per: time.Minute}}
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Config: cfg, Cache: cache.NewRedis(ctx, client, nil), Limiter: ratelimit.NewRedis(client)})
+	b.Store().Birthdays.Set(ctx, models.Birthday{DiscordID: bottest.MemberID, GuildID: bottest.GuildID, Month: 3, Day: 15})
+
+	// Leave a cached member object, a rate limit window and a rate limit notice behind. Checking
+	// a role rule looks the member up.
+	bottest.Send(b, fake, bottest.AdminID, "!perms allow auditlog member")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "set permission rule")
+	bottest.Send(b, fake, bottest.MemberID, "!mydata")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!mydata"), "Slow down!")
+	keysOf := func() []string {
+		var keys []string
+		for _, key := range server.Keys() {
+			if strings.Contains(key, bottest.MemberID) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+	if keys := keysOf(); len(keys) < 3 {
+		t.Fatalf("expected the member and rate limit keys to be cached, got %q", keys)
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!forgetme confirm"), "✅ Deleted everything")
+	if keys := keysOf(); len(keys) != 0 {
+		t.Fatalf("keys left after !forgetme: %q", keys)
+	}
+}
+
+func TestForgetUser(t *testing.T) {
+	ctx := context.Background()
+	b, fake := bottest.New(t)
+	store := b.Store()
+
+	// A birthday kept for another server is out of this server's reach
+	store.Birthdays.Set(ctx, models.Birthday{DiscordID: bottest.MemberID, GuildID: "999", Month: 3, Day: 15})
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!forgetuser <@303>"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!forgetuser <@303>"), "doesn't store anything about <@303> in this server")
+	if _, err := store.Birthdays.Get(ctx, bottest.MemberID); err != nil {
+		t.Fatalf("another server's birthday was deleted: %v", err)
+	}
+
+	store.Birthdays.Set(ctx, models.Birthday{DiscordID: bottest.MemberID, GuildID: bottest.GuildID, Month: 3, Day: 15})
+	store.AuditLog.Add(ctx, models.AuditEntry{GuildID: bottest.GuildID, ActorID: bottest.AdminID, Action: "set birthday", After: "<@303>"})
+	b.SetPermissionRule(ctx, models.PermissionRule{GuildID: bottest.GuildID, Target: "auditlog", SubjectType: "user", SubjectID: bottest.MemberID, Allow: true})
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "set birthday")
+	replies := bottest.Send(b, fake, bottest.AdminID, "!forgetuser <@303>")
+	bottest.ExpectReply(t, replies, "(a birthday, a permission rule and a mention in the audit log)")
+	bottest.ExpectReply(t, replies, "kept with their name taken out")
+	if _, err := store.Birthdays.Get(ctx, bottest.MemberID); err == nil {
+		t.Fatal("birthday still stored after !forgetuser")
+	}
+	// The rule stops applying straight away, not when the cached rules expire
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!auditlog"), "Administrator")
+
+	// Neither the old entries nor the one recording the request name the member
+	entries, _ := store.AuditLog.ListRecent(ctx, bottest.GuildID, 10)
+	if len(entries) != 2 || entries[0].Action != "forget user" {
+		t.Fatalf("unexpected audit entries %+v", entries)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Before+entry.After, bottest.MemberID) {
+			t.Fatalf("audit entry still names the member: %+v", entry)
+		}
+	}
+}
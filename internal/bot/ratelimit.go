@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"GoopBot/internal/config"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/ratelimit"
+	"fmt"
+	"time"
+)
+
+// allowRateLimited reports whether the author may use the command now under the configured
+// limits per member and per server. If not, it tells them when they can use it again.
+func (b *Bot) allowRateLimited(ctx *CommandContext) bool {
+	cmd := ctx.Command
+	for _, scope := range []string{config.RateLimitUser, config.RateLimitGuild} {
+		limit := b.cfg.RateLimits.For(cmd.Name, scope)
+		if !limit.Enabled() {
+			continue
+		}
+		subject := ctx.Author.ID
+		if scope == config.RateLimitGuild {
+			subject = ctx.GuildID
+		}
+		key := fmt.Sprintf("%s:%s:%s", cmd.Name, scope, subject)
+
+		result, err := b.limiter.Allow(ctx.Context(), key, ratelimit.Limit{Count: limit.Count, Per: limit.Per})
+		if err != nil {
+			// Better to let a command through than to block everyone on an outage
+			ctx.Log.With(logging.KeyError, err).Warnf("Failed to check the rate limit")
+			continue
+		}
+		if result.Allowed {
+			continue
+		}
+
+		ctx.Log.Debugf("Rate limited per %s for %s", scope, result.RetryAfter)
+		if b.shouldNotifyRateLimit(ctx, key, result.RetryAfter) {
+			again := time.Now().Add(result.RetryAfter).Add(time.Second - 1).Truncate(time.Second)
+			if scope == config.RateLimitUser {
+				ctx.Replyf("⏳ Slow down! You can use %s%s again <t:%d:R>", ctx.prefix, cmd.Name, again.Unix())
+			} else {
+				ctx.Replyf("⏳ %s%s is cooling down on this server, try again <t:%d:R>", ctx.prefix, cmd.Name, again.Unix())
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// shouldNotifyRateLimit reports whether to tell the author they are rate limited. Slash
+// commands always get an answer, but chat commands only get one per wait, so spamming a
+// command doesn't make the bot spam back.
+func (b *Bot) shouldNotifyRateLimit(ctx *CommandContext, key string, wait time.Duration) bool {
+	if ctx.prefix == "/" {
+		return true
+	}
+	result, err := b.limiter.Allow(ctx.Context(), key+":notice:"+ctx.Author.ID, ratelimit.Limit{Count: 1, Per: wait})
+	return err != nil || result.Allowed
+}
@@ -0,0 +1,39 @@
+package bot_test
+
+import (
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+)
+
+func TestRateLimits(t *testing.T) {
+	cfg := config.Default()
+	cfg.RateLimits = config.RateLimits{
+		config.RateLimitUser:              {Count: 2, Per: time.Minute},
+		"health." + config.RateLimitGuild: {Count: 1, Per: time.Minute},
+		"health." + config.RateLimitUser:  {},
+	}
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Config: cfg})
+
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.MemberID, "!help"), "Available commands")
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.MemberID, "!help"), "Available commands")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!help"), "Slow down! You can use !help again <t:")
+	// Spamming on doesn't make the bot spam back
+	if replies := bottest.Send(b, fake, bottest.MemberID, "!help"); len(replies) != 0 {
+		t.Fatalf("expected no reply to a repeated rate-limited command, got %q", replies)
+	}
+	// Limits are per member
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.StrangerID, "!help"), "Available commands")
+
+	// A per-server limit covers every member
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!health"), "Database")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.OwnerID, "!health"), "!health is cooling down on this server")
+
+	// Commands that are denied don't count against the limit
+	for i := 0; i < 3; i++ {
+		bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!settings"), "Administrator")
+	}
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"GoopBot/internal/metrics"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Session is the subset of the Discord API the bot uses. *discordgo.Session implements it;
+// tests use the in-memory fake from internal/discordtest instead of a live gateway.
+type Session interface {
+	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
+	GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error)
+	GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error)
+	GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error)
+	GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error
+	UserChannelPermissions(userID, channelID string, options ...discordgo.RequestOption) (int64, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+
+	ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ApplicationCommandBulkOverwrite(appID, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+
+	UpdateStatusComplex(usd discordgo.UpdateStatusData) error
+}
+
+var _ Session = (*discordgo.Session)(nil)
+
+// meteredSession records every Discord API call it passes on to a Session
+type meteredSession struct {
+	Session
+	metrics *metrics.Metrics
+}
+
+// instrumentSession wraps s so its calls show up in goopbot_discord_api_requests_total
+func instrumentSession(s Session, m *metrics.Metrics) Session {
+	return meteredSession{Session: s, metrics: m}
+}
+
+func (s meteredSession) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	channel, err := s.Session.Channel(channelID, options...)
+	s.metrics.DiscordCall("Channel", err)
+	return channel, err
+}
+
+func (s meteredSession) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	guild, err := s.Session.Guild(guildID, options...)
+	s.metrics.DiscordCall("Guild", err)
+	return guild, err
+}
+
+func (s meteredSession) GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	channels, err := s.Session.GuildChannels(guildID, options...)
+	s.metrics.DiscordCall("GuildChannels", err)
+	return channels, err
+}
+
+func (s meteredSession) GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error) {
+	roles, err := s.Session.GuildRoles(guildID, options...)
+	s.metrics.DiscordCall("GuildRoles", err)
+	return roles, err
+}
+
+func (s meteredSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	member, err := s.Session.GuildMember(guildID, userID, options...)
+	s.metrics.DiscordCall("GuildMember", err)
+	return member, err
+}
+
+func (s meteredSession) GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	err := s.Session.GuildMemberRoleAdd(guildID, userID, roleID, options...)
+	s.metrics.DiscordCall("GuildMemberRoleAdd", err)
+	return err
+}
+
+func (s meteredSession) UserChannelPermissions(userID, channelID string, options ...discordgo.RequestOption) (int64, error) {
+	perms, err := s.Session.UserChannelPermissions(userID, channelID, options...)
+	s.metrics.DiscordCall("UserChannelPermissions", err)
+	return perms, err
+}
+
+func (s meteredSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	channel, err := s.Session.UserChannelCreate(recipientID, options...)
+	s.metrics.DiscordCall("UserChannelCreate", err)
+	return channel, err
+}
+
+func (s meteredSession) ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	msg, err := s.Session.ChannelMessage(channelID, messageID, options...)
+	s.metrics.DiscordCall("ChannelMessage", err)
+	return msg, err
+}
+
+func (s meteredSession) ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	msg, err := s.Session.ChannelMessageSend(channelID, content, options...)
+	s.metrics.DiscordCall("ChannelMessageSend", err)
+	return msg, err
+}
+
+func (s meteredSession) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	msg, err := s.Session.ChannelMessageSendEmbed(channelID, embed, options...)
+	s.metrics.DiscordCall("ChannelMessageSendEmbed", err)
+	return msg, err
+}
+
+func (s meteredSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	msg, err := s.Session.ChannelMessageSendComplex(channelID, data, options...)
+	s.metrics.DiscordCall("ChannelMessageSendComplex", err)
+	return msg, err
+}
+
+func (s meteredSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	err := s.Session.InteractionRespond(interaction, resp, options...)
+	s.metrics.DiscordCall("InteractionRespond", err)
+	return err
+}
+
+func (s meteredSession) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	msg, err := s.Session.FollowupMessageCreate(interaction, wait, data, options...)
+	s.metrics.DiscordCall("FollowupMessageCreate", err)
+	return msg, err
+}
+
+func (s meteredSession) ApplicationCommandBulkOverwrite(appID, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	cmds, err := s.Session.ApplicationCommandBulkOverwrite(appID, guildID, commands, options...)
+	s.metrics.DiscordCall("ApplicationCommandBulkOverwrite", err)
+	return cmds, err
+}
+
+func (s meteredSession) UpdateStatusComplex(usd discordgo.UpdateStatusData) error {
+	err := s.Session.UpdateStatusComplex(usd)
+	s.metrics.DiscordCall("UpdateStatusComplex", err)
+	return err
+}
@@ -0,0 +1,491 @@
+package bot
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"GoopBot/storage/cache"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// guildSettingsCacheTTL is how long guild settings stay cached
+const guildSettingsCacheTTL = time.Hour
+
+// maxPrefixLength keeps prefixes short enough to type
+const maxPrefixLength = 5
+
+// GuildSettings holds per-guild overrides. Empty fields fall back to the bot configuration.
+type GuildSettings = models.GuildSettings
+
+// GuildConfig is the effective configuration of a guild: its overrides merged with the bot defaults
+type GuildConfig struct {
+	GuildID               string
+	CreatorRole           string
+	MemberRole            string
+	CommandPrefix         string
+	NotificationChannelID string
+	ModLogChannelID       string
+	modules               map[string]bool // nil enables every module
+}
+
+// ModuleEnabled reports whether a module is enabled. Core commands (empty module) are always enabled.
+func (c GuildConfig) ModuleEnabled(module string) bool {
+	if module == "" || c.modules == nil {
+		return true
+	}
+	return c.modules[module]
+}
+
+// guildSettingsKey is the cache key guild settings are cached under
+func guildSettingsKey(guildID string) string {
+	return fmt.Sprintf("guild_settings:%s", guildID)
+}
+
+// GetGuildSettings returns a guild's stored overrides, from the cache when possible.
+// A guild without overrides gets an empty GuildSettings.
+func (b *Bot) GetGuildSettings(ctx context.Context, guildID string) (GuildSettings, error) {
+	log := b.log.With(logging.KeyGuildID, guildID)
+	settings := GuildSettings{GuildID: guildID}
+
+	found, err := cache.GetJSON(ctx, b.cache, guildSettingsKey(guildID), &settings)
+	if err != nil {
+		log.With(logging.KeyError, err).Debugf("Failed to read cached guild settings")
+	} else if found {
+		return settings, nil
+	}
+
+	settings, err = LoadGuildSettings(ctx, b.dbConn, guildID)
+	if err != nil {
+		return GuildSettings{}, err
+	}
+
+	// Guilds without overrides are cached too, so they don't hit the database on every message
+	if err := cache.SetJSON(ctx, b.cache, guildSettingsKey(guildID), settings, guildSettingsCacheTTL); err != nil {
+		log.With(logging.KeyError, err).Debugf("Failed to cache guild settings")
+	}
+	return settings, nil
+}
+
+// LoadGuildSettings reads a guild's stored overrides from the database, without the cache,
+// for tools that run without a bot. A guild without overrides gets an empty GuildSettings.
+func LoadGuildSettings(ctx context.Context, conn *gorm.DB, guildID string) (GuildSettings, error) {
+	settings := GuildSettings{GuildID: guildID}
+	err := conn.WithContext(ctx).Where("guild_id = ?", guildID).First(&settings).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return GuildSettings{}, fmt.Errorf("failed to load guild settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateGuildSettings applies update to a guild's stored overrides and saves them
+func (b *Bot) UpdateGuildSettings(ctx context.Context, guildID string, update func(*GuildSettings) error) error {
+	var settings GuildSettings
+	if err := b.dbConn.Where(map[string]interface{}{"guild_id": guildID}).FirstOrInit(&settings).Error; err != nil {
+		return fmt.Errorf("failed to load guild settings: %w", err)
+	}
+
+	if err := update(&settings); err != nil {
+		return err
+	}
+
+	if err := b.dbConn.Save(&settings).Error; err != nil {
+		return fmt.Errorf("failed to save guild settings: %w", err)
+	}
+
+	if err := b.cache.Delete(ctx, guildSettingsKey(guildID)); err != nil {
+		b.log.With(logging.KeyGuildID, guildID, logging.KeyError, err).Warnf("Failed to invalidate cached guild settings")
+	}
+	return nil
+}
+
+// GuildConfig returns the effective configuration of a guild. If the overrides can't be
+// loaded the bot defaults are used, so commands keep working when the database is unhappy.
+func (b *Bot) GuildConfig(guildID string) GuildConfig {
+	cfg := GuildConfig{
+		GuildID:       guildID,
+		CreatorRole:   b.cfg.CreatorRole,
+		MemberRole:    b.cfg.MemberRole,
+		CommandPrefix: b.cfg.CommandPrefix,
+	}
+
+	settings, err := b.GetGuildSettings(b.workCtx, guildID)
+	if err != nil {
+		b.log.With(logging.KeyGuildID, guildID, logging.KeyError, err).Errorf("Using default guild settings")
+		return cfg
+	}
+
+	if settings.CreatorRole != "" {
+		cfg.CreatorRole = settings.CreatorRole
+	}
+	if settings.MemberRole != "" {
+		cfg.MemberRole = settings.MemberRole
+	}
+	if settings.CommandPrefix != "" {
+		cfg.CommandPrefix = settings.CommandPrefix
+	}
+	cfg.NotificationChannelID = settings.NotificationChannelID
+	cfg.ModLogChannelID = settings.ModLogChannelID
+	if settings.EnabledModules != nil {
+		cfg.modules = make(map[string]bool)
+		for _, module := range splitModules(*settings.EnabledModules) {
+			cfg.modules[module] = true
+		}
+	}
+	return cfg
+}
+
+// splitModules parses a comma-separated module list
+func splitModules(list string) []string {
+	var modules []string
+	for _, module := range strings.Split(list, ",") {
+		if module = strings.TrimSpace(module); module != "" {
+			modules = append(modules, module)
+		}
+	}
+	return modules
+}
+
+// setModuleEnabled turns a module on or off in the stored overrides. all lists every
+// running module, which are all enabled until a guild first changes them.
+func setModuleEnabled(settings *GuildSettings, all []string, module string, enabled bool) {
+	current := all
+	if settings.EnabledModules != nil {
+		current = splitModules(*settings.EnabledModules)
+	}
+
+	set := make(map[string]bool)
+	for _, m := range current {
+		set[m] = true
+	}
+	set[module] = enabled
+
+	var modules []string
+	for m, on := range set {
+		if on {
+			modules = append(modules, m)
+		}
+	}
+	sort.Strings(modules)
+	list := strings.Join(modules, ",")
+	settings.EnabledModules = &list
+}
+
+// validatePrefix checks that a command prefix is short and has no whitespace
+func validatePrefix(prefix string) error {
+	if prefix == "" || len([]rune(prefix)) > maxPrefixLength {
+		return fmt.Errorf("the prefix must be 1-%d characters", maxPrefixLength)
+	}
+	if strings.IndexFunc(prefix, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("the prefix must not contain spaces")
+	}
+	return nil
+}
+
+// settingNames are the settings !resetsetting accepts
+var settingNames = []string{"prefix", "creator_role", "member_role", "default_channel", "modlog_channel", "modules"}
+
+// settingsCommands returns the admin commands for viewing and changing guild settings
+func (b *Bot) settingsCommands() []*Command {
+	moduleArg := Arg{
+		Name:        "module",
+		Description: "Module name",
+		Type:        ArgString,
+		Required:    true,
+		Complete: func(_, _, partial string) []string {
+			return completeFrom(b.Modules(), partial)
+		},
+	}
+
+	return []*Command{
+		{
+			Name:        "settings",
+			Description: "Show this server's bot settings",
+			Category:    categorySettings,
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdSettings,
+		},
+		{
+			Name:        "setprefix",
+			Description: "Change the command prefix for this server",
+			Category:    categorySettings,
+			Args:        []Arg{{Name: "prefix", Description: "New prefix, e.g. ?", Type: ArgString, Required: true}},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdSetPrefix,
+		},
+		{
+			Name:        "setcreatorrole",
+			Description: "Set the role allowed to link Twitch accounts",
+			Category:    categorySettings,
+			Args:        []Arg{{Name: "role", Description: "Creator role", Type: ArgRole, Required: true}},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdSetCreatorRole,
+		},
+		{
+			Name:        "setmemberrole",
+			Description: "Set the role allowed to set birthdays and granted by role messages",
+			Category:    categorySettings,
+			Args:        []Arg{{Name: "role", Description: "Member role", Type: ArgRole, Required: true}},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdSetMemberRole,
+		},
+		{
+			Name:        "setdefaultchannel",
+			Description: "Set the channel used for notifications that have no channel of their own",
+			Category:    categorySettings,
+			Args:        []Arg{{Name: "channel", Description: "Default notification channel", Type: ArgChannel, Required: true}},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdSetDefaultChannel,
+		},
+		{
+			Name:        "setmodlog",
+			Description: "Set the channel admin changes are posted to",
+			Category:    categorySettings,
+			Args:        []Arg{{Name: "channel", Description: "Mod-log channel", Type: ArgChannel, Required: true}},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdSetModLog,
+		},
+		{
+			Name:        "enablemodule",
+			Description: "Enable a module on this server",
+			Category:    categorySettings,
+			Args:        []Arg{moduleArg},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdEnableModule,
+		},
+		{
+			Name:        "disablemodule",
+			Description: "Disable a module on this server",
+			Category:    categorySettings,
+			Args:        []Arg{moduleArg},
+			Permission:  PermissionAdmin,
+			Handler:     b.cmdDisableModule,
+		},
+		{
+			Name:        "resetsetting",
+			Description: "Restore a setting to the bot default",
+			Category:    categorySettings,
+			Args: []Arg{{
+				Name:        "setting",
+				Description: strings.Join(settingNames, ", "),
+				Type:        ArgString,
+				Required:    true,
+				Complete: func(_, _, partial string) []string {
+					return completeFrom(settingNames, partial)
+				},
+			}},
+			Permission: PermissionAdmin,
+			Handler:    b.cmdResetSetting,
+		},
+		{
+			Name:        "auditlog",
+			Description: "Show the latest admin changes on this server",
+			Category:    categorySettings,
+			Args: []Arg{{Name: "count", Description: fmt.Sprintf("How many entries to show (default %d, at most %d)",
+				defaultAuditLogEntries, maxAuditLogEntries), Type: ArgString}},
+			Permission: PermissionAdmin,
+			Handler:    b.cmdAuditLog,
+		},
+	}
+}
+
+// completeFrom suggests the options starting with the partial input
+func completeFrom(options []string, partial string) []string {
+	var matches []string
+	for _, option := range options {
+		if strings.HasPrefix(option, strings.ToLower(partial)) {
+			matches = append(matches, option)
+		}
+	}
+	return matches
+}
+
+func (b *Bot) cmdSettings(ctx *CommandContext) error {
+	settings, err := b.GetGuildSettings(b.workCtx, ctx.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	effective := b.GuildConfig(ctx.GuildID)
+
+	source := func(override string) string {
+		if override == "" {
+			return " (default)"
+		}
+		return ""
+	}
+
+	channel, modLog := "not set", "not set"
+	if effective.NotificationChannelID != "" {
+		channel = channelMention(effective.NotificationChannelID)
+	}
+	if effective.ModLogChannelID != "" {
+		modLog = channelMention(effective.ModLogChannelID)
+	}
+
+	var modules []string
+	for _, module := range b.Modules() {
+		status := "❌"
+		if effective.ModuleEnabled(module) {
+			status = "✅"
+		}
+		modules = append(modules, fmt.Sprintf("%s %s", status, module))
+	}
+
+	message := "⚙️ **Server Settings:**\n"
+	message += fmt.Sprintf("• Prefix: `%s`%s\n", effective.CommandPrefix, source(settings.CommandPrefix))
+	message += fmt.Sprintf("• Creator role: `%s`%s\n", effective.CreatorRole, source(settings.CreatorRole))
+	message += fmt.Sprintf("• Member role: `%s`%s\n", effective.MemberRole, source(settings.MemberRole))
+	message += fmt.Sprintf("• Default notification channel: %s\n", channel)
+	message += fmt.Sprintf("• Mod-log channel: %s\n", modLog)
+	if len(modules) == 0 {
+		modules = append(modules, "none")
+	}
+	message += fmt.Sprintf("• Modules: %s\n", strings.Join(modules, ", "))
+	ctx.Reply(message)
+	return nil
+}
+
+func (b *Bot) cmdSetPrefix(ctx *CommandContext) error {
+	prefix := ctx.Arg("prefix")
+	if err := validatePrefix(prefix); err != nil {
+		return err
+	}
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		s.CommandPrefix = prefix
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to set prefix: %w", err)
+	}
+	ctx.Audit("set prefix", ctx.Settings.CommandPrefix, prefix)
+	ctx.Replyf("✅ Command prefix is now `%s` (e.g. `%shelp`)", prefix, prefix)
+	return nil
+}
+
+func (b *Bot) cmdSetCreatorRole(ctx *CommandContext) error {
+	role := b.FindRole(ctx.GuildID, ctx.Arg("role"))
+	if role == nil {
+		return fmt.Errorf("role '%s' not found in this server", ctx.Arg("role"))
+	}
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		s.CreatorRole = role.Name
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to set creator role: %w", err)
+	}
+	ctx.Audit("set creator role", ctx.Settings.CreatorRole, role.Name)
+	ctx.Replyf("✅ Creator role is now '%s'", role.Name)
+	return nil
+}
+
+func (b *Bot) cmdSetMemberRole(ctx *CommandContext) error {
+	role := b.FindRole(ctx.GuildID, ctx.Arg("role"))
+	if role == nil {
+		return fmt.Errorf("role '%s' not found in this server", ctx.Arg("role"))
+	}
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		s.MemberRole = role.Name
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to set member role: %w", err)
+	}
+	ctx.Audit("set member role", ctx.Settings.MemberRole, role.Name)
+	ctx.Replyf("✅ Member role is now '%s'", role.Name)
+	return nil
+}
+
+func (b *Bot) cmdSetDefaultChannel(ctx *CommandContext) error {
+	channelID := ctx.Arg("channel")
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		s.NotificationChannelID = channelID
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to set default channel: %w", err)
+	}
+	ctx.Audit("set default channel", channelMention(ctx.Settings.NotificationChannelID), channelMention(channelID))
+	ctx.Replyf("✅ <#%s> is now the default notification channel", channelID)
+	return nil
+}
+
+func (b *Bot) cmdEnableModule(ctx *CommandContext) error {
+	return b.toggleModule(ctx, true)
+}
+
+func (b *Bot) cmdDisableModule(ctx *CommandContext) error {
+	return b.toggleModule(ctx, false)
+}
+
+// toggleModule enables or disables the module named in the command's arguments
+func (b *Bot) toggleModule(ctx *CommandContext, enabled bool) error {
+	module := strings.ToLower(ctx.Arg("module"))
+	if !b.hasModule(module) {
+		return fmt.Errorf("unknown module '%s', choose one of: %s", module, strings.Join(b.Modules(), ", "))
+	}
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		setModuleEnabled(s, b.Modules(), module, enabled)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update modules: %w", err)
+	}
+
+	action := "disable module"
+	if enabled {
+		action = "enable module"
+	}
+	ctx.Audit(action, b.enabledModules(ctx.Settings), b.enabledModules(b.GuildConfig(ctx.GuildID)))
+
+	if enabled {
+		ctx.Replyf("✅ Enabled the %s module", module)
+	} else {
+		ctx.Replyf("✅ Disabled the %s module", module)
+	}
+	return nil
+}
+
+func (b *Bot) cmdResetSetting(ctx *CommandContext) error {
+	setting := strings.ToLower(ctx.Arg("setting"))
+	var before string
+	if err := b.UpdateGuildSettings(b.workCtx, ctx.GuildID, func(s *GuildSettings) error {
+		switch setting {
+		case "prefix":
+			before, s.CommandPrefix = s.CommandPrefix, ""
+		case "creator_role":
+			before, s.CreatorRole = s.CreatorRole, ""
+		case "member_role":
+			before, s.MemberRole = s.MemberRole, ""
+		case "default_channel":
+			before, s.NotificationChannelID = channelMention(s.NotificationChannelID), ""
+		case "modlog_channel":
+			before, s.ModLogChannelID = channelMention(s.ModLogChannelID), ""
+		case "modules":
+			if s.EnabledModules != nil {
+				before = *s.EnabledModules
+			}
+			s.EnabledModules = nil
+		default:
+			return fmt.Errorf("unknown setting '%s', choose one of: %s", setting, strings.Join(settingNames, ", "))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	ctx.Audit("reset "+setting, before, "default")
+	ctx.Replyf("✅ Reset %s to the default", setting)
+	return nil
+}
+
+// enabledModules lists the modules a guild configuration enables, for the audit log
+func (b *Bot) enabledModules(cfg GuildConfig) string {
+	var modules []string
+	for _, module := range b.Modules() {
+		if cfg.ModuleEnabled(module) {
+			modules = append(modules, module)
+		}
+	}
+	return strings.Join(modules, ", ")
+}
@@ -0,0 +1,64 @@
+package bot_test
+
+import (
+	"strings"
+	"testing"
+
+	"GoopBot/internal/bottest"
+)
+
+func TestSettingsCommandsRequireAdmin(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!setprefix ?"), "Administrator permissions")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!settings"), "Prefix: `!` (default)")
+}
+
+func TestPerGuildPrefix(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setprefix ?"), "Command prefix is now `?`")
+
+	if replies := bottest.Send(b, fake, bottest.StrangerID, "!help"); len(replies) != 0 {
+		t.Fatalf("old prefix still answered: %q", replies)
+	}
+	replies := bottest.Send(b, fake, bottest.StrangerID, "?help")
+	bottest.ExpectFirstReply(t, replies, "?linktwitch <username>")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "?setprefix two words"), "Command prefix is now `two`")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "twosetprefix toolong"), "1-5 characters")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "tworesetsetting prefix"), "Reset prefix")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!gooplive"), "No Goop Creators")
+}
+
+func TestPerGuildRoles(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	// Swap the roles around: only holders of the member role may now link Twitch accounts
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setcreatorrole <@&"+bottest.MemberRoleID+">"), "Creator role is now 'member'")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.CreatorID, "!linktwitch goopy"), "You need the 'member' role")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!linktwitch goopy"), "Successfully linked")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setcreatorrole nosuchrole"), "not found")
+	bottest.ExpectFirstReply(t, bottest.Send(b, fake, bottest.StrangerID, "!help"), "(member role required)")
+}
+
+func TestModuleToggle(t *testing.T) {
+	b, fake := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!disablemodule birthdays"), "Disabled the birthdays module")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!setbirthday 03/15"), "birthdays module is disabled")
+
+	replies := bottest.Send(b, fake, bottest.StrangerID, "!help")
+	if len(replies) == 0 || strings.Contains(strings.Join(replies, "\n"), "!setbirthday") {
+		t.Fatalf("help should hide disabled commands, got %q", replies)
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!settings"), "❌ birthdays")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!enablemodule birthdays"), "Enabled the birthdays module")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!setbirthday 03/15"), "Successfully set your birthday")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!disablemodule weather"), "unknown module")
+}
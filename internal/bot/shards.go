@@ -0,0 +1,245 @@
+package bot
+
+import (
+	"GoopBot/internal/config"
+	"GoopBot/internal/logging"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// identifyInterval is how long to wait between opening shards. Discord allows one
+// identify per five seconds unless the application has a higher concurrency limit.
+const identifyInterval = 5 * time.Second
+
+// shard is one gateway connection and the state the health output reports for it
+type shard struct {
+	id        int
+	session   *discordgo.Session
+	connected atomic.Bool
+	since     atomic.Int64 // Unix time of the last connect or disconnect
+}
+
+// ShardStatus describes one gateway shard run by this process
+type ShardStatus struct {
+	ID        int
+	Count     int
+	Connected bool
+	Since     time.Time     // When the shard last connected or disconnected
+	Guilds    int           // Guilds the shard has received
+	Latency   time.Duration // Last heartbeat round trip
+}
+
+// ShardFor returns the shard that receives a guild's events, as Discord assigns them
+func ShardFor(guildID string, count int) int {
+	if count <= 1 {
+		return 0
+	}
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return int((id >> 22) % uint64(count))
+}
+
+// HandlesGuild reports whether this process runs the shard a guild belongs to. Jobs skip
+// guilds of other shards, which the processes running those shards take care of.
+func (b *Bot) HandlesGuild(guildID string) bool {
+	if len(b.shards) == 0 || b.shardCount <= 1 {
+		return true
+	}
+	want := ShardFor(guildID, b.shardCount)
+	for _, s := range b.shards {
+		if s.id == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ShardStatuses reports on every shard this process runs. It is empty when the bot
+// runs without a gateway connection, as in tests.
+func (b *Bot) ShardStatuses() []ShardStatus {
+	statuses := make([]ShardStatus, len(b.shards))
+	for i, s := range b.shards {
+		s.session.State.RLock()
+		guilds := len(s.session.State.Guilds)
+		s.session.State.RUnlock()
+
+		statuses[i] = ShardStatus{
+			ID:        s.id,
+			Count:     b.shardCount,
+			Connected: s.connected.Load(),
+			Since:     time.Unix(s.since.Load(), 0),
+			Guilds:    guilds,
+			Latency:   s.session.HeartbeatLatency(),
+		}
+	}
+	return statuses
+}
+
+// leaseName is the leader election lease for a job. Processes running different shards
+// each need their own leader, so the lease covers the shards this process runs.
+func (b *Bot) leaseName(job string) string {
+	if b.shardCount <= 1 || len(b.shards) == b.shardCount {
+		return job
+	}
+	name := job + "/shards"
+	for _, s := range b.shards {
+		name += fmt.Sprintf("-%d", s.id)
+	}
+	return name
+}
+
+// Intents are the gateway intents the bot needs for message handling and reactions. Message
+// content is privileged, so it must also be enabled for the application in the Developer Portal.
+const Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages |
+	discordgo.IntentsMessageContent | discordgo.IntentsGuildMessageReactions
+
+// GatewayIntents returns Intents plus the optional intents the configuration enables
+func GatewayIntents(cfg config.Config) discordgo.Intent {
+	intents := Intents
+	if cfg.GuildMembersIntent {
+		// Member updates keep the cached member roles current
+		intents |= discordgo.IntentsGuildMembers
+	}
+	return intents
+}
+
+// newShards creates a gateway session for each shard the configuration asks this process
+// to run. With no shard count set, Discord's recommended count is used.
+func newShards(cfg config.Config, logger Logger) ([]*shard, int, error) {
+	newSession := func() (*discordgo.Session, error) {
+		dg, err := discordgo.New("Bot " + cfg.DiscordToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Discord session: %w", err)
+		}
+		dg.Identify.Intents = GatewayIntents(cfg)
+		return dg, nil
+	}
+
+	first, err := newSession()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count := cfg.ShardCount
+	if count == 0 {
+		gateway, err := first.GatewayBot()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get the recommended shard count: %w", err)
+		}
+		count = gateway.Shards
+		if count < 1 {
+			count = 1
+		}
+	}
+
+	ids := []int{cfg.ShardID}
+	if cfg.ShardID < 0 {
+		ids = make([]int, count)
+		for i := range ids {
+			ids[i] = i
+		}
+	}
+
+	shards := make([]*shard, len(ids))
+	for i, id := range ids {
+		dg := first
+		if i > 0 {
+			if dg, err = newSession(); err != nil {
+				return nil, 0, err
+			}
+		}
+		dg.ShardID, dg.ShardCount = id, count
+		shards[i] = &shard{id: id, session: dg}
+	}
+	logger.Infof("Running %d of %d shards: %v", len(ids), count, ids)
+	return shards, count, nil
+}
+
+// watch keeps the shard's connection status up to date
+func (s *shard) watch(log Logger) {
+	set := func(connected bool) {
+		s.connected.Store(connected)
+		s.since.Store(time.Now().Unix())
+	}
+	s.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Ready) {
+		set(true)
+		log.Infof("Shard %d is ready", s.id)
+	})
+	s.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Resumed) {
+		set(true)
+		log.Infof("Shard %d resumed", s.id)
+	})
+	s.session.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) {
+		set(false)
+		log.Warnf("Shard %d disconnected, reconnecting", s.id)
+	})
+}
+
+// openShards connects every shard, pausing between them as Discord's identify limit requires
+func (b *Bot) openShards() error {
+	for i, s := range b.shards {
+		if i > 0 {
+			time.Sleep(identifyInterval)
+		}
+		s.watch(b.log.With(logging.KeyShard, s.id))
+		for _, handler := range b.handlers {
+			s.session.AddHandler(handler)
+		}
+		// Open the session once handlers are in place so the ready event is not missed
+		if err := s.session.Open(); err != nil {
+			return fmt.Errorf("failed to open shard %d: %w", s.id, err)
+		}
+	}
+	return nil
+}
+
+// closeShards closes every gateway connection
+func (b *Bot) closeShards() []error {
+	var errs []error
+	for _, s := range b.shards {
+		if err := s.session.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close shard %d: %w", s.id, err))
+		}
+	}
+	return errs
+}
+
+var (
+	shardConnectedDesc = prometheus.NewDesc("goopbot_shard_connected",
+		"Whether a gateway shard run by this process is connected (1) or not (0).", []string{"shard"}, nil)
+	shardLatencyDesc = prometheus.NewDesc("goopbot_shard_heartbeat_latency_seconds",
+		"Last heartbeat round trip of a gateway shard.", []string{"shard"}, nil)
+	shardGuildsDesc = prometheus.NewDesc("goopbot_shard_guilds",
+		"Guilds a gateway shard has received.", []string{"shard"}, nil)
+)
+
+// shardCollector exports ShardStatuses as metrics at scrape time
+type shardCollector struct {
+	bot *Bot
+}
+
+func (c shardCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- shardConnectedDesc
+	ch <- shardLatencyDesc
+	ch <- shardGuildsDesc
+}
+
+func (c shardCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.bot.ShardStatuses() {
+		id := strconv.Itoa(status.ID)
+		connected := 0.0
+		if status.Connected {
+			connected = 1
+		}
+		ch <- prometheus.MustNewConstMetric(shardConnectedDesc, prometheus.GaugeValue, connected, id)
+		ch <- prometheus.MustNewConstMetric(shardLatencyDesc, prometheus.GaugeValue, status.Latency.Seconds(), id)
+		ch <- prometheus.MustNewConstMetric(shardGuildsDesc, prometheus.GaugeValue, float64(status.Guilds), id)
+	}
+}
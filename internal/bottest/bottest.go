@@ -0,0 +1,146 @@
+// Package bottest builds bots on an in-memory database and a fake Discord session,
+// so the core and every feature module can be tested without Discord, Redis or Twitch.
+package bottest
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/config"
+	"GoopBot/internal/dbtest"
+	"GoopBot/internal/discordtest"
+	"GoopBot/internal/repository"
+	"GoopBot/storage/db"
+
+	"github.com/bwmarrin/discordgo"
+	"gorm.io/gorm"
+)
+
+// Fixture IDs seeded by New
+const (
+	BotID         = "1"
+	GuildID       = "100"
+	ChannelID     = "200"
+	LiveChannelID = "201"
+	OwnerID       = "300"
+	AdminID       = "301"
+	CreatorID     = "302"
+	MemberID      = "303"
+	StrangerID    = "304"
+	CreatorRoleID = "400"
+	MemberRoleID  = "401"
+)
+
+// NewDB returns a migrated database that is removed when the test ends: in-memory SQLite,
+// or Postgres when dbtest.PostgresEnv is set
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	conn := dbtest.Open(t)
+	if err := db.Migrate(context.Background(), conn, nil); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return conn
+}
+
+// NewSession returns a fake session seeded with one guild, two channels, the creator and
+// member roles and a user for each permission level
+func NewSession() *discordtest.Session {
+	fake := discordtest.NewSession()
+	fake.AddGuild(GuildID, OwnerID)
+	fake.AddChannel(GuildID, ChannelID)
+	fake.AddChannel(GuildID, LiveChannelID)
+	fake.AddRole(GuildID, CreatorRoleID, "Goop Creator")
+	fake.AddRole(GuildID, MemberRoleID, "member")
+	fake.AddMember(GuildID, OwnerID, "owner")
+	fake.AddMember(GuildID, AdminID, "admin")
+	fake.AddMember(GuildID, CreatorID, "creator", CreatorRoleID)
+	fake.AddMember(GuildID, MemberID, "member", MemberRoleID)
+	fake.AddMember(GuildID, StrangerID, "stranger")
+	fake.SetPermissions(AdminID, ChannelID, discordgo.PermissionAdministrator)
+	return fake
+}
+
+// New builds a bot running the given modules with the default configuration.
+// Module records are stored in an in-memory SQLite database.
+func New(t testing.TB, modules ...bot.Module) (*bot.Bot, *discordtest.Session) {
+	t.Helper()
+	return NewWithStore(t, nil, modules...)
+}
+
+// NewWithStore builds a bot whose modules keep their records in store, such as
+// repository.NewMemory(). A nil store uses the in-memory SQLite database.
+func NewWithStore(t testing.TB, store *repository.Store, modules ...bot.Module) (*bot.Bot, *discordtest.Session) {
+	t.Helper()
+	return NewWithDeps(t, bot.Deps{Store: store}, modules...)
+}
+
+// NewWithDeps is like New but takes services such as the store or cache from deps.
+// The fake session, a fresh database and the default configuration fill in the rest.
+func NewWithDeps(t testing.TB, deps bot.Deps, modules ...bot.Module) (*bot.Bot, *discordtest.Session) {
+	t.Helper()
+
+	fake := NewSession()
+	deps.Session = fake
+	if deps.DB == nil {
+		deps.DB = NewDB(t)
+	}
+	if reflect.ValueOf(deps.Config).IsZero() {
+		// Without rate limits, so tests can send commands back to back
+		deps.Config = config.Default()
+		deps.Config.RateLimits = nil
+	}
+	b, err := bot.New(deps, modules...)
+	if err != nil {
+		t.Fatalf("create bot: %v", err)
+	}
+	t.Cleanup(func() { b.Close(context.Background()) })
+
+	b.Dispatch(&discordgo.Ready{User: &discordgo.User{ID: BotID, Username: "GoopBot"}})
+	fake.Reset()
+	return b, fake
+}
+
+// Send delivers a chat message from userID to the test channel and returns the bot's replies
+func Send(b *bot.Bot, fake *discordtest.Session, userID, content string) []string {
+	fake.Reset()
+	b.Dispatch(&discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        "9999",
+		GuildID:   GuildID,
+		ChannelID: ChannelID,
+		Content:   content,
+		Author:    &discordgo.User{ID: userID, Username: "user" + userID},
+	}})
+
+	var replies []string
+	for _, m := range fake.SentTo(ChannelID) {
+		replies = append(replies, m.Content)
+	}
+	return replies
+}
+
+// ExpectReply asserts that exactly one reply was sent and that it contains want
+func ExpectReply(t testing.TB, replies []string, want string) {
+	t.Helper()
+	if len(replies) != 1 {
+		t.Fatalf("expected 1 reply, got %d: %q", len(replies), replies)
+	}
+	if !strings.Contains(replies[0], want) {
+		t.Fatalf("expected reply containing %q, got %q", want, replies[0])
+	}
+}
+
+// ExpectFirstReply asserts that at least one reply was sent and that the first contains
+// want, for commands such as !help that answer in several messages
+func ExpectFirstReply(t testing.TB, replies []string, want string) {
+	t.Helper()
+	if len(replies) == 0 {
+		t.Fatal("expected a reply, got none")
+	}
+	if !strings.Contains(replies[0], want) {
+		t.Fatalf("expected first reply containing %q, got %q", want, replies[0])
+	}
+}
@@ -0,0 +1,677 @@
+// Package config loads and validates GoopBot's configuration.
+//
+// Values are merged from, in increasing order of precedence: built-in defaults,
+// a JSON or YAML config file, a .env file, environment variables and command-line flags.
+package config
+
+import (
+	"GoopBot/internal/scheduler"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration that reads from strings like "5m" or "30s" in config files
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler (used by both JSON and YAML)
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// RateLimit allows Count uses every Per, written as "5/10s". The zero value, written "off",
+// allows any number.
+type RateLimit struct {
+	Count int
+	Per   time.Duration
+}
+
+// Enabled reports whether the limit restricts anything
+func (l RateLimit) Enabled() bool {
+	return l.Count > 0
+}
+
+// String formats the limit the way UnmarshalText reads it
+func (l RateLimit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Count, l.Per)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler (used by both JSON and YAML)
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "off" || value == "0" {
+		*l = RateLimit{}
+		return nil
+	}
+	count, per, ok := strings.Cut(value, "/")
+	if !ok {
+		return fmt.Errorf("rate limit %q must look like 5/10s or be off", value)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return fmt.Errorf("rate limit %q must start with a whole number of uses", value)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("rate limit %q must end with a positive duration such as 10s", value)
+	}
+	*l = RateLimit{Count: n, Per: d}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l RateLimit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// Rate limit scopes: how often each member, and each server, may use a command
+const (
+	RateLimitUser  = "user"
+	RateLimitGuild = "guild"
+)
+
+// RateLimits maps a scope to its limit. "user" and "guild" apply to every command;
+// "<command>.user" and "<command>.guild" override them for one command.
+type RateLimits map[string]RateLimit
+
+// For returns the limit of a command in a scope
+func (r RateLimits) For(command, scope string) RateLimit {
+	if limit, ok := r[command+"."+scope]; ok {
+		return limit
+	}
+	return r[scope]
+}
+
+// parseRateLimits merges a comma-separated list such as "user=5/10s,checkstreams.guild=1/1m"
+// into limits
+func parseRateLimits(limits *RateLimits, value string) error {
+	if *limits == nil {
+		*limits = make(RateLimits)
+	}
+	for _, item := range splitList(value) {
+		key, text, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("%q must look like user=5/10s", item)
+		}
+		var limit RateLimit
+		if err := limit.UnmarshalText([]byte(text)); err != nil {
+			return err
+		}
+		(*limits)[strings.TrimSpace(key)] = limit
+	}
+	return nil
+}
+
+// RedisConfig holds Redis connection settings
+type RedisConfig struct {
+	Addr     string `json:"addr" yaml:"addr"`
+	Password string `json:"password" yaml:"password"`
+	DB       int    `json:"db" yaml:"db"`
+}
+
+// Cache backends
+const (
+	CacheRedis  = "redis"
+	CacheMemory = "memory"
+)
+
+// TwitchConfig holds Twitch API credentials
+type TwitchConfig struct {
+	ClientID     string `json:"client_id" yaml:"client_id"`
+	ClientSecret string `json:"client_secret" yaml:"client_secret"`
+}
+
+// Config holds every tunable of the bot
+type Config struct {
+	DiscordToken   string `json:"discord_token" yaml:"discord_token"`
+	CommandGuildID string `json:"command_guild_id" yaml:"command_guild_id"` // Register slash commands for one guild only
+
+	// HTTPAddr is where to serve /healthz, /readyz and /metrics, e.g. ":8080". Empty disables it.
+	HTTPAddr string `json:"http_addr" yaml:"http_addr"`
+	// PublicURL is where users reach HTTPAddr, e.g. "https://goopbot.example.com", for dashboard
+	// login links. Empty uses http://localhost with the HTTPAddr port.
+	PublicURL string `json:"public_url" yaml:"public_url"`
+	// APIToken enables the admin API under /api on HTTPAddr. Requests must send it as a bearer token.
+	APIToken string `json:"api_token" yaml:"api_token"`
+
+	// ShardCount is the number of gateway shards; 0 uses the count Discord recommends.
+	// ShardID runs only that shard in this process; -1 runs every shard.
+	ShardCount int `json:"shard_count" yaml:"shard_count"`
+	ShardID    int `json:"shard_id" yaml:"shard_id"`
+
+	DBPath      string       `json:"db_path" yaml:"db_path"`
+	DatabaseURL string       `json:"database_url" yaml:"database_url"` // postgres:// URL; when set, DBPath is ignored
+	Cache       string       `json:"cache" yaml:"cache"`               // CacheRedis or CacheMemory
+	Redis       RedisConfig  `json:"redis" yaml:"redis"`
+	Twitch      TwitchConfig `json:"twitch" yaml:"twitch"`
+
+	// BackupDir enables scheduled online backups of the SQLite database into this directory.
+	// Every BackupInterval a copy is taken and all but the newest BackupKeep are deleted.
+	BackupDir      string   `json:"backup_dir" yaml:"backup_dir"`
+	BackupInterval Duration `json:"backup_interval" yaml:"backup_interval"`
+	BackupKeep     int      `json:"backup_keep" yaml:"backup_keep"`
+
+	// LeaderElection makes instances sharing a Redis server elect one of them to run each
+	// background job, instead of every instance running all of them. Requires the Redis cache.
+	LeaderElection bool `json:"leader_election" yaml:"leader_election"`
+
+	// GuildMembersIntent subscribes to member updates, so cached member roles change as soon
+	// as they do on Discord. The intent is privileged and must be enabled in the Developer Portal.
+	GuildMembersIntent bool `json:"guild_members_intent" yaml:"guild_members_intent"`
+
+	CommandPrefix   string   `json:"command_prefix" yaml:"command_prefix"`
+	CreatorRole     string   `json:"creator_role" yaml:"creator_role"`
+	MemberRole      string   `json:"member_role" yaml:"member_role"`
+	PollInterval    Duration `json:"poll_interval" yaml:"poll_interval"`
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	LogLevel        string   `json:"log_level" yaml:"log_level"`
+	LogFormat       string   `json:"log_format" yaml:"log_format"` // "text" or "json"
+
+	// Timezone is the IANA time zone, e.g. "Europe/Berlin", that cron schedules and
+	// birthdays go by. Empty uses the system's.
+	Timezone string `json:"timezone" yaml:"timezone"`
+	// BirthdaySchedule is the cron expression for when birthday messages go out
+	BirthdaySchedule string `json:"birthday_schedule" yaml:"birthday_schedule"`
+
+	// RateLimits limits how often members and servers may use commands (see RateLimits)
+	RateLimits RateLimits `json:"rate_limits" yaml:"rate_limits"`
+
+	// Modules lists the feature modules to run, e.g. ["twitch", "birthdays"]. Empty runs all of them.
+	Modules []string `json:"modules" yaml:"modules"`
+}
+
+// Database returns what to open: the Postgres URL when one is set, otherwise the SQLite path
+func (c Config) Database() string {
+	if c.DatabaseURL != "" {
+		return c.DatabaseURL
+	}
+	return c.DBPath
+}
+
+// IsPostgresURL reports whether dsn names a Postgres database rather than a SQLite file
+func IsPostgresURL(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+// RunsModule reports whether the named feature module is selected to run
+func (c Config) RunsModule(name string) bool {
+	if len(c.Modules) == 0 {
+		return true
+	}
+	for _, module := range c.Modules {
+		if strings.EqualFold(strings.TrimSpace(module), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the time zone named by Timezone, or the system's when it is empty or
+// invalid; Validate reports invalid names
+func (c Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// minAPITokenLength keeps API tokens long enough that guessing them is impractical
+const minAPITokenLength = 16
+
+// Default returns the configuration used when nothing else is specified
+func Default() Config {
+	return Config{
+		ShardID:         -1,
+		DBPath:          "./GoopBot.db",
+		Cache:           CacheRedis,
+		BackupInterval:  Duration{24 * time.Hour},
+		BackupKeep:      7,
+		Redis:           RedisConfig{Addr: "localhost:6379"},
+		CommandPrefix:   "!",
+		CreatorRole:     "Goop Creator",
+		MemberRole:      "member",
+		PollInterval:    Duration{5 * time.Minute},
+		ShutdownTimeout: Duration{30 * time.Second},
+		LogLevel:        "info",
+		LogFormat:       "text",
+		// Midnight, as birthdays have always gone out
+		BirthdaySchedule: "0 0 * * *",
+		RateLimits: RateLimits{
+			RateLimitUser:  {Count: 5, Per: 10 * time.Second},
+			RateLimitGuild: {Count: 30, Per: 10 * time.Second},
+			// Every manual check calls the Twitch API for every linked creator
+			"checkstreams." + RateLimitGuild: {Count: 1, Per: time.Minute},
+		},
+	}
+}
+
+// defaultConfigFiles are tried in order when no config file is given explicitly
+var defaultConfigFiles = []string{"config.json", "config.yaml", "config.yml"}
+
+// Load builds the configuration from all sources and validates it.
+// args are the command-line arguments without the program name.
+func Load(args []string) (Config, error) {
+	cfg, err := Read(args)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Read builds the configuration from all sources like Load but does not validate it,
+// for tools such as the migrate command that only need part of it.
+func Read(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("goopbot", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a JSON or YAML config file")
+	envFile := fs.String("env-file", ".env", "path to a .env file")
+	flagValues := registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	// .env values are loaded into the environment first so they can also name the config file.
+	// Variables that are already set in the environment take precedence over the .env file.
+	if err := godotenv.Load(*envFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("reading %s: %w", *envFile, err)
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if err := loadFile(&cfg, path); err != nil {
+		return Config{}, err
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	// Only flags given explicitly override the other sources
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if apply, ok := flagValues[f.Name]; ok && flagErr == nil {
+			flagErr = apply(&cfg)
+		}
+	})
+	if flagErr != nil {
+		return Config{}, flagErr
+	}
+	return cfg, nil
+}
+
+// loadFile merges a JSON or YAML config file into cfg. When path is empty the default
+// file names are tried and a missing file is not an error.
+func loadFile(cfg *Config, path string) error {
+	explicit := path != ""
+	candidates := []string{path}
+	if !explicit {
+		candidates = defaultConfigFiles
+	}
+
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && !explicit {
+				continue
+			}
+			return fmt.Errorf("reading config file: %w", err)
+		}
+
+		switch strings.ToLower(filepath.Ext(candidate)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, cfg)
+		default:
+			err = json.Unmarshal(data, cfg)
+		}
+		if err != nil {
+			return fmt.Errorf("parsing config file %s: %w", candidate, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// envSetter applies a single environment variable to the config
+type envSetter func(cfg *Config, value string) error
+
+func setString(field func(*Config) *string) envSetter {
+	return func(cfg *Config, value string) error {
+		*field(cfg) = value
+		return nil
+	}
+}
+
+func setInt(field func(*Config) *int) envSetter {
+	return func(cfg *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number: %w", err)
+		}
+		*field(cfg) = n
+		return nil
+	}
+}
+
+func setList(field func(*Config) *[]string) envSetter {
+	return func(cfg *Config, value string) error {
+		*field(cfg) = splitList(value)
+		return nil
+	}
+}
+
+// splitList parses a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func setBool(field func(*Config) *bool) envSetter {
+	return func(cfg *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = b
+		return nil
+	}
+}
+
+func setDuration(field func(*Config) *Duration) envSetter {
+	return func(cfg *Config, value string) error {
+		return field(cfg).UnmarshalText([]byte(value))
+	}
+}
+
+// envVars maps environment variable names to config fields
+var envVars = map[string]envSetter{
+	"DISCORD_TOKEN":        setString(func(c *Config) *string { return &c.DiscordToken }),
+	"DISCORD_GUILD_ID":     setString(func(c *Config) *string { return &c.CommandGuildID }),
+	"HTTP_ADDR":            setString(func(c *Config) *string { return &c.HTTPAddr }),
+	"PUBLIC_URL":           setString(func(c *Config) *string { return &c.PublicURL }),
+	"API_TOKEN":            setString(func(c *Config) *string { return &c.APIToken }),
+	"SHARD_COUNT":          setInt(func(c *Config) *int { return &c.ShardCount }),
+	"SHARD_ID":             setInt(func(c *Config) *int { return &c.ShardID }),
+	"DB_PATH":              setString(func(c *Config) *string { return &c.DBPath }),
+	"DATABASE_URL":         setString(func(c *Config) *string { return &c.DatabaseURL }),
+	"BACKUP_DIR":           setString(func(c *Config) *string { return &c.BackupDir }),
+	"BACKUP_INTERVAL":      setDuration(func(c *Config) *Duration { return &c.BackupInterval }),
+	"BACKUP_KEEP":          setInt(func(c *Config) *int { return &c.BackupKeep }),
+	"CACHE_BACKEND":        setString(func(c *Config) *string { return &c.Cache }),
+	"REDIS_ADDR":           setString(func(c *Config) *string { return &c.Redis.Addr }),
+	"REDIS_PASSWORD":       setString(func(c *Config) *string { return &c.Redis.Password }),
+	"REDIS_DB":             setInt(func(c *Config) *int { return &c.Redis.DB }),
+	"LEADER_ELECTION":      setBool(func(c *Config) *bool { return &c.LeaderElection }),
+	"GUILD_MEMBERS_INTENT": setBool(func(c *Config) *bool { return &c.GuildMembersIntent }),
+	"TWITCH_CLIENT_ID":     setString(func(c *Config) *string { return &c.Twitch.ClientID }),
+	"TWITCH_CLIENT_SECRET": setString(func(c *Config) *string { return &c.Twitch.ClientSecret }),
+	"COMMAND_PREFIX":       setString(func(c *Config) *string { return &c.CommandPrefix }),
+	"CREATOR_ROLE":         setString(func(c *Config) *string { return &c.CreatorRole }),
+	"MEMBER_ROLE":          setString(func(c *Config) *string { return &c.MemberRole }),
+	"POLL_INTERVAL":        setDuration(func(c *Config) *Duration { return &c.PollInterval }),
+	"TIMEZONE":             setString(func(c *Config) *string { return &c.Timezone }),
+	"BIRTHDAY_SCHEDULE":    setString(func(c *Config) *string { return &c.BirthdaySchedule }),
+	"SHUTDOWN_TIMEOUT":     setDuration(func(c *Config) *Duration { return &c.ShutdownTimeout }),
+	"LOG_LEVEL":            setString(func(c *Config) *string { return &c.LogLevel }),
+	"LOG_FORMAT":           setString(func(c *Config) *string { return &c.LogFormat }),
+	"MODULES":              setList(func(c *Config) *[]string { return &c.Modules }),
+	"RATE_LIMITS": func(c *Config, value string) error {
+		return parseRateLimits(&c.RateLimits, value)
+	},
+}
+
+// applyEnv merges environment variables into cfg. Empty variables are ignored.
+func applyEnv(cfg *Config) error {
+	for name, set := range envVars {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := set(cfg, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// registerFlags defines a command-line flag for every tunable and returns how to apply each one
+func registerFlags(fs *flag.FlagSet) map[string]func(*Config) error {
+	strFlag := func(name, usage string, field func(*Config) *string) func(*Config) error {
+		v := fs.String(name, "", usage)
+		return func(c *Config) error {
+			*field(c) = *v
+			return nil
+		}
+	}
+
+	redisDB := fs.Int("redis-db", 0, "Redis database number")
+	shardCount := fs.Int("shard-count", 0, "number of gateway shards (default: Discord's recommendation)")
+	shardID := fs.Int("shard-id", -1, "run only this shard (default: all shards)")
+	backupInterval := fs.Duration("backup-interval", 0, "how often to back up the database")
+	backupKeep := fs.Int("backup-keep", 0, "how many database backups to keep")
+	pollInterval := fs.Duration("poll-interval", 0, "how often to check Twitch stream status")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "how long to wait for in-flight work on shutdown")
+	leaderElection := fs.Bool("leader-election", false, "share background jobs with other instances through Redis")
+	guildMembersIntent := fs.Bool("guild-members-intent", false, "receive member updates (privileged intent) to keep cached roles current")
+	modules := fs.String("modules", "", "comma-separated feature modules to run (default: all)")
+	rateLimits := fs.String("rate-limits", "", "command rate limits to change, e.g. user=5/10s,checkstreams.guild=1/1m")
+
+	return map[string]func(*Config) error{
+		"discord-token":  strFlag("discord-token", "Discord bot token", func(c *Config) *string { return &c.DiscordToken }),
+		"guild":          strFlag("guild", "register slash commands for this guild only", func(c *Config) *string { return &c.CommandGuildID }),
+		"http-addr":      strFlag("http-addr", "serve health checks and metrics on this address, e.g. :8080", func(c *Config) *string { return &c.HTTPAddr }),
+		"public-url":     strFlag("public-url", "URL the HTTP server is reached at, for dashboard links", func(c *Config) *string { return &c.PublicURL }),
+		"api-token":      strFlag("api-token", "enable the admin API, authenticated with this bearer token", func(c *Config) *string { return &c.APIToken }),
+		"db":             strFlag("db", "path to the SQLite database", func(c *Config) *string { return &c.DBPath }),
+		"database-url":   strFlag("database-url", "Postgres URL (postgres://...), used instead of -db", func(c *Config) *string { return &c.DatabaseURL }),
+		"backup-dir":     strFlag("backup-dir", "back up the SQLite database into this directory", func(c *Config) *string { return &c.BackupDir }),
+		"cache":          strFlag("cache", "where to cache stream status and settings: redis or memory", func(c *Config) *string { return &c.Cache }),
+		"redis-addr":     strFlag("redis-addr", "Redis address (host:port)", func(c *Config) *string { return &c.Redis.Addr }),
+		"redis-password": strFlag("redis-password", "Redis password", func(c *Config) *string { return &c.Redis.Password }),
+		"twitch-client-id": strFlag("twitch-client-id", "Twitch API client ID",
+			func(c *Config) *string { return &c.Twitch.ClientID }),
+		"twitch-client-secret": strFlag("twitch-client-secret", "Twitch API client secret",
+			func(c *Config) *string { return &c.Twitch.ClientSecret }),
+		"prefix":       strFlag("prefix", "chat command prefix", func(c *Config) *string { return &c.CommandPrefix }),
+		"creator-role": strFlag("creator-role", "role allowed to link Twitch accounts", func(c *Config) *string { return &c.CreatorRole }),
+		"member-role":  strFlag("member-role", "role allowed to set birthdays", func(c *Config) *string { return &c.MemberRole }),
+		"log-level":    strFlag("log-level", "debug, info, warn or error", func(c *Config) *string { return &c.LogLevel }),
+		"log-format":   strFlag("log-format", "text or json", func(c *Config) *string { return &c.LogFormat }),
+		"timezone":     strFlag("timezone", "time zone for schedules and birthdays, e.g. Europe/Berlin", func(c *Config) *string { return &c.Timezone }),
+		"birthday-schedule": strFlag("birthday-schedule", "cron expression for when birthday messages go out",
+			func(c *Config) *string { return &c.BirthdaySchedule }),
+		"shard-count": func(c *Config) error {
+			c.ShardCount = *shardCount
+			return nil
+		},
+		"shard-id": func(c *Config) error {
+			c.ShardID = *shardID
+			return nil
+		},
+		"redis-db": func(c *Config) error {
+			c.Redis.DB = *redisDB
+			return nil
+		},
+		"leader-election": func(c *Config) error {
+			c.LeaderElection = *leaderElection
+			return nil
+		},
+		"guild-members-intent": func(c *Config) error {
+			c.GuildMembersIntent = *guildMembersIntent
+			return nil
+		},
+		"backup-interval": func(c *Config) error {
+			c.BackupInterval = Duration{*backupInterval}
+			return nil
+		},
+		"backup-keep": func(c *Config) error {
+			c.BackupKeep = *backupKeep
+			return nil
+		},
+		"poll-interval": func(c *Config) error {
+			c.PollInterval = Duration{*pollInterval}
+			return nil
+		},
+		"shutdown-timeout": func(c *Config) error {
+			c.ShutdownTimeout = Duration{*shutdownTimeout}
+			return nil
+		},
+		"modules": func(c *Config) error {
+			c.Modules = splitList(*modules)
+			return nil
+		},
+		"rate-limits": func(c *Config) error {
+			if err := parseRateLimits(&c.RateLimits, *rateLimits); err != nil {
+				return fmt.Errorf("invalid -rate-limits: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// Validate checks that required settings are present and values are in range.
+// All problems are reported at once.
+func (c Config) Validate() error {
+	var problems []string
+	require := func(value, name, source string) {
+		if strings.TrimSpace(value) == "" {
+			problems = append(problems, fmt.Sprintf("%s is required (set %s)", name, source))
+		}
+	}
+
+	require(c.DiscordToken, "Discord token", "DISCORD_TOKEN or -discord-token")
+	// Only Twitch notifications talk to the Twitch API
+	if c.RunsModule("twitch") {
+		require(c.Twitch.ClientID, "Twitch client ID", "TWITCH_CLIENT_ID or -twitch-client-id")
+		require(c.Twitch.ClientSecret, "Twitch client secret", "TWITCH_CLIENT_SECRET or -twitch-client-secret")
+	}
+	if c.DatabaseURL == "" {
+		require(c.DBPath, "database path", "DB_PATH or -db")
+	} else if !IsPostgresURL(c.DatabaseURL) {
+		problems = append(problems, "database URL must start with postgres:// or postgresql://")
+	}
+	if c.BackupDir != "" {
+		if c.DatabaseURL != "" {
+			problems = append(problems, "backups need the SQLite database; back up Postgres with pg_dump instead")
+		}
+		if c.BackupInterval.Duration < time.Minute {
+			problems = append(problems, fmt.Sprintf("backup interval must be at least 1m, got %s", c.BackupInterval))
+		}
+		if c.BackupKeep < 1 {
+			problems = append(problems, "backup keep must be at least 1")
+		}
+	}
+	switch c.Cache {
+	case CacheRedis:
+		require(c.Redis.Addr, "Redis address", "REDIS_ADDR or -redis-addr")
+	case CacheMemory:
+		if c.LeaderElection {
+			problems = append(problems, "leader election needs the Redis cache (CACHE_BACKEND=redis)")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("cache must be redis or memory, got %q", c.Cache))
+	}
+	require(c.CommandPrefix, "command prefix", "COMMAND_PREFIX or -prefix")
+	require(c.CreatorRole, "creator role name", "CREATOR_ROLE or -creator-role")
+	require(c.MemberRole, "member role name", "MEMBER_ROLE or -member-role")
+
+	if c.PublicURL != "" && !strings.HasPrefix(c.PublicURL, "http://") && !strings.HasPrefix(c.PublicURL, "https://") {
+		problems = append(problems, "public URL must start with http:// or https://")
+	}
+	if c.APIToken != "" {
+		if c.HTTPAddr == "" {
+			problems = append(problems, "the admin API needs an HTTP address (set HTTP_ADDR or -http-addr)")
+		}
+		if len(c.APIToken) < minAPITokenLength {
+			problems = append(problems, fmt.Sprintf("API token must be at least %d characters", minAPITokenLength))
+		}
+	}
+	if c.ShardCount < 0 {
+		problems = append(problems, "shard count must not be negative")
+	}
+	if c.ShardID >= 0 && c.ShardID >= c.ShardCount {
+		problems = append(problems, fmt.Sprintf("shard ID %d needs a shard count above it (set SHARD_COUNT or -shard-count)", c.ShardID))
+	}
+	if c.ShardID < -1 {
+		problems = append(problems, "shard ID must be -1 (all shards) or a shard number")
+	}
+	if c.Redis.DB < 0 {
+		problems = append(problems, "Redis DB must not be negative")
+	}
+	if c.PollInterval.Duration < 30*time.Second {
+		problems = append(problems, fmt.Sprintf("poll interval must be at least 30s, got %s", c.PollInterval))
+	}
+	if c.ShutdownTimeout.Duration <= 0 {
+		problems = append(problems, "shutdown timeout must be positive")
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			problems = append(problems, fmt.Sprintf("unknown time zone %q, use an IANA name such as Europe/Berlin", c.Timezone))
+		}
+	}
+	if _, err := scheduler.ParseCron(c.BirthdaySchedule, c.Location()); err != nil {
+		problems = append(problems, fmt.Sprintf("birthday schedule: %v", err))
+	}
+	for scope, limit := range c.RateLimits {
+		if scope != RateLimitUser && scope != RateLimitGuild &&
+			!strings.HasSuffix(scope, "."+RateLimitUser) && !strings.HasSuffix(scope, "."+RateLimitGuild) {
+			problems = append(problems, fmt.Sprintf("rate limit %q must be user, guild, <command>.user or <command>.guild", scope))
+		}
+		if limit.Count < 0 || (limit.Enabled() && limit.Per <= 0) {
+			problems = append(problems, fmt.Sprintf("rate limit %s must allow a positive number of uses per positive duration, got %s", scope, limit))
+		}
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("log level must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	switch strings.ToLower(c.LogFormat) {
+	case "text", "json":
+	default:
+		problems = append(problems, fmt.Sprintf("log format must be text or json, got %q", c.LogFormat))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
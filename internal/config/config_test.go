@@ -0,0 +1,285 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable the loader reads so the host environment cannot leak in
+func clearEnv(t *testing.T) {
+	t.Helper()
+	names := []string{"CONFIG_FILE"}
+	for name := range envVars {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		t.Setenv(name, "") // restores the original value after the test
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadMergesSourcesInPrecedenceOrder(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+
+	configFile := writeFile(t, dir, "config.yaml", `
+discord_token: from-file
+db_path: /data/file.db
+poll_interval: 10m
+redis:
+  addr: file:6379
+  db: 2
+twitch:
+  client_id: file-id
+  client_secret: file-secret
+`)
+	envFile := writeFile(t, dir, ".env", "DB_PATH=/data/dotenv.db\nREDIS_ADDR=dotenv:6379\n")
+	t.Setenv("REDIS_ADDR", "env:6379")
+
+	cfg, err := Load([]string{"-config", configFile, "-env-file", envFile, "-poll-interval", "2m"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.DiscordToken != "from-file" {
+		t.Errorf("discord token: got %q, want value from file", cfg.DiscordToken)
+	}
+	if cfg.DBPath != "/data/dotenv.db" {
+		t.Errorf("db path: got %q, want .env to override the file", cfg.DBPath)
+	}
+	if cfg.Redis.Addr != "env:6379" {
+		t.Errorf("redis addr: got %q, want the environment to override .env", cfg.Redis.Addr)
+	}
+	if cfg.Redis.DB != 2 {
+		t.Errorf("redis db: got %d, want 2", cfg.Redis.DB)
+	}
+	if cfg.PollInterval.Duration != 2*time.Minute {
+		t.Errorf("poll interval: got %s, want the flag to override the file", cfg.PollInterval)
+	}
+	if cfg.CreatorRole != "Goop Creator" || cfg.CommandPrefix != "!" {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+}
+
+func TestLoadReadsJSONFile(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	configFile := writeFile(t, dir, "bot.json", `{
+		"discord_token": "token",
+		"member_role": "Members",
+		"shutdown_timeout": "1m",
+		"twitch": {"client_id": "id", "client_secret": "secret"}
+	}`)
+
+	cfg, err := Load([]string{"-config", configFile, "-env-file", filepath.Join(dir, "missing.env")})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.MemberRole != "Members" || cfg.ShutdownTimeout.Duration != time.Minute {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadReportsAllMissingFields(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+
+	_, err := Load([]string{"-env-file", filepath.Join(dir, "missing.env"), "-poll-interval", "1s"})
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, want := range []string{"DISCORD_TOKEN", "TWITCH_CLIENT_ID", "TWITCH_CLIENT_SECRET", "poll interval"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadRejectsMalformedEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("REDIS_DB", "zero")
+
+	_, err := Load([]string{"-env-file", filepath.Join(t.TempDir(), "missing.env")})
+	if err == nil || !strings.Contains(err.Error(), "REDIS_DB") {
+		t.Fatalf("expected an error naming REDIS_DB, got %v", err)
+	}
+}
+
+func TestDatabaseURLSelectsPostgres(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DISCORD_TOKEN", "token")
+	t.Setenv("TWITCH_CLIENT_ID", "id")
+	t.Setenv("TWITCH_CLIENT_SECRET", "secret")
+	t.Setenv("DATABASE_URL", "postgres://goop@db/goopbot")
+	missingEnv := filepath.Join(t.TempDir(), "missing.env")
+
+	// A database URL replaces the SQLite path
+	cfg, err := Load([]string{"-env-file", missingEnv, "-db", ""})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database() != "postgres://goop@db/goopbot" {
+		t.Errorf("database: got %q, want the Postgres URL", cfg.Database())
+	}
+
+	_, err = Load([]string{"-env-file", missingEnv, "-database-url", "mysql://goop@db/goopbot"})
+	if err == nil || !strings.Contains(err.Error(), "postgres://") {
+		t.Fatalf("expected an error for a non-Postgres URL, got %v", err)
+	}
+
+	// Without a URL the SQLite path is used
+	cfg, err = Read([]string{"-env-file", missingEnv, "-database-url", ""})
+	if err != nil || cfg.Database() != "./GoopBot.db" {
+		t.Fatalf("expected the default SQLite path, got %q (err %v)", cfg.Database(), err)
+	}
+}
+
+func TestMemoryCacheNeedsNoRedis(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DISCORD_TOKEN", "token")
+	t.Setenv("TWITCH_CLIENT_ID", "id")
+	t.Setenv("TWITCH_CLIENT_SECRET", "secret")
+	t.Setenv("CACHE_BACKEND", "memory")
+	missingEnv := filepath.Join(t.TempDir(), "missing.env")
+
+	cfg, err := Load([]string{"-env-file", missingEnv, "-redis-addr", ""})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Cache != CacheMemory {
+		t.Errorf("cache: got %q, want memory", cfg.Cache)
+	}
+
+	_, err = Load([]string{"-env-file", missingEnv, "-cache", "redis", "-redis-addr", ""})
+	if err == nil || !strings.Contains(err.Error(), "Redis address is required") {
+		t.Fatalf("expected the Redis cache to need an address, got %v", err)
+	}
+	_, err = Load([]string{"-env-file", missingEnv, "-leader-election"})
+	if err == nil || !strings.Contains(err.Error(), "leader election needs the Redis cache") {
+		t.Fatalf("expected leader election to need Redis, got %v", err)
+	}
+	_, err = Load([]string{"-env-file", missingEnv, "-cache", "memcached"})
+	if err == nil || !strings.Contains(err.Error(), "cache must be redis or memory") {
+		t.Fatalf("expected an error for an unknown cache, got %v", err)
+	}
+}
+
+func TestAPITokenNeedsHTTPAddr(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DISCORD_TOKEN", "token")
+	t.Setenv("TWITCH_CLIENT_ID", "id")
+	t.Setenv("TWITCH_CLIENT_SECRET", "secret")
+	missingEnv := filepath.Join(t.TempDir(), "missing.env")
+
+	_, err := Load([]string{"-env-file", missingEnv, "-api-token", "short"})
+	if err == nil || !strings.Contains(err.Error(), "needs an HTTP address") || !strings.Contains(err.Error(), "at least 16 characters") {
+		t.Fatalf("expected errors for the address and token length, got %v", err)
+	}
+
+	cfg, err := Load([]string{"-env-file", missingEnv, "-api-token", "0123456789abcdef", "-http-addr", ":8080"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.APIToken != "0123456789abcdef" {
+		t.Errorf("API token: got %q", cfg.APIToken)
+	}
+}
+
+func TestBackupsNeedSQLite(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DISCORD_TOKEN", "token")
+	t.Setenv("TWITCH_CLIENT_ID", "id")
+	t.Setenv("TWITCH_CLIENT_SECRET", "secret")
+	missingEnv := filepath.Join(t.TempDir(), "missing.env")
+
+	_, err := Load([]string{"-env-file", missingEnv, "-backup-dir", "backups", "-database-url", "postgres://localhost/goopbot", "-backup-keep", "0"})
+	if err == nil || !strings.Contains(err.Error(), "backups need the SQLite database") || !strings.Contains(err.Error(), "backup keep must be at least 1") {
+		t.Fatalf("expected errors for Postgres and the retention, got %v", err)
+	}
+
+	t.Setenv("BACKUP_INTERVAL", "6h")
+	cfg, err := Load([]string{"-env-file", missingEnv, "-backup-dir", "backups"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.BackupDir != "backups" || cfg.BackupInterval.Duration != 6*time.Hour || cfg.BackupKeep != 7 {
+		t.Errorf("unexpected backup settings: %q every %s keeping %d", cfg.BackupDir, cfg.BackupInterval, cfg.BackupKeep)
+	}
+}
+
+func TestTwitchCredentialsOnlyForTwitchModule(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DISCORD_TOKEN", "token")
+	missingEnv := filepath.Join(t.TempDir(), "missing.env")
+
+	// Birthdays alone never talk to Twitch
+	cfg, err := Load([]string{"-env-file", missingEnv, "-modules", "birthdays,rolemessages"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.RunsModule("twitch") || !cfg.RunsModule("Birthdays") {
+		t.Errorf("unexpected modules: %v", cfg.Modules)
+	}
+
+	for _, modules := range []string{"", "birthdays, Twitch"} {
+		_, err := Load([]string{"-env-file", missingEnv, "-modules", modules})
+		if err == nil || !strings.Contains(err.Error(), "TWITCH_CLIENT_ID") {
+			t.Errorf("modules %q: expected the Twitch credentials to be required, got %v", modules, err)
+		}
+	}
+}
+
+func TestRateLimitsMergeOverDefaults(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	configFile := writeFile(t, dir, "config.yaml", `
+discord_token: token
+twitch:
+  client_id: id
+  client_secret: secret
+rate_limits:
+  gooplive.user: 2/30s
+  guild: "off"
+`)
+	t.Setenv("RATE_LIMITS", "checkstreams.user=1/5m")
+
+	cfg, err := Load([]string{"-config", configFile, "-env-file", filepath.Join(dir, "missing.env")})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, tc := range []struct {
+		command, scope string
+		want           RateLimit
+	}{
+		{"gooplive", RateLimitUser, RateLimit{Count: 2, Per: 30 * time.Second}},
+		{"help", RateLimitUser, RateLimit{Count: 5, Per: 10 * time.Second}},
+		{"help", RateLimitGuild, RateLimit{}},
+		{"checkstreams", RateLimitUser, RateLimit{Count: 1, Per: 5 * time.Minute}},
+		{"checkstreams", RateLimitGuild, RateLimit{Count: 1, Per: time.Minute}},
+	} {
+		if got := cfg.RateLimits.For(tc.command, tc.scope); got != tc.want {
+			t.Errorf("%s.%s: got %s, want %s", tc.command, tc.scope, got, tc.want)
+		}
+	}
+
+	t.Setenv("RATE_LIMITS", "gooplive.member=1/1s")
+	if _, err := Load([]string{"-config", configFile, "-env-file", filepath.Join(dir, "missing.env")}); err == nil || !strings.Contains(err.Error(), "gooplive.member") {
+		t.Fatalf("expected an unknown scope to be rejected, got %v", err)
+	}
+	t.Setenv("RATE_LIMITS", "user=5 per 10s")
+	if _, err := Load([]string{"-config", configFile, "-env-file", filepath.Join(dir, "missing.env")}); err == nil || !strings.Contains(err.Error(), "RATE_LIMITS") {
+		t.Fatalf("expected a malformed limit to be rejected, got %v", err)
+	}
+}
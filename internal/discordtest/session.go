@@ -0,0 +1,405 @@
+// Package discordtest provides an in-memory Discord session for driving the bot in tests
+// without a live gateway connection.
+package discordtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MaxMessageLength is the most characters Discord accepts in a message's content
+const MaxMessageLength = 2000
+
+// checkLength rejects content Discord would refuse as too long
+func checkLength(content string) error {
+	if n := utf8.RuneCountInString(content); n > MaxMessageLength {
+		return fmt.Errorf("message content is %d characters, over Discord's %d limit", n, MaxMessageLength)
+	}
+	return nil
+}
+
+// SentMessage records a message or embed the bot sent to a channel
+type SentMessage struct {
+	ChannelID string
+	Content   string
+	Embed     *discordgo.MessageEmbed
+	Files     []SentFile
+}
+
+// SentFile records a file attached to a sent message
+type SentFile struct {
+	Name string
+	Data []byte
+}
+
+// RoleChange records a role granted to a guild member
+type RoleChange struct {
+	GuildID string
+	UserID  string
+	RoleID  string
+}
+
+// InteractionReply records a response or follow-up sent for an interaction
+type InteractionReply struct {
+	InteractionID string
+	Type          discordgo.InteractionResponseType
+	Content       string
+	Choices       []*discordgo.ApplicationCommandOptionChoice
+}
+
+// Session is an in-memory implementation of the Discord operations the bot uses.
+// Guilds, channels, roles, members and messages are seeded with the Add* methods;
+// everything the bot sends is recorded and can be inspected afterwards.
+type Session struct {
+	mu sync.Mutex
+
+	guilds      map[string]*discordgo.Guild
+	channels    map[string]*discordgo.Channel
+	roles       map[string][]*discordgo.Role
+	members     map[string]*discordgo.Member // keyed by guildID/userID
+	permissions map[string]int64             // keyed by userID/channelID
+	messages    map[string]*discordgo.Message
+	dmChannels  map[string]string // DM channel ID by user ID
+
+	sent         []SentMessage
+	roleChanges  []RoleChange
+	interactions []InteractionReply
+	appCommands  map[string][]*discordgo.ApplicationCommand // keyed by guild ID, "" for global
+	status       *discordgo.UpdateStatusData
+	nextID       int
+}
+
+// NewSession creates an empty fake session
+func NewSession() *Session {
+	return &Session{
+		guilds:      make(map[string]*discordgo.Guild),
+		channels:    make(map[string]*discordgo.Channel),
+		roles:       make(map[string][]*discordgo.Role),
+		members:     make(map[string]*discordgo.Member),
+		permissions: make(map[string]int64),
+		messages:    make(map[string]*discordgo.Message),
+		dmChannels:  make(map[string]string),
+		appCommands: make(map[string][]*discordgo.ApplicationCommand),
+		nextID:      1000,
+	}
+}
+
+// AddGuild seeds a guild owned by ownerID
+func (s *Session) AddGuild(guildID, ownerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guilds[guildID] = &discordgo.Guild{ID: guildID, OwnerID: ownerID}
+}
+
+// AddChannel seeds a text channel in a guild
+func (s *Session) AddChannel(guildID, channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channelID] = &discordgo.Channel{ID: channelID, GuildID: guildID, Type: discordgo.ChannelTypeGuildText}
+}
+
+// AddRole seeds a guild role
+func (s *Session) AddRole(guildID, roleID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles[guildID] = append(s.roles[guildID], &discordgo.Role{ID: roleID, Name: name})
+}
+
+// AddMember seeds a guild member holding the given role IDs
+func (s *Session) AddMember(guildID, userID, username string, roleIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[guildID+"/"+userID] = &discordgo.Member{
+		GuildID: guildID,
+		User:    &discordgo.User{ID: userID, Username: username},
+		Roles:   append([]string(nil), roleIDs...),
+	}
+}
+
+// SetPermissions sets the computed permissions of a user in a channel
+func (s *Session) SetPermissions(userID, channelID string, permissions int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.permissions[userID+"/"+channelID] = permissions
+}
+
+// AddMessage seeds an existing message in a channel
+func (s *Session) AddMessage(channelID, messageID, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[messageID] = &discordgo.Message{ID: messageID, ChannelID: channelID, Content: content}
+}
+
+// Sent returns every message and embed sent so far
+func (s *Session) Sent() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
+
+// SentTo returns the messages and embeds sent to one channel
+func (s *Session) SentTo(channelID string) []SentMessage {
+	var out []SentMessage
+	for _, m := range s.Sent() {
+		if m.ChannelID == channelID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// SentToUser returns the direct messages sent to a user
+func (s *Session) SentToUser(userID string) []SentMessage {
+	s.mu.Lock()
+	channelID, ok := s.dmChannels[userID]
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.SentTo(channelID)
+}
+
+// RoleChanges returns every role granted so far
+func (s *Session) RoleChanges() []RoleChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RoleChange(nil), s.roleChanges...)
+}
+
+// InteractionReplies returns every interaction response and follow-up sent so far
+func (s *Session) InteractionReplies() []InteractionReply {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]InteractionReply(nil), s.interactions...)
+}
+
+// ApplicationCommands returns the commands last registered for a guild ("" for global)
+func (s *Session) ApplicationCommands(guildID string) []*discordgo.ApplicationCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appCommands[guildID]
+}
+
+// Status returns the presence last set by the bot, or nil
+func (s *Session) Status() *discordgo.UpdateStatusData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Reset clears everything recorded so far, keeping seeded state
+func (s *Session) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+	s.roleChanges = nil
+	s.interactions = nil
+}
+
+// newID returns a unique snowflake-like ID. Callers must hold s.mu.
+func (s *Session) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// Channel implements bot.Session
+func (s *Session) Channel(channelID string, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.channels[channelID]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown channel %s", channelID)
+}
+
+// Guild implements bot.Session
+func (s *Session) Guild(guildID string, _ ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if g, ok := s.guilds[guildID]; ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("unknown guild %s", guildID)
+}
+
+// GuildChannels implements bot.Session
+func (s *Session) GuildChannels(guildID string, _ ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.guilds[guildID]; !ok {
+		return nil, fmt.Errorf("unknown guild %s", guildID)
+	}
+	var channels []*discordgo.Channel
+	for _, c := range s.channels {
+		if c.GuildID == guildID {
+			channels = append(channels, c)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].ID < channels[j].ID })
+	return channels, nil
+}
+
+// GuildRoles implements bot.Session
+func (s *Session) GuildRoles(guildID string, _ ...discordgo.RequestOption) ([]*discordgo.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.guilds[guildID]; !ok {
+		return nil, fmt.Errorf("unknown guild %s", guildID)
+	}
+	return append([]*discordgo.Role(nil), s.roles[guildID]...), nil
+}
+
+// GuildMember implements bot.Session
+func (s *Session) GuildMember(guildID, userID string, _ ...discordgo.RequestOption) (*discordgo.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.members[guildID+"/"+userID]
+	if !ok {
+		return nil, fmt.Errorf("unknown member %s in guild %s", userID, guildID)
+	}
+	copied := *m
+	copied.Roles = append([]string(nil), m.Roles...)
+	return &copied, nil
+}
+
+// GuildMemberRoleAdd implements bot.Session
+func (s *Session) GuildMemberRoleAdd(guildID, userID, roleID string, _ ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.members[guildID+"/"+userID]
+	if !ok {
+		return fmt.Errorf("unknown member %s in guild %s", userID, guildID)
+	}
+	m.Roles = append(m.Roles, roleID)
+	s.roleChanges = append(s.roleChanges, RoleChange{GuildID: guildID, UserID: userID, RoleID: roleID})
+	return nil
+}
+
+// UserChannelPermissions implements bot.Session
+func (s *Session) UserChannelPermissions(userID, channelID string, _ ...discordgo.RequestOption) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.channels[channelID]; !ok {
+		return 0, fmt.Errorf("unknown channel %s", channelID)
+	}
+	return s.permissions[userID+"/"+channelID], nil
+}
+
+// UserChannelCreate implements bot.Session. Each user gets one DM channel.
+func (s *Session) UserChannelCreate(recipientID string, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.dmChannels[recipientID]
+	if !ok {
+		id = s.newID()
+		s.dmChannels[recipientID] = id
+	}
+	return &discordgo.Channel{ID: id, Type: discordgo.ChannelTypeDM, Recipients: []*discordgo.User{{ID: recipientID}}}, nil
+}
+
+// ChannelMessage implements bot.Session
+func (s *Session) ChannelMessage(channelID, messageID string, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.messages[messageID]; ok && m.ChannelID == channelID {
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown message %s in channel %s", messageID, channelID)
+}
+
+// ChannelMessageSend implements bot.Session
+func (s *Session) ChannelMessageSend(channelID, content string, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := checkLength(content); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, SentMessage{ChannelID: channelID, Content: content})
+	return &discordgo.Message{ID: s.newID(), ChannelID: channelID, Content: content}, nil
+}
+
+// ChannelMessageSendEmbed implements bot.Session
+func (s *Session) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, SentMessage{ChannelID: channelID, Embed: embed})
+	return &discordgo.Message{ID: s.newID(), ChannelID: channelID, Embeds: []*discordgo.MessageEmbed{embed}}, nil
+}
+
+// ChannelMessageSendComplex implements bot.Session. Attached files are read into the record.
+func (s *Session) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := checkLength(data.Content); err != nil {
+		return nil, err
+	}
+	sent := SentMessage{ChannelID: channelID, Content: data.Content}
+	if len(data.Embeds) > 0 {
+		sent.Embed = data.Embeds[0]
+	}
+	for _, file := range data.Files {
+		contents, err := io.ReadAll(file.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		sent.Files = append(sent.Files, SentFile{Name: file.Name, Data: contents})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, sent)
+	return &discordgo.Message{ID: s.newID(), ChannelID: channelID, Content: data.Content, Embeds: data.Embeds}, nil
+}
+
+// InteractionRespond implements bot.Session
+func (s *Session) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, _ ...discordgo.RequestOption) error {
+	if resp.Data != nil {
+		if err := checkLength(resp.Data.Content); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reply := InteractionReply{InteractionID: interaction.ID, Type: resp.Type}
+	if resp.Data != nil {
+		reply.Content = resp.Data.Content
+		reply.Choices = resp.Data.Choices
+	}
+	s.interactions = append(s.interactions, reply)
+	return nil
+}
+
+// FollowupMessageCreate implements bot.Session
+func (s *Session) FollowupMessageCreate(interaction *discordgo.Interaction, _ bool, data *discordgo.WebhookParams, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if err := checkLength(data.Content); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interactions = append(s.interactions, InteractionReply{
+		InteractionID: interaction.ID,
+		Type:          discordgo.InteractionResponseChannelMessageWithSource,
+		Content:       data.Content,
+	})
+	return &discordgo.Message{ID: s.newID(), ChannelID: interaction.ChannelID, Content: data.Content}, nil
+}
+
+// ApplicationCommandBulkOverwrite implements bot.Session
+func (s *Session) ApplicationCommandBulkOverwrite(_ string, guildID string, commands []*discordgo.ApplicationCommand, _ ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appCommands[guildID] = commands
+	return commands, nil
+}
+
+// UpdateStatusComplex implements bot.Session
+func (s *Session) UpdateStatusComplex(usd discordgo.UpdateStatusData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = &usd
+	return nil
+}
@@ -0,0 +1,171 @@
+// Package birthdays is the birthdays module: members record their birthday and the bot
+// congratulates them in the guild's birthday channel.
+package birthdays
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"GoopBot/internal/scheduler"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Name is the module name used in configuration and per-guild settings
+const Name = "birthdays"
+
+// Module implements bot.Module for birthday greetings
+type Module struct {
+	bot      *bot.Bot
+	log      bot.Logger
+	schedule scheduler.Schedule
+}
+
+// New creates the birthdays module
+func New() *Module {
+	return &Module{}
+}
+
+// Name implements bot.Module
+func (m *Module) Name() string {
+	return Name
+}
+
+// Init implements bot.Module
+func (m *Module) Init(b *bot.Bot) error {
+	m.bot = b
+	m.log = b.Logger().With(logging.KeyComponent, Name)
+	schedule, err := scheduler.ParseCron(b.Config().BirthdaySchedule, b.Location())
+	if err != nil {
+		return fmt.Errorf("failed to parse birthday schedule: %w", err)
+	}
+	m.schedule = schedule
+	return nil
+}
+
+// Handlers implements bot.Module
+func (m *Module) Handlers() []interface{} {
+	return nil
+}
+
+// Jobs implements bot.Module
+func (m *Module) Jobs() []bot.Job {
+	// Check birthdays on the configured schedule, and at startup when no run is recorded, as
+	// after a restart with the memory cache. A birthday already wished today isn't sent again.
+	return []bot.Job{{
+		Name:       "birthday monitoring",
+		Schedule:   m.schedule,
+		RunAtStart: true,
+		Run:        m.CheckBirthdays,
+	}}
+}
+
+// SetUserBirthday sets a user's birthday
+func (m *Module) SetUserBirthday(ctx context.Context, discordID, username, guildID, birthdayStr string) error {
+	// Parse birthday string (MM/DD format)
+	parts := strings.Split(birthdayStr, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid birthday format, use MM/DD (e.g., 03/15)")
+	}
+
+	month, err := strconv.Atoi(parts[0])
+	if err != nil || month < 1 || month > 12 {
+		return fmt.Errorf("invalid month, must be 01-12")
+	}
+
+	day, err := strconv.Atoi(parts[1])
+	if err != nil || day < 1 || day > 31 {
+		return fmt.Errorf("invalid day, must be 01-31")
+	}
+
+	// Create or update birthday record
+	return m.bot.Store().Birthdays.Set(ctx, models.Birthday{
+		DiscordID: discordID,
+		Username:  username,
+		GuildID:   guildID,
+		Month:     month,
+		Day:       day,
+	})
+}
+
+// SetBirthdayChannel sets the birthday notification channel for a guild
+func (m *Module) SetBirthdayChannel(ctx context.Context, guildID, channelID string) error {
+	return m.bot.Store().BirthdayChannels.Activate(ctx, guildID, channelID)
+}
+
+// GetUpcomingBirthdays gets upcoming birthdays for a guild: the rest of this month and all of the next
+func (m *Module) GetUpcomingBirthdays(ctx context.Context, guildID string) ([]models.Birthday, error) {
+	now := m.bot.Now()
+	birthdays, err := m.bot.Store().Birthdays.ListUpcoming(ctx, guildID, int(now.Month()), now.Day())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get birthdays: %w", err)
+	}
+	return birthdays, nil
+}
+
+// CheckBirthdays checks for today's birthdays and sends notifications
+func (m *Module) CheckBirthdays(ctx context.Context) {
+	store := m.bot.Store()
+	now := m.bot.Now()
+
+	// Get all birthdays for today
+	birthdays, err := store.Birthdays.ListOn(ctx, int(now.Month()), now.Day())
+	if err != nil {
+		m.log.With(logging.KeyError, err).Errorf("Failed to get today's birthdays")
+		return
+	}
+
+	for _, birthday := range birthdays {
+		if ctx.Err() != nil {
+			m.log.Warnf("Birthday check interrupted: %v", ctx.Err())
+			return
+		}
+
+		log := m.log.With(logging.KeyGuildID, birthday.GuildID, logging.KeyUserID, birthday.DiscordID)
+
+		// Check if we already sent a birthday message today. The database may hand LastSent
+		// back in UTC, so it is compared in the bot's time zone.
+		if birthday.LastSent.In(now.Location()).Format("2006-01-02") == now.Format("2006-01-02") {
+			continue
+		}
+
+		// Guilds on other shards are checked by the process running them
+		if !m.bot.HandlesGuild(birthday.GuildID) {
+			continue
+		}
+
+		settings := m.bot.GuildConfig(birthday.GuildID)
+		if !settings.ModuleEnabled(Name) {
+			continue
+		}
+
+		// Get birthday channel for this guild, falling back to the default notification channel
+		channel, err := store.BirthdayChannels.Active(ctx, birthday.GuildID)
+		if err != nil {
+			if settings.NotificationChannelID == "" {
+				log.Warnf("No active birthday channel found")
+				continue
+			}
+			channel.ChannelID = settings.NotificationChannelID
+		}
+
+		// Send birthday message
+		message := fmt.Sprintf("🎉 **Happy Birthday** <@%s>! 🎂\nHope you have a wonderful day! 🎈", birthday.DiscordID)
+
+		_, err = m.bot.Session().ChannelMessageSend(channel.ChannelID, message)
+		m.bot.Metrics().BirthdayMessageSent(err)
+		if err != nil {
+			log.With(logging.KeyChannelID, channel.ChannelID, logging.KeyError, err).Errorf("Failed to send birthday message for %s", birthday.Username)
+			continue
+		}
+
+		// Update last sent timestamp
+		if err := store.Birthdays.MarkSent(ctx, birthday.DiscordID, now); err != nil {
+			log.With(logging.KeyError, err).Errorf("Failed to update birthday last sent for %s", birthday.Username)
+		}
+
+		log.Infof("Sent birthday message for %s", birthday.Username)
+	}
+}
@@ -0,0 +1,86 @@
+package birthdays_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+	"GoopBot/internal/features/birthdays"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"GoopBot/internal/scheduler"
+)
+
+func TestSetBirthday(t *testing.T) {
+	b, fake := bottest.NewWithStore(t, repository.NewMemory(), birthdays.New())
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!setbirthday 03/15"), "You need the 'member' role")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!setbirthday 13/01"), "invalid month")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!setbirthday 03/15"), "Successfully set your birthday")
+
+	birthdays, err := b.Store().Birthdays.ListOn(context.Background(), 3, 15)
+	if err != nil || len(birthdays) != 1 || birthdays[0].DiscordID != bottest.MemberID {
+		t.Fatalf("birthday was not stored: %+v (err %v)", birthdays, err)
+	}
+}
+
+func TestBirthdaysListsUpcoming(t *testing.T) {
+	b, fake := bottest.NewWithStore(t, repository.NewMemory(), birthdays.New())
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!birthdays"), "No upcoming birthdays")
+
+	now := time.Now()
+	b.Store().Birthdays.Set(context.Background(), models.Birthday{DiscordID: bottest.MemberID, Username: "member", GuildID: bottest.GuildID, Month: int(now.Month()), Day: now.Day()})
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!birthdays"), fmt.Sprintf("member - %02d/%02d", int(now.Month()), now.Day()))
+}
+
+func TestCheckBirthdaysSendsOncePerDay(t *testing.T) {
+	m := birthdays.New()
+	b, fake := bottest.NewWithStore(t, repository.NewMemory(), m)
+	ctx := context.Background()
+
+	now := time.Now()
+	b.Store().Birthdays.Set(ctx, models.Birthday{DiscordID: bottest.MemberID, Username: "member", GuildID: bottest.GuildID, Month: int(now.Month()), Day: now.Day()})
+
+	// Without a birthday channel or default channel there is nowhere to send
+	m.CheckBirthdays(ctx)
+	if len(fake.Sent()) != 0 {
+		t.Fatalf("sent without a birthday channel: %+v", fake.Sent())
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setbirthdaychannel <#"+bottest.LiveChannelID+">"), "Successfully set birthday notification channel")
+	m.CheckBirthdays(ctx)
+	m.CheckBirthdays(ctx)
+	sent := fake.SentTo(bottest.LiveChannelID)
+	if len(sent) != 1 || !strings.Contains(sent[0].Content, "<@"+bottest.MemberID+">") {
+		t.Fatalf("expected one birthday message, got %+v", sent)
+	}
+}
+
+func TestCheckBirthdaysComparesLastSentInLocalTime(t *testing.T) {
+	cfg := config.Default()
+	cfg.RateLimits = nil
+	cfg.Timezone = "Pacific/Auckland"
+	loc, _ := time.LoadLocation(cfg.Timezone)
+	// 10:00 on 03/15 in Auckland is still 03/14 in UTC
+	clock := scheduler.NewFakeClock(time.Date(2026, 3, 15, 10, 0, 0, 0, loc))
+
+	m := birthdays.New()
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Config: cfg, Store: repository.NewMemory(), Clock: clock}, m)
+	ctx := context.Background()
+	store := b.Store()
+
+	store.Birthdays.Set(ctx, models.Birthday{DiscordID: bottest.MemberID, Username: "member", GuildID: bottest.GuildID, Month: 3, Day: 15})
+	store.BirthdayChannels.Activate(ctx, bottest.GuildID, bottest.LiveChannelID)
+	store.Birthdays.MarkSent(ctx, bottest.MemberID, clock.Now().Add(-time.Hour).UTC())
+
+	m.CheckBirthdays(ctx)
+	if sent := fake.SentTo(bottest.LiveChannelID); len(sent) != 0 {
+		t.Fatalf("wished a happy birthday twice on the same local day: %+v", sent)
+	}
+}
@@ -0,0 +1,74 @@
+package birthdays
+
+import (
+	"GoopBot/internal/bot"
+	"fmt"
+)
+
+// Commands implements bot.Module
+func (m *Module) Commands() []*bot.Command {
+	return []*bot.Command{
+		{
+			Name:        "setbirthday",
+			Description: "Set your birthday (MM/DD)",
+			Category:    bot.CategoryGeneral,
+			Args:        []bot.Arg{{Name: "date", Description: "Your birthday as MM/DD, e.g. 03/15", Type: bot.ArgString, Required: true}},
+			Permission:  bot.PermissionMember,
+			Handler:     m.cmdSetBirthday,
+		},
+		{
+			Name:        "setbirthdaychannel",
+			Description: "Set birthday notification channel",
+			Category:    bot.CategoryGeneral,
+			Args:        []bot.Arg{{Name: "channel", Description: "Channel for birthday messages", Type: bot.ArgChannel, Required: true}},
+			Permission:  bot.PermissionAdmin,
+			Handler:     m.cmdSetBirthdayChannel,
+		},
+		{
+			Name:        "birthdays",
+			Description: "Show upcoming birthdays",
+			Category:    bot.CategoryGeneral,
+			Handler:     m.cmdBirthdays,
+		},
+	}
+}
+
+func (m *Module) cmdSetBirthday(ctx *bot.CommandContext) error {
+	if err := m.SetUserBirthday(ctx.Context(), ctx.Author.ID, ctx.Author.Username, ctx.GuildID, ctx.Arg("date")); err != nil {
+		return fmt.Errorf("failed to set birthday: %w", err)
+	}
+	ctx.Reply("🎂 Successfully set your birthday!")
+	return nil
+}
+
+func (m *Module) cmdSetBirthdayChannel(ctx *bot.CommandContext) error {
+	var before string
+	if previous, err := m.bot.Store().BirthdayChannels.Active(ctx.Context(), ctx.GuildID); err == nil {
+		before = fmt.Sprintf("<#%s>", previous.ChannelID)
+	}
+	if err := m.SetBirthdayChannel(ctx.Context(), ctx.GuildID, ctx.Arg("channel")); err != nil {
+		return fmt.Errorf("failed to set birthday channel: %w", err)
+	}
+	ctx.Audit("set birthday channel", before, fmt.Sprintf("<#%s>", ctx.Arg("channel")))
+	ctx.Reply("🎂 Successfully set birthday notification channel!")
+	return nil
+}
+
+func (m *Module) cmdBirthdays(ctx *bot.CommandContext) error {
+	birthdays, err := m.GetUpcomingBirthdays(ctx.Context(), ctx.GuildID)
+	if err != nil {
+		return err
+	}
+
+	if len(birthdays) == 0 {
+		ctx.Reply("🎂 No upcoming birthdays found!")
+		return nil
+	}
+
+	message := "🎂 **Upcoming Birthdays:**\n"
+	for _, birthday := range birthdays {
+		message += fmt.Sprintf("• %s - %02d/%02d\n", birthday.Username, birthday.Month, birthday.Day)
+	}
+	ctx.Reply(message)
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package features lists the modules built into GoopBot and picks the ones a deployment runs.
+// To add a module, implement bot.Module in a package under internal/features and add it to Builtin.
+package features
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/features/birthdays"
+	"GoopBot/internal/features/rolemessages"
+	"GoopBot/internal/features/twitchlive"
+	"fmt"
+	"strings"
+)
+
+// Builtin returns a fresh instance of every built-in module
+func Builtin() []bot.Module {
+	return []bot.Module{
+		twitchlive.New(nil),
+		birthdays.New(),
+		rolemessages.New(),
+	}
+}
+
+// Select returns the built-in modules named in names, in the order given.
+// An empty list selects every built-in module.
+func Select(names []string) ([]bot.Module, error) {
+	all := Builtin()
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	byName := make(map[string]bot.Module, len(all))
+	var known []string
+	for _, m := range all {
+		byName[m.Name()] = m
+		known = append(known, m.Name())
+	}
+
+	var selected []bot.Module
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		m, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown module %q (available: %s)", name, strings.Join(known, ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, m)
+		}
+	}
+	return selected, nil
+}
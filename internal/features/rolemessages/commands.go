@@ -0,0 +1,135 @@
+package rolemessages
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/logging"
+	"fmt"
+	"strings"
+)
+
+// category is the help section the role message commands are listed under
+const category = "Role Management Commands"
+
+// Commands implements bot.Module
+func (m *Module) Commands() []*bot.Command {
+	return []*bot.Command{
+		{
+			Name:        "setrolemessage",
+			Description: "Set a message to grant a role when reacted to (default: the member role)",
+			Category:    category,
+			Args: []bot.Arg{
+				{Name: "message_id", Description: "ID of a message in this channel", Type: bot.ArgString, Required: true},
+				{Name: "role_name", Description: "Role to grant (default: the member role)", Type: bot.ArgRole},
+			},
+			Permission: bot.PermissionAdmin,
+			Handler:    m.cmdSetRoleMessage,
+		},
+		{
+			Name:        "removerolemessage",
+			Description: "Remove role-granting from a message",
+			Category:    category,
+			Args: []bot.Arg{{
+				Name:        "message_id",
+				Description: "ID of the role message",
+				Type:        bot.ArgString,
+				Required:    true,
+				Complete:    m.completeRoleMessageIDs,
+			}},
+			Permission: bot.PermissionAdmin,
+			Handler:    m.cmdRemoveRoleMessage,
+		},
+		{
+			Name:        "listrolemessages",
+			Description: "List all active role-granting messages",
+			Category:    category,
+			Permission:  bot.PermissionAdmin,
+			Handler:     m.cmdListRoleMessages,
+		},
+	}
+}
+
+func (m *Module) cmdSetRoleMessage(ctx *bot.CommandContext) error {
+	messageID := ctx.Arg("message_id")
+	roleName := ctx.Arg("role_name")
+	if roleName == "" {
+		roleName = ctx.Settings.MemberRole
+	}
+
+	// Verify the role exists
+	role := m.bot.FindRole(ctx.GuildID, roleName)
+	if role == nil {
+		return fmt.Errorf("role '%s' not found in this server", roleName)
+	}
+
+	// Verify the message exists and get its channel
+	msg, err := ctx.Session.ChannelMessage(ctx.ChannelID, messageID)
+	if err != nil {
+		return fmt.Errorf("message not found! Make sure the message ID is correct and the message is in this channel")
+	}
+
+	var before string
+	if previous, err := m.bot.Store().RoleMessages.FindActive(ctx.Context(), messageID); err == nil {
+		before = describeRoleMessage(previous.MessageID, previous.RoleName)
+	}
+	if err := m.SetRoleMessage(ctx.Context(), ctx.GuildID, msg.ChannelID, messageID, role.Name); err != nil {
+		return fmt.Errorf("failed to set role message: %w", err)
+	}
+	ctx.Audit("set role message", before, describeRoleMessage(messageID, role.Name))
+	ctx.Replyf("✅ Message %s will now grant the '%s' role when reacted to!", messageID, role.Name)
+	return nil
+}
+
+func (m *Module) cmdRemoveRoleMessage(ctx *bot.CommandContext) error {
+	messageID := ctx.Arg("message_id")
+	var before string
+	if previous, err := m.bot.Store().RoleMessages.FindActive(ctx.Context(), messageID); err == nil {
+		before = describeRoleMessage(previous.MessageID, previous.RoleName)
+	}
+	if err := m.RemoveRoleMessage(ctx.Context(), messageID); err != nil {
+		return fmt.Errorf("failed to remove role message: %w", err)
+	}
+	ctx.Audit("remove role message", before, "")
+	ctx.Reply("✅ Role message removed successfully!")
+	return nil
+}
+
+func (m *Module) cmdListRoleMessages(ctx *bot.CommandContext) error {
+	roleMessages, err := m.GetRoleMessages(ctx.Context(), ctx.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get role messages: %w", err)
+	}
+
+	if len(roleMessages) == 0 {
+		ctx.Reply("📝 No role messages are currently set up.")
+		return nil
+	}
+
+	message := "📝 **Active Role Messages:**\n"
+	for _, rm := range roleMessages {
+		message += fmt.Sprintf("• Message ID: `%s` → Role: `%s` (in <#%s>)\n", rm.MessageID, rm.RoleName, rm.ChannelID)
+	}
+	ctx.Reply(message)
+	return nil
+}
+
+// describeRoleMessage describes a role message for the audit log
+func describeRoleMessage(messageID, roleName string) string {
+	return fmt.Sprintf("message %s grants '%s'", messageID, roleName)
+}
+
+// completeRoleMessageIDs suggests active role message IDs for the guild
+func (m *Module) completeRoleMessageIDs(guildID, userID, partial string) []string {
+	roleMessages, err := m.GetRoleMessages(m.bot.WorkContext(), guildID)
+	if err != nil {
+		m.log.With(logging.KeyGuildID, guildID, logging.KeyError, err).Errorf("Failed to get role messages for autocomplete")
+		return nil
+	}
+
+	var ids []string
+	for _, rm := range roleMessages {
+		if strings.HasPrefix(rm.MessageID, partial) {
+			ids = append(ids, rm.MessageID)
+		}
+	}
+	return ids
+}
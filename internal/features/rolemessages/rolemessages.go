@@ -0,0 +1,125 @@
+// Package rolemessages is the reaction roles module: reacting to a designated message
+// grants the reacting member a role.
+package rolemessages
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Name is the module name used in configuration and per-guild settings
+const Name = "rolemessages"
+
+// Module implements bot.Module for role-granting messages
+type Module struct {
+	bot *bot.Bot
+	log bot.Logger
+}
+
+// New creates the role messages module
+func New() *Module {
+	return &Module{}
+}
+
+// Name implements bot.Module
+func (m *Module) Name() string {
+	return Name
+}
+
+// Init implements bot.Module
+func (m *Module) Init(b *bot.Bot) error {
+	m.bot = b
+	m.log = b.Logger().With(logging.KeyComponent, Name)
+	return nil
+}
+
+// Handlers implements bot.Module
+func (m *Module) Handlers() []interface{} {
+	return []interface{}{m.handleMessageReactionAdd}
+}
+
+// Jobs implements bot.Module
+func (m *Module) Jobs() []bot.Job {
+	return nil
+}
+
+// handleMessageReactionAdd handles when a user adds a reaction to a message
+func (m *Module) handleMessageReactionAdd(_ *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	// Ignore reactions from bots
+	if m.bot.IsSelf(r.UserID) {
+		return
+	}
+
+	// Check if this message is designated as a role-granting message
+	roleMessage, err := m.bot.Store().RoleMessages.FindActive(m.bot.WorkContext(), r.MessageID)
+	if err != nil {
+		// Message is not set up for role granting, ignore
+		return
+	}
+
+	if !m.bot.GuildConfig(r.GuildID).ModuleEnabled(Name) {
+		return
+	}
+
+	log := m.log.With(logging.KeyGuildID, r.GuildID, logging.KeyUserID, r.UserID, "message_id", r.MessageID)
+	session := m.bot.Session()
+
+	// Get guild member
+	member, err := session.GuildMember(r.GuildID, r.UserID)
+	if err != nil {
+		log.With(logging.KeyError, err).Errorf("Failed to get guild member for reaction")
+		return
+	}
+
+	// Check if user already has the target role
+	if m.bot.HasRole(r.GuildID, member.Roles, roleMessage.RoleName) {
+		return // User already has the role
+	}
+
+	// Get the target role ID
+	targetRoleID := m.bot.RoleID(r.GuildID, roleMessage.RoleName)
+	if targetRoleID == "" {
+		log.Warnf("Could not find '%s' role", roleMessage.RoleName)
+		return
+	}
+
+	// Assign the target role to the user
+	if err := session.GuildMemberRoleAdd(r.GuildID, r.UserID, targetRoleID); err != nil {
+		log.With(logging.KeyError, err).Errorf("Failed to add %s role", roleMessage.RoleName)
+		return
+	}
+
+	log.Infof("Added '%s' role to %s for reacting to a role message", roleMessage.RoleName, member.User.Username)
+
+	// Optionally send a DM to the user (uncomment if desired)
+	/*
+		channel, err := session.UserChannelCreate(r.UserID)
+		if err == nil {
+			session.ChannelMessageSend(channel.ID, fmt.Sprintf("🎉 Welcome! You've been given the '%s' role for participating in the server!", roleMessage.RoleName))
+		}
+	*/
+}
+
+// SetRoleMessage sets a message to grant a role when reacted to
+func (m *Module) SetRoleMessage(ctx context.Context, guildID, channelID, messageID, roleName string) error {
+	return m.bot.Store().RoleMessages.Set(ctx, models.RoleMessage{
+		GuildID:   guildID,
+		ChannelID: channelID,
+		MessageID: messageID,
+		RoleName:  roleName,
+	})
+}
+
+// RemoveRoleMessage removes role-granting functionality from a message
+func (m *Module) RemoveRoleMessage(ctx context.Context, messageID string) error {
+	return m.bot.Store().RoleMessages.Remove(ctx, messageID)
+}
+
+// GetRoleMessages gets all active role messages for a guild
+func (m *Module) GetRoleMessages(ctx context.Context, guildID string) ([]models.RoleMessage, error) {
+	return m.bot.Store().RoleMessages.ListActive(ctx, guildID)
+}
@@ -0,0 +1,48 @@
+package rolemessages_test
+
+import (
+	"testing"
+
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/features/rolemessages"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRoleMessageGrantsRoleOnReaction(t *testing.T) {
+	b, fake := bottest.New(t, rolemessages.New())
+	fake.AddMessage(bottest.ChannelID, "555", "React to get the member role!")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setrolemessage 555 nosuchrole"), "role 'nosuchrole' not found")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setrolemessage 556"), "message not found")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setrolemessage 555"), "will now grant the 'member' role")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!listrolemessages"), "`555` → Role: `member`")
+
+	react := &discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+		UserID: bottest.StrangerID, MessageID: "555", ChannelID: bottest.ChannelID, GuildID: bottest.GuildID,
+	}}
+	b.Dispatch(react)
+	changes := fake.RoleChanges()
+	if len(changes) != 1 || changes[0].UserID != bottest.StrangerID || changes[0].RoleID != bottest.MemberRoleID {
+		t.Fatalf("unexpected role changes: %+v", changes)
+	}
+
+	// Reacting again does not grant the role twice
+	b.Dispatch(react)
+	if len(fake.RoleChanges()) != 1 {
+		t.Fatalf("role granted twice: %+v", fake.RoleChanges())
+	}
+
+	// Reactions are ignored while the module is disabled
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!disablemodule rolemessages"), "Disabled the rolemessages module")
+	b.Dispatch(&discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+		UserID: bottest.CreatorID, MessageID: "555", ChannelID: bottest.ChannelID, GuildID: bottest.GuildID,
+	}})
+	if len(fake.RoleChanges()) != 0 {
+		t.Fatalf("role granted while the module is disabled: %+v", fake.RoleChanges())
+	}
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!enablemodule rolemessages"), "Enabled the rolemessages module")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!removerolemessage 555"), "removed successfully")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!listrolemessages"), "No role messages")
+}
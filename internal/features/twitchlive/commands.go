@@ -0,0 +1,128 @@
+package twitchlive
+
+import (
+	"GoopBot/internal/bot"
+	"fmt"
+	"strings"
+)
+
+// Commands implements bot.Module
+func (m *Module) Commands() []*bot.Command {
+	return []*bot.Command{
+		{
+			Name:        "linktwitch",
+			Description: "Link your Twitch username",
+			Category:    bot.CategoryGeneral,
+			Args:        []bot.Arg{{Name: "username", Description: "Your Twitch username", Type: bot.ArgString, Required: true}},
+			Permission:  bot.PermissionCreator,
+			Handler:     m.cmdLinkTwitch,
+		},
+		{
+			Name:        "unlinktwitch",
+			Description: "Unlink your Twitch username",
+			Category:    bot.CategoryGeneral,
+			Permission:  bot.PermissionCreator,
+			Handler:     m.cmdUnlinkTwitch,
+		},
+		{
+			Name:        "setnotifications",
+			Description: "Set notification channel for live streams",
+			Category:    bot.CategoryGeneral,
+			Args:        []bot.Arg{{Name: "channel", Description: "Channel for live notifications", Type: bot.ArgChannel, Required: true}},
+			Permission:  bot.PermissionAdmin,
+			Handler:     m.cmdSetNotifications,
+		},
+		{
+			Name:        "gooplive",
+			Aliases:     []string{"live"},
+			Description: "Show currently live Goop Creators",
+			Category:    bot.CategoryGeneral,
+			Handler:     m.cmdGoopLive,
+		},
+		{
+			Name:        "checkstreams",
+			Description: "Manually check stream status",
+			Category:    bot.CategoryGeneral,
+			Permission:  bot.PermissionAdmin,
+			Handler:     m.cmdCheckStreams,
+		},
+	}
+}
+
+func (m *Module) cmdLinkTwitch(ctx *bot.CommandContext) error {
+	username := ctx.Arg("username")
+	if err := m.LinkTwitchAccount(ctx.Context(), ctx.Author.ID, ctx.Author.Username, ctx.GuildID, username); err != nil {
+		return fmt.Errorf("failed to link Twitch account: %w", err)
+	}
+	ctx.Replyf("✅ Successfully linked your Twitch account: %s", username)
+	return nil
+}
+
+func (m *Module) cmdUnlinkTwitch(ctx *bot.CommandContext) error {
+	if err := m.UnlinkTwitchAccount(ctx.Context(), ctx.Author.ID); err != nil {
+		return fmt.Errorf("failed to unlink Twitch account: %w", err)
+	}
+	ctx.Reply("✅ Successfully unlinked your Twitch account")
+	return nil
+}
+
+func (m *Module) cmdSetNotifications(ctx *bot.CommandContext) error {
+	channelID := ctx.Arg("channel")
+	previous, err := m.bot.Store().NotificationChannels.ListActive(ctx.Context(), ctx.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification channels: %w", err)
+	}
+	if err := m.SetNotificationChannel(ctx.Context(), ctx.GuildID, channelID); err != nil {
+		return fmt.Errorf("failed to set notification channel: %w", err)
+	}
+
+	var before []string
+	for _, channel := range previous {
+		before = append(before, fmt.Sprintf("<#%s>", channel.ChannelID))
+	}
+	ctx.Audit("set notification channel", strings.Join(before, ", "), fmt.Sprintf("<#%s>", channelID))
+	ctx.Replyf("✅ Successfully set <#%s> as the live notification channel", channelID)
+	return nil
+}
+
+func (m *Module) cmdGoopLive(ctx *bot.CommandContext) error {
+	liveCreators, err := m.GetLiveGoopCreators(ctx.Context(), ctx.GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to get live creators: %w", err)
+	}
+
+	if len(liveCreators) == 0 {
+		ctx.Reply("No Goop Creators are currently live 😴")
+		return nil
+	}
+
+	message := "**🔴 Currently Live Goop Creators:**\n"
+	for _, stream := range liveCreators {
+		message += fmt.Sprintf("• **%s** - %s\n", stream.TwitchUsername, stream.StreamTitle)
+		message += fmt.Sprintf("  └ Playing: %s | Viewers: %d\n", stream.GameName, stream.ViewerCount)
+		message += fmt.Sprintf("  └ https://twitch.tv/%s\n\n", stream.TwitchUsername)
+	}
+	ctx.Reply(message)
+	return nil
+}
+
+func (m *Module) cmdCheckStreams(ctx *bot.CommandContext) error {
+	// One manual check at a time is plenty; each one calls Twitch for every creator
+	if !m.manualCheck.CompareAndSwap(false, true) {
+		ctx.Reply("⏳ A stream status check is already running")
+		return nil
+	}
+	ctx.Reply("🔄 Checking stream status...")
+
+	// Run stream check in background
+	started := m.bot.StartTask(func() {
+		defer m.manualCheck.Store(false)
+		m.CheckStreamStatus(m.bot.WorkContext())
+		ctx.Reply("✅ Stream status check completed!")
+	})
+	if !started {
+		m.manualCheck.Store(false)
+		return fmt.Errorf("the bot is shutting down, try again later")
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+// Package twitchlive is the Twitch module: creators link their Twitch accounts and the
+// bot posts a notification when they go live.
+package twitchlive
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"GoopBot/internal/scheduler"
+	"GoopBot/internal/twitch"
+	"GoopBot/storage/cache"
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Name is the module name used in configuration and per-guild settings
+const Name = "twitch"
+
+// initialCheckDelay gives the gateway time to connect before the first stream check
+const initialCheckDelay = 10 * time.Second
+
+// StreamSource reports which of the given Twitch users are live. *twitch.Client implements it.
+type StreamSource interface {
+	GetMultipleStreams(ctx context.Context, usernames []string) ([]twitch.StreamData, error)
+}
+
+// Module implements bot.Module for Twitch live notifications
+type Module struct {
+	bot    *bot.Bot
+	log    bot.Logger
+	source StreamSource
+
+	manualCheck atomic.Bool // Set while a !checkstreams check runs
+}
+
+// New creates the Twitch module. With a nil source it connects to the Twitch API
+// using the configured client credentials.
+func New(source StreamSource) *Module {
+	return &Module{source: source}
+}
+
+// Name implements bot.Module
+func (m *Module) Name() string {
+	return Name
+}
+
+// Init implements bot.Module
+func (m *Module) Init(b *bot.Bot) error {
+	m.bot = b
+	m.log = b.Logger().With(logging.KeyComponent, Name)
+
+	if m.source == nil {
+		cfg := b.Config().Twitch
+		client, err := twitch.NewClient(twitch.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Logger:       b.Logger().With(logging.KeyComponent, "twitch-api"),
+			OnResponse:   b.Metrics().TwitchResponse,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize Twitch client: %w", err)
+		}
+		m.source = client
+	}
+	return nil
+}
+
+// Handlers implements bot.Module
+func (m *Module) Handlers() []interface{} {
+	return nil
+}
+
+// Jobs implements bot.Module
+func (m *Module) Jobs() []bot.Job {
+	interval := m.bot.Config().PollInterval.Duration
+	return []bot.Job{{
+		Name:         "stream monitoring",
+		Schedule:     scheduler.Every(interval),
+		InitialDelay: initialCheckDelay,
+		Jitter:       interval / 10, // Keeps the checks of many bots from hitting Twitch at once
+		RunAtStart:   true,
+		Run:          m.CheckStreamStatus,
+	}}
+}
+
+// LinkTwitchAccount links a Discord user's Twitch account (for Goop Creators)
+func (m *Module) LinkTwitchAccount(ctx context.Context, discordID, username, guildID, twitchUsername string) error {
+	return m.bot.Store().Creators.Link(ctx, models.GoopCreator{
+		DiscordID:      discordID,
+		Username:       username,
+		GuildID:        guildID,
+		TwitchUsername: twitchUsername,
+	})
+}
+
+// UnlinkTwitchAccount removes a Discord user's Twitch link
+func (m *Module) UnlinkTwitchAccount(ctx context.Context, discordID string) error {
+	return m.bot.Store().Creators.Unlink(ctx, discordID)
+}
+
+// SetNotificationChannel sets a channel for live notifications
+func (m *Module) SetNotificationChannel(ctx context.Context, guildID, channelID string) error {
+	return m.bot.Store().NotificationChannels.Activate(ctx, guildID, channelID)
+}
+
+// GetLiveGoopCreators returns all live Goop Creators for a guild
+func (m *Module) GetLiveGoopCreators(ctx context.Context, guildID string) ([]models.TwitchStream, error) {
+	return m.bot.Store().Streams.ListLive(ctx, guildID)
+}
+
+// UpdateStreamStatus updates the live status of a streamer and sends notifications if needed
+func (m *Module) UpdateStreamStatus(ctx context.Context, twitchUsername string, isLive bool, viewerCount int, gameName, streamTitle string) error {
+	store := m.bot.Store()
+	now := time.Now()
+
+	// Check previous status from the cache first
+	previousStatus, _ := cache.GetStreamStatus(ctx, m.bot.Cache(), twitchUsername)
+	wasLiveBefore := previousStatus != nil && previousStatus.IsLive
+
+	// Get previous status from the database as backup
+	if !wasLiveBefore {
+		if previousStream, err := store.Streams.Get(ctx, twitchUsername); err == nil {
+			wasLiveBefore = previousStream.IsLive
+		}
+	}
+
+	log := m.log.With(logging.KeyTwitchUsername, twitchUsername)
+
+	// Find the Discord ID for this Twitch username
+	creator, err := store.Creators.FindByTwitchUsername(ctx, twitchUsername)
+	if err != nil {
+		// If no creator found, still update the stream but don't send notifications
+		log.Warnf("No Goop Creator found for Twitch username")
+	} else {
+		log = log.With(logging.KeyGuildID, creator.GuildID, logging.KeyUserID, creator.DiscordID)
+	}
+
+	// Update or create the stream status
+	stream := models.TwitchStream{
+		TwitchUsername: twitchUsername,
+		IsLive:         isLive,
+		LastChecked:    &now,
+		ViewerCount:    viewerCount,
+		GameName:       gameName,
+		StreamTitle:    streamTitle,
+		DiscordID:      creator.DiscordID,
+	}
+
+	if err := store.Streams.Save(ctx, stream); err != nil {
+		return err
+	}
+
+	// If streamer just went live (wasn't live before and is live now), send notifications
+	if isLive && !wasLiveBefore && creator.DiscordID != "" {
+		log.Infof("🔴 %s just went LIVE! Sending notifications...", creator.Username)
+		guildID, discordUsername := creator.GuildID, creator.Username
+		m.bot.StartTask(func() {
+			m.SendGoingLiveNotifications(m.bot.WorkContext(), guildID, twitchUsername, discordUsername, gameName, streamTitle, viewerCount)
+		})
+	} else if isLive && wasLiveBefore {
+		log.Debugf("📺 %s is still live (updating data only)", creator.Username)
+	} else if !isLive && wasLiveBefore {
+		log.Infof("⚫ %s went offline", creator.Username)
+	}
+
+	return nil
+}
+
+// SendGoingLiveNotifications sends notifications when a Goop Creator goes live
+func (m *Module) SendGoingLiveNotifications(ctx context.Context, guildID, twitchUsername, discordUsername, gameName, streamTitle string, viewerCount int) {
+	log := m.log.With(logging.KeyGuildID, guildID, logging.KeyTwitchUsername, twitchUsername)
+
+	channels, err := NotificationChannelIDs(ctx, m.bot.Store(), guildID, func() (string, error) {
+		return m.bot.GuildConfig(guildID).NotificationChannelID, nil
+	})
+	if err != nil {
+		log.With(logging.KeyError, err).Errorf("Failed to get notification channels")
+		return
+	}
+	if len(channels) == 0 {
+		log.Warnf("No active notification channels found")
+		return
+	}
+
+	embed := LiveEmbed(twitchUsername, discordUsername, gameName, streamTitle, viewerCount)
+
+	// Send notifications to all active notification channels
+	for _, channelID := range channels {
+		_, err := m.bot.Session().ChannelMessageSendEmbed(channelID, embed)
+		m.bot.Metrics().NotificationSent(err)
+		if err != nil {
+			log.With(logging.KeyChannelID, channelID, logging.KeyError, err).Errorf("Failed to send notification")
+		}
+	}
+
+	log.Infof("Sent going live notifications for %s to %d channels", discordUsername, len(channels))
+}
+
+// NotificationChannelIDs returns where going-live notifications for a guild are posted: its
+// active notification channels, or else the default channel defaultChannel returns
+func NotificationChannelIDs(ctx context.Context, store *repository.Store, guildID string, defaultChannel func() (string, error)) ([]string, error) {
+	active, err := store.NotificationChannels.ListActive(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification channels: %w", err)
+	}
+	var channels []string
+	for _, channel := range active {
+		channels = append(channels, channel.ChannelID)
+	}
+	if len(channels) > 0 {
+		return channels, nil
+	}
+
+	channelID, err := defaultChannel()
+	if err != nil {
+		return nil, err
+	}
+	if channelID != "" {
+		channels = append(channels, channelID)
+	}
+	return channels, nil
+}
+
+// LiveEmbed builds the going-live notification for a stream
+func LiveEmbed(twitchUsername, discordUsername, gameName, streamTitle string, viewerCount int) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("🔴 %s is now LIVE!", discordUsername),
+		Description: streamTitle,
+		Color:       0x9146FF, // Twitch purple
+		URL:         fmt.Sprintf("https://twitch.tv/%s", twitchUsername),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Twitch Channel",
+				Value:  fmt.Sprintf("[%s](https://twitch.tv/%s)", twitchUsername, twitchUsername),
+				Inline: true,
+			},
+			{
+				Name:   "Game/Category",
+				Value:  gameName,
+				Inline: true,
+			},
+			{
+				Name:   "Viewers",
+				Value:  fmt.Sprintf("%d", viewerCount),
+				Inline: true,
+			},
+		},
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: fmt.Sprintf("https://static-cdn.jtvnw.net/previews-ttv/live_user_%s-320x180.jpg", strings.ToLower(twitchUsername)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "GoopBot Live Notifications",
+		},
+	}
+}
+
+// CheckStreamStatus asks the Twitch API which linked creators are live and updates their status
+func (m *Module) CheckStreamStatus(ctx context.Context) {
+	start := time.Now()
+	live, err := m.checkStreamStatus(ctx)
+	m.bot.Metrics().StreamCheckDone(start, live, err)
+	if err != nil {
+		m.log.With(logging.KeyError, err).Errorf("Stream status check failed")
+	}
+}
+
+// checkStreamStatus does the work of CheckStreamStatus and returns how many creators are live
+func (m *Module) checkStreamStatus(ctx context.Context) (int, error) {
+	// Get all active Goop Creators
+	creators, err := m.bot.Store().Creators.ListActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active creators: %w", err)
+	}
+
+	// Skip creators in guilds that have the Twitch module disabled or belong to
+	// shards another process runs
+	enabled := make(map[string]bool)
+	active := creators[:0]
+	for _, creator := range creators {
+		on, seen := enabled[creator.GuildID]
+		if !seen {
+			on = m.bot.HandlesGuild(creator.GuildID) && m.bot.GuildConfig(creator.GuildID).ModuleEnabled(Name)
+			enabled[creator.GuildID] = on
+		}
+		if on {
+			active = append(active, creator)
+		}
+	}
+	creators = active
+
+	if len(creators) == 0 {
+		m.log.Debugf("No active Goop Creators to check")
+		return 0, nil
+	}
+
+	// Extract usernames for batch API call
+	usernames := make([]string, len(creators))
+	for i, creator := range creators {
+		usernames[i] = creator.TwitchUsername
+	}
+
+	m.log.Debugf("Checking stream status for %d creators: %v", len(usernames), usernames)
+
+	// Get stream data from Twitch API
+	streams, err := m.source.GetMultipleStreams(ctx, usernames)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stream data from Twitch API: %w", err)
+	}
+
+	// Create a map of live streams for quick lookup
+	liveStreams := make(map[string]*twitch.StreamData)
+	for i := range streams {
+		liveStreams[strings.ToLower(streams[i].UserLogin)] = &streams[i]
+	}
+
+	// Process each creator
+	for _, creator := range creators {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("stream status check interrupted: %w", ctx.Err())
+		}
+
+		username := strings.ToLower(creator.TwitchUsername)
+		log := m.log.With(logging.KeyTwitchUsername, creator.TwitchUsername, logging.KeyGuildID, creator.GuildID)
+
+		streamData, isLive := liveStreams[username]
+		var err error
+		if isLive {
+			// Creator is live
+			log.Debugf("Stream is live: %s", streamData.Title)
+			err = m.UpdateStreamStatus(ctx, creator.TwitchUsername, true, streamData.ViewerCount, streamData.GameName, streamData.Title)
+		} else {
+			// Creator is offline
+			log.Debugf("Stream is offline")
+			err = m.UpdateStreamStatus(ctx, creator.TwitchUsername, false, 0, "", "")
+		}
+		if err != nil {
+			log.With(logging.KeyError, err).Errorf("Failed to update stream status")
+		}
+
+		// Cache to avoid duplicate notifications
+		if err := cache.SetStreamStatus(ctx, m.bot.Cache(), creator.TwitchUsername, isLive); err != nil {
+			log.With(logging.KeyError, err).Debugf("Failed to cache stream status")
+		}
+	}
+
+	m.log.Infof("Stream status check completed. Found %d live streams out of %d creators", len(streams), len(creators))
+	return len(streams), nil
+}
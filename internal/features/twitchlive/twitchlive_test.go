@@ -0,0 +1,228 @@
+package twitchlive_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/discordtest"
+	"GoopBot/internal/features/twitchlive"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"GoopBot/internal/twitch"
+	"GoopBot/storage/cache"
+)
+
+// fakeSource is a stream source whose live streams are set by the test
+type fakeSource struct {
+	mu      sync.Mutex
+	streams []twitch.StreamData
+}
+
+func (f *fakeSource) GetMultipleStreams(context.Context, []string) ([]twitch.StreamData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]twitch.StreamData(nil), f.streams...), nil
+}
+
+func (f *fakeSource) setLive(streams ...twitch.StreamData) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams = streams
+}
+
+// downCache is a cache whose backend is unreachable, like Redis during an outage
+type downCache struct{}
+
+func (downCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, cache.ErrUnavailable
+}
+func (downCache) Set(context.Context, string, []byte, time.Duration) error {
+	return cache.ErrUnavailable
+}
+func (downCache) Delete(context.Context, ...string) error      { return cache.ErrUnavailable }
+func (downCache) DeleteMatching(context.Context, string) error { return cache.ErrUnavailable }
+func (downCache) Ping(context.Context) error                   { return cache.ErrUnavailable }
+func (downCache) Close() error                                 { return nil }
+
+// newTestBot builds a bot running only the Twitch module on an in-memory store
+func newTestBot(t *testing.T) (*bot.Bot, *discordtest.Session, *twitchlive.Module, *fakeSource) {
+	t.Helper()
+	source := &fakeSource{}
+	m := twitchlive.New(source)
+	b, fake := bottest.NewWithStore(t, repository.NewMemory(), m)
+	return b, fake, m, source
+}
+
+func TestLinkTwitchRequiresCreatorRole(t *testing.T) {
+	b, fake, _, _ := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!linktwitch someone"), "You need the 'Goop Creator' role")
+
+	if creators, _ := b.Store().Creators.ListActive(context.Background()); len(creators) != 0 {
+		t.Fatalf("expected no linked creators, got %+v", creators)
+	}
+}
+
+func TestLinkAndUnlinkTwitch(t *testing.T) {
+	b, fake, _, _ := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.CreatorID, "!linktwitch"), "Usage: !linktwitch <username>")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.CreatorID, "!linktwitch goopstreamer"), "Successfully linked your Twitch account: goopstreamer")
+
+	creator, err := b.Store().Creators.FindByTwitchUsername(context.Background(), "goopstreamer")
+	if err != nil {
+		t.Fatalf("creator was not stored: %v", err)
+	}
+	if creator.DiscordID != bottest.CreatorID || creator.GuildID != bottest.GuildID || !creator.IsActive {
+		t.Fatalf("unexpected creator record: %+v", creator)
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.CreatorID, "!linktwitch goopstreamer2"), "Successfully linked your Twitch account: goopstreamer2")
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.CreatorID, "!unlinktwitch"), "Successfully unlinked")
+	if creators, _ := b.Store().Creators.ListActive(context.Background()); len(creators) != 0 {
+		t.Fatalf("creator still linked after !unlinktwitch: %+v", creators)
+	}
+
+	// Linking again after unlinking restores the record
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.CreatorID, "!linktwitch goopstreamer"), "Successfully linked your Twitch account: goopstreamer")
+}
+
+func TestSetNotificationsRequiresAdmin(t *testing.T) {
+	b, fake, _, _ := newTestBot(t)
+
+	mention := "<#" + bottest.LiveChannelID + ">"
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!setnotifications "+mention), "Administrator permissions or server ownership")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setnotifications general"), "must be a channel mention")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setnotifications "+mention), "Successfully set "+mention)
+	// The server owner does not need the Administrator permission
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.OwnerID, "!setnotifications "+mention), "Successfully set "+mention)
+
+	channels, _ := b.Store().NotificationChannels.ListActive(context.Background(), bottest.GuildID)
+	if len(channels) != 1 || channels[0].ChannelID != bottest.LiveChannelID {
+		t.Fatalf("unexpected notification channels: %+v", channels)
+	}
+}
+
+func TestGoingLiveNotificationEmbed(t *testing.T) {
+	_, fake, m, _ := newTestBot(t)
+
+	if err := m.SetNotificationChannel(context.Background(), bottest.GuildID, bottest.LiveChannelID); err != nil {
+		t.Fatalf("set notification channel: %v", err)
+	}
+	m.SendGoingLiveNotifications(context.Background(), bottest.GuildID, "GoopStreamer", "creator", "Just Chatting", "Hello chat", 42)
+
+	sent := fake.SentTo(bottest.LiveChannelID)
+	if len(sent) != 1 || sent[0].Embed == nil {
+		t.Fatalf("expected one embed, got %+v", sent)
+	}
+	embed := sent[0].Embed
+	if embed.URL != "https://twitch.tv/GoopStreamer" || embed.Description != "Hello chat" {
+		t.Fatalf("unexpected embed: %+v", embed)
+	}
+	if !strings.Contains(embed.Thumbnail.URL, "live_user_goopstreamer") {
+		t.Fatalf("thumbnail should use the lowercase login: %s", embed.Thumbnail.URL)
+	}
+}
+
+func TestDefaultNotificationChannelFallback(t *testing.T) {
+	b, fake, m, _ := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!setdefaultchannel <#"+bottest.LiveChannelID+">"), "default notification channel")
+
+	fake.Reset()
+	m.SendGoingLiveNotifications(context.Background(), bottest.GuildID, "goopy", "creator", "Just Chatting", "hello", 5)
+	if sent := fake.SentTo(bottest.LiveChannelID); len(sent) != 1 || sent[0].Embed == nil {
+		t.Fatalf("expected a going-live embed in the default channel, got %+v", fake.Sent())
+	}
+}
+
+func TestGoopLiveListsLiveCreators(t *testing.T) {
+	b, fake, m, _ := newTestBot(t)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!gooplive"), "No Goop Creators are currently live")
+
+	if err := m.LinkTwitchAccount(context.Background(), bottest.CreatorID, "creator", bottest.GuildID, "goopstreamer"); err != nil {
+		t.Fatalf("link account: %v", err)
+	}
+	b.Store().Streams.Save(context.Background(), models.TwitchStream{TwitchUsername: "goopstreamer", IsLive: true, GameName: "Minecraft", StreamTitle: "Building", ViewerCount: 7, DiscordID: bottest.CreatorID})
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!live"), "**goopstreamer** - Building")
+}
+
+func TestCheckStreamStatusSkipsDisabledGuilds(t *testing.T) {
+	b, fake, m, source := newTestBot(t)
+
+	if err := m.LinkTwitchAccount(context.Background(), bottest.CreatorID, "creator", bottest.GuildID, "goopstreamer"); err != nil {
+		t.Fatalf("link account: %v", err)
+	}
+	source.setLive(twitch.StreamData{UserLogin: "goopstreamer", Title: "Building", GameName: "Minecraft", ViewerCount: 3})
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!disablemodule twitch"), "Disabled the twitch module")
+
+	m.CheckStreamStatus(context.Background())
+	if _, err := b.Store().Streams.Get(context.Background(), "goopstreamer"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("stream status was recorded for a guild with the module disabled")
+	}
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!enablemodule twitch"), "Enabled the twitch module")
+	m.CheckStreamStatus(context.Background())
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.StrangerID, "!gooplive"), "**goopstreamer** - Building")
+}
+
+func TestGoingLiveNotifiesOnce(t *testing.T) {
+	b, fake, m, source := newTestBot(t)
+	ctx := context.Background()
+
+	if err := m.LinkTwitchAccount(ctx, bottest.CreatorID, "creator", bottest.GuildID, "goopstreamer"); err != nil {
+		t.Fatalf("link account: %v", err)
+	}
+	if err := m.SetNotificationChannel(ctx, bottest.GuildID, bottest.LiveChannelID); err != nil {
+		t.Fatalf("set notification channel: %v", err)
+	}
+
+	// Offline, live on two checks in a row, offline, then live again
+	live := twitch.StreamData{UserLogin: "goopstreamer", Title: "Building", GameName: "Minecraft", ViewerCount: 3}
+	for _, streams := range [][]twitch.StreamData{nil, {live}, {live}, nil, {live}} {
+		source.setLive(streams...)
+		m.CheckStreamStatus(ctx)
+	}
+	b.Close(ctx) // Waits for the notifications to be sent
+
+	if sent := fake.SentTo(bottest.LiveChannelID); len(sent) != 2 {
+		t.Fatalf("expected a notification each time the stream went live, got %d", len(sent))
+	}
+	stream, err := b.Store().Streams.Get(ctx, "goopstreamer")
+	if err != nil || !stream.IsLive || stream.DiscordID != bottest.CreatorID {
+		t.Fatalf("unexpected stream status %+v (err %v)", stream, err)
+	}
+}
+
+func TestGoingLiveNotifiesOnceWithCacheDown(t *testing.T) {
+	source := &fakeSource{}
+	m := twitchlive.New(source)
+	b, fake := bottest.NewWithDeps(t, bot.Deps{Store: repository.NewMemory(), Cache: downCache{}}, m)
+	ctx := context.Background()
+
+	if err := m.LinkTwitchAccount(ctx, bottest.CreatorID, "creator", bottest.GuildID, "goopstreamer"); err != nil {
+		t.Fatalf("link account: %v", err)
+	}
+	if err := m.SetNotificationChannel(ctx, bottest.GuildID, bottest.LiveChannelID); err != nil {
+		t.Fatalf("set notification channel: %v", err)
+	}
+
+	// Without the cache the previous status comes from the database
+	source.setLive(twitch.StreamData{UserLogin: "goopstreamer", Title: "Building", GameName: "Minecraft", ViewerCount: 3})
+	m.CheckStreamStatus(ctx)
+	m.CheckStreamStatus(ctx)
+	b.Close(ctx)
+
+	if sent := fake.SentTo(bottest.LiveChannelID); len(sent) != 1 {
+		t.Fatalf("expected one notification, got %d", len(sent))
+	}
+}
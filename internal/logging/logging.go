@@ -0,0 +1,133 @@
+// Package logging provides the leveled, structured logger used throughout GoopBot.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Logger writes leveled log messages. Key/value fields attached with With are
+// included on every message, e.g. logger.With("guild_id", id, "command", name).
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+
+	// With returns a logger that adds the given key/value pairs to every message
+	With(keyvals ...interface{}) Logger
+	// SetLevel changes the minimum level for this logger and every logger derived from it
+	SetLevel(level string) error
+}
+
+// Common field keys, so the same value is always logged under the same name
+const (
+	KeyGuildID        = "guild_id"
+	KeyChannelID      = "channel_id"
+	KeyUserID         = "user_id"
+	KeyTwitchUsername = "twitch_username"
+	KeyCommand        = "command"
+	KeyComponent      = "component"
+	KeyShard          = "shard"
+	KeyError          = "error"
+)
+
+// ParseLevel converts "debug", "info", "warn" or "error" into a slog level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// New creates a logger writing to w. format is "text" or "json".
+func New(w io.Writer, level, format string) (Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(lvl)
+	opts := &slog.HandlerOptions{Level: levelVar}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (use text or json)", format)
+	}
+
+	return &slogLogger{logger: slog.New(handler), level: levelVar}, nil
+}
+
+// Nop returns a logger that discards everything, for tests and optional dependencies
+func Nop() Logger {
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(slog.LevelError + 1)
+	return &slogLogger{logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: levelVar})), level: levelVar}
+}
+
+// OrNop returns l, or a no-op logger when l is nil
+func OrNop(l Logger) Logger {
+	if l == nil {
+		return Nop()
+	}
+	return l
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+	level  *slog.LevelVar // Shared with loggers derived through With
+}
+
+func (l *slogLogger) logf(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.logf(slog.LevelDebug, format, args)
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.logf(slog.LevelInfo, format, args)
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.logf(slog.LevelWarn, format, args)
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.logf(slog.LevelError, format, args)
+}
+
+func (l *slogLogger) With(keyvals ...interface{}) Logger {
+	return &slogLogger{logger: l.logger.With(keyvals...), level: l.level}
+}
+
+func (l *slogLogger) SetLevel(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.Set(lvl)
+	return nil
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debugf("debug message")
+	logger.Infof("info message")
+	logger.Warnf("warn message")
+	logger.Errorf("error message")
+
+	out := buf.String()
+	for _, dropped := range []string{"debug message", "info message"} {
+		if strings.Contains(out, dropped) {
+			t.Errorf("output contains %q below the configured level:\n%s", dropped, out)
+		}
+	}
+	for _, kept := range []string{"warn message", "error message"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("output is missing %q:\n%s", kept, out)
+		}
+	}
+}
+
+func TestSetLevelAppliesToDerivedLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := logger.With(KeyGuildID, "100")
+
+	child.Debugf("hidden")
+	if err := logger.SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	child.Debugf("visible")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, "visible") {
+		t.Errorf("SetLevel did not reach the derived logger:\n%s", out)
+	}
+	if err := logger.SetLevel("loud"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "debug", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.With(KeyGuildID, "100", KeyUserID, "303").
+		With(KeyTwitchUsername, "goopy", KeyCommand, "linktwitch").
+		Infof("linked %s", "goopy")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]string{
+		"level":           "INFO",
+		"msg":             "linked goopy",
+		KeyGuildID:        "100",
+		KeyUserID:         "303",
+		KeyTwitchUsername: "goopy",
+		KeyCommand:        "linktwitch",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %q", key, entry[key], value)
+		}
+	}
+}
+
+func TestNewRejectsUnknownOptions(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "verbose", "text"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,174 @@
+// Package metrics defines the Prometheus metrics the bot and its modules record.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Result label values
+const (
+	ResultOK      = "ok"
+	ResultError   = "error"
+	ResultDenied  = "denied"  // The user lacked the permission or role the command needs
+	ResultLimited = "limited" // The user or server used the command too often
+)
+
+// Metrics holds every metric. Record through its methods so label values stay consistent.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	commands            *prometheus.CounterVec
+	streamCheckDuration prometheus.Histogram
+	streamChecks        *prometheus.CounterVec
+	streamCheckSuccess  prometheus.Gauge
+	liveCreators        prometheus.Gauge
+	notifications       *prometheus.CounterVec
+	birthdayMessages    *prometheus.CounterVec
+	twitchRequests      *prometheus.CounterVec
+	discordRequests     *prometheus.CounterVec
+	discordCache        *prometheus.CounterVec
+	backups             *prometheus.CounterVec
+	backupSuccess       prometheus.Gauge
+	backupSize          prometheus.Gauge
+}
+
+// New creates the metrics on a registry of their own, together with the Go runtime and
+// process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_commands_total",
+			Help: "Commands handled, by command and result (ok, error, denied or limited).",
+		}, []string{"command", "result"}),
+		streamCheckDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "goopbot_stream_check_duration_seconds",
+			Help:    "How long a Twitch stream status check took.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		}),
+		streamChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_stream_checks_total",
+			Help: "Twitch stream status checks, by result.",
+		}, []string{"result"}),
+		streamCheckSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "goopbot_stream_check_last_success_timestamp_seconds",
+			Help: "When a stream status check last succeeded, as a Unix timestamp.",
+		}),
+		liveCreators: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "goopbot_live_creators",
+			Help: "Linked creators that were live at the last stream check.",
+		}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_live_notifications_total",
+			Help: "Going-live notifications sent to channels, by result (ok or error).",
+		}, []string{"result"}),
+		birthdayMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_birthday_messages_total",
+			Help: "Birthday messages sent, by result (ok or error).",
+		}, []string{"result"}),
+		twitchRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_twitch_api_requests_total",
+			Help: "Twitch API requests, by endpoint and HTTP status code (0 when no response arrived).",
+		}, []string{"endpoint", "code"}),
+		discordRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_discord_api_requests_total",
+			Help: "Discord API calls, by method and result (ok or error).",
+		}, []string{"method", "result"}),
+		discordCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_discord_cache_lookups_total",
+			Help: "Lookups of cached Discord guilds, channels, roles and members, by kind and result (hit or miss).",
+		}, []string{"kind", "result"}),
+		backups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goopbot_backups_total",
+			Help: "Database backups taken, by result (ok or error).",
+		}, []string{"result"}),
+		backupSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "goopbot_backup_last_success_timestamp_seconds",
+			Help: "When a database backup last succeeded, as a Unix timestamp.",
+		}),
+		backupSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "goopbot_backup_size_bytes",
+			Help: "Size of the latest database backup.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.commands, m.streamCheckDuration, m.streamChecks, m.streamCheckSuccess, m.liveCreators,
+		m.notifications, m.birthdayMessages, m.twitchRequests, m.discordRequests, m.discordCache,
+		m.backups, m.backupSuccess, m.backupSize,
+	)
+	return m
+}
+
+// Registry returns the registry to serve, and to register further collectors on
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// result maps an error to a result label
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
+
+// CommandHandled records a command and its result, one of the Result constants
+func (m *Metrics) CommandHandled(command, result string) {
+	m.commands.WithLabelValues(command, result).Inc()
+}
+
+// StreamCheckDone records a stream status check that started at start, and how many of
+// the checked creators were live if it succeeded
+func (m *Metrics) StreamCheckDone(start time.Time, live int, err error) {
+	m.streamCheckDuration.Observe(time.Since(start).Seconds())
+	m.streamChecks.WithLabelValues(result(err)).Inc()
+	if err == nil {
+		m.streamCheckSuccess.SetToCurrentTime()
+		m.liveCreators.Set(float64(live))
+	}
+}
+
+// NotificationSent records a going-live notification sent to one channel
+func (m *Metrics) NotificationSent(err error) {
+	m.notifications.WithLabelValues(result(err)).Inc()
+}
+
+// BirthdayMessageSent records a birthday message
+func (m *Metrics) BirthdayMessageSent(err error) {
+	m.birthdayMessages.WithLabelValues(result(err)).Inc()
+}
+
+// TwitchResponse records a Twitch API response; code is 0 when the request failed without one
+func (m *Metrics) TwitchResponse(endpoint string, code int) {
+	m.twitchRequests.WithLabelValues(endpoint, strconv.Itoa(code)).Inc()
+}
+
+// DiscordCall records a Discord API call
+func (m *Metrics) DiscordCall(method string, err error) {
+	m.discordRequests.WithLabelValues(method, result(err)).Inc()
+}
+
+// DiscordCacheLookup records a lookup of a cached Discord object of the given kind
+func (m *Metrics) DiscordCacheLookup(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.discordCache.WithLabelValues(kind, result).Inc()
+}
+
+// BackupDone records a database backup and, if it succeeded, its size
+func (m *Metrics) BackupDone(size int64, err error) {
+	m.backups.WithLabelValues(result(err)).Inc()
+	if err == nil {
+		m.backupSuccess.SetToCurrentTime()
+		m.backupSize.Set(float64(size))
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStreamCheckDone(t *testing.T) {
+	m := New()
+
+	m.StreamCheckDone(time.Now(), 3, nil)
+	if got := testutil.ToFloat64(m.liveCreators); got != 3 {
+		t.Errorf("live creators: got %v, want 3", got)
+	}
+	success := testutil.ToFloat64(m.streamCheckSuccess)
+	if success == 0 {
+		t.Fatalf("last success time was not set")
+	}
+
+	// A failed check counts as one but keeps the last success
+	m.StreamCheckDone(time.Now(), 0, errors.New("twitch is down"))
+	if got := testutil.ToFloat64(m.streamChecks.WithLabelValues(ResultError)); got != 1 {
+		t.Errorf("failed checks: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.liveCreators); got != 3 {
+		t.Errorf("a failed check changed the live creator count to %v", got)
+	}
+	if got := testutil.ToFloat64(m.streamCheckSuccess); got != success {
+		t.Errorf("a failed check moved the last success time")
+	}
+	if got := testutil.CollectAndCount(m.streamCheckDuration); got != 1 {
+		t.Errorf("expected one duration histogram, got %d", got)
+	}
+}
+
+func TestTwitchAndNotificationCounters(t *testing.T) {
+	m := New()
+
+	m.TwitchResponse("streams", 200)
+	m.TwitchResponse("streams", 200)
+	m.TwitchResponse("token", 0)
+	m.NotificationSent(nil)
+	m.NotificationSent(errors.New("missing access"))
+	m.BirthdayMessageSent(nil)
+
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"streams 200", testutil.ToFloat64(m.twitchRequests.WithLabelValues("streams", "200")), 2},
+		{"token without response", testutil.ToFloat64(m.twitchRequests.WithLabelValues("token", "0")), 1},
+		{"notifications sent", testutil.ToFloat64(m.notifications.WithLabelValues(ResultOK)), 1},
+		{"notifications failed", testutil.ToFloat64(m.notifications.WithLabelValues(ResultError)), 1},
+		{"birthday messages", testutil.ToFloat64(m.birthdayMessages.WithLabelValues(ResultOK)), 1},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestBackupDone(t *testing.T) {
+	m := New()
+
+	m.BackupDone(4096, nil)
+	m.BackupDone(0, errors.New("disk full"))
+	if got := testutil.ToFloat64(m.backupSize); got != 4096 {
+		t.Errorf("backup size: got %v, want 4096", got)
+	}
+	if testutil.ToFloat64(m.backupSuccess) == 0 {
+		t.Errorf("last success time was not set")
+	}
+	if got := testutil.ToFloat64(m.backups.WithLabelValues(ResultError)); got != 1 {
+		t.Errorf("failed backups: got %v, want 1", got)
+	}
+}
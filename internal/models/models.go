@@ -0,0 +1,121 @@
+// Package models defines the database records shared by the bot core and its feature modules.
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GoopCreator represents a Discord user with the "Goop Creator" role (streamers)
+type GoopCreator struct {
+	gorm.Model
+	DiscordID      string `gorm:"uniqueIndex" json:"discord_id"`
+	Username       string `json:"username"`
+	GuildID        string `json:"guild_id"`
+	TwitchUsername string `json:"twitch_username"` // Their Twitch username
+	IsActive       bool   `json:"is_active"`       // Whether notifications are enabled
+}
+
+// TwitchStream represents a Twitch stream status
+type TwitchStream struct {
+	gorm.Model
+	TwitchUsername string     `gorm:"uniqueIndex" json:"twitch_username"`
+	IsLive         bool       `json:"is_live"`
+	LastChecked    *time.Time `json:"last_checked"`
+	ViewerCount    int        `json:"viewer_count"`
+	GameName       string     `json:"game_name"`
+	StreamTitle    string     `json:"stream_title"`
+	DiscordID      string     `json:"discord_id"` // Associated Discord user ID
+}
+
+// NotificationChannel represents channels where live notifications should be sent
+type NotificationChannel struct {
+	gorm.Model
+	GuildID   string `json:"guild_id"`
+	ChannelID string `gorm:"uniqueIndex" json:"channel_id"`
+	IsActive  bool   `json:"is_active"`
+}
+
+// Birthday represents a user's birthday
+type Birthday struct {
+	gorm.Model
+	DiscordID string    `gorm:"uniqueIndex" json:"discord_id"`
+	Username  string    `json:"username"`
+	GuildID   string    `json:"guild_id"`
+	Month     int       `json:"month"`     // 1-12
+	Day       int       `json:"day"`       // 1-31
+	Year      *int      `json:"year"`      // Optional birth year
+	LastSent  time.Time `json:"last_sent"` // When we last sent birthday message
+}
+
+// BirthdayChannel represents channels where birthday notifications should be sent
+type BirthdayChannel struct {
+	gorm.Model
+	GuildID   string `json:"guild_id"`
+	ChannelID string `gorm:"uniqueIndex" json:"channel_id"`
+	IsActive  bool   `json:"is_active"`
+}
+
+// RoleMessage represents messages that grant roles when reacted to
+type RoleMessage struct {
+	gorm.Model
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+	MessageID string `gorm:"uniqueIndex" json:"message_id"`
+	RoleName  string `json:"role_name"` // The role to grant (e.g., "member")
+	IsActive  bool   `json:"is_active"`
+}
+
+// GuildSettings holds per-guild overrides. Empty fields fall back to the bot configuration.
+type GuildSettings struct {
+	gorm.Model
+	GuildID               string  `gorm:"uniqueIndex" json:"guild_id"`
+	CreatorRole           string  `json:"creator_role"`
+	MemberRole            string  `json:"member_role"`
+	CommandPrefix         string  `json:"command_prefix"`
+	EnabledModules        *string `json:"enabled_modules"`         // Comma-separated; nil enables every module
+	NotificationChannelID string  `json:"notification_channel_id"` // Used when a feature has no channel of its own
+	ModLogChannelID       string  `json:"mod_log_channel_id"`      // Where audit entries are mirrored; empty for none
+}
+
+// DashboardToken is a login for a guild's web dashboard. Only a hash of the token is stored.
+type DashboardToken struct {
+	gorm.Model
+	GuildID   string    `gorm:"index" json:"guild_id"`
+	TokenHash string    `gorm:"uniqueIndex" json:"-"`
+	IssuedBy  string    `json:"issued_by"` // Discord ID of the admin who asked for it
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AuditEntry records an admin-level change to a guild's bot setup. CreatedAt is when it was made.
+type AuditEntry struct {
+	gorm.Model
+	GuildID   string `gorm:"index" json:"guild_id"`
+	ActorID   string `json:"actor_id"` // Discord ID of who made the change; empty for the admin API
+	ActorName string `json:"actor_name"`
+	Action    string `json:"action"` // e.g. "set prefix"
+	Before    string `json:"before"`
+	After     string `json:"after"`
+	Via       string `json:"via"` // "command", "dashboard" or "api"
+}
+
+// PermissionRule grants or denies a command, or a group of commands, to a role or a member
+// of a guild, overriding the command's default permission
+type PermissionRule struct {
+	gorm.Model
+	GuildID     string `gorm:"uniqueIndex:idx_permission_rule" json:"guild_id"`
+	Target      string `gorm:"uniqueIndex:idx_permission_rule" json:"target"` // Command name, "module:<name>" or "all"
+	SubjectType string `json:"subject_type"`                                  // "role" or "user"
+	SubjectID   string `gorm:"uniqueIndex:idx_permission_rule" json:"subject_id"`
+	Allow       bool   `json:"allow"`
+}
+
+// All returns an empty instance of every model, for tools that work on every table
+func All() []interface{} {
+	return []interface{}{
+		&GoopCreator{}, &TwitchStream{}, &NotificationChannel{}, &Birthday{},
+		&BirthdayChannel{}, &RoleMessage{}, &GuildSettings{}, &DashboardToken{}, &AuditEntry{},
+		&PermissionRule{},
+	}
+}
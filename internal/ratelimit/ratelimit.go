@@ -0,0 +1,189 @@
+// Package ratelimit limits how often something may happen, with sliding windows kept in
+// Redis, so every instance shares them, or in process memory. Fallback uses Redis and
+// switches to memory while Redis is down.
+package ratelimit
+
+import (
+	"GoopBot/internal/logging"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit allows Count uses of a key in any window of length Per
+type Limit struct {
+	Count int
+	Per   time.Duration
+}
+
+// Result is the outcome of an Allow call
+type Result struct {
+	Allowed    bool
+	Remaining  int           // Uses left in the current window
+	RetryAfter time.Duration // When a denied use would be allowed
+}
+
+// Limiter counts the uses of keys in sliding windows
+type Limiter interface {
+	// Allow records a use of key and reports whether it is within limit. Denied uses
+	// aren't recorded, so retrying too early doesn't push the window back.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+	// Forget drops the windows of every key that has subject, such as a user ID, as one of
+	// its colon-separated parts, e.g. gooplive:user:303 for 303
+	Forget(ctx context.Context, subject string) error
+}
+
+// namesSubject reports whether subject is one of the colon-separated parts of key
+func namesSubject(key, subject string) bool {
+	for _, part := range strings.Split(key, ":") {
+		if part == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// sweepEvery is how many calls the in-memory limiter takes between sweeps for idle keys
+const sweepEvery = 1000
+
+// Memory is a Limiter in process memory, for single-instance deployments, tests and as
+// the fallback while Redis is down
+type Memory struct {
+	mu      sync.Mutex
+	windows map[string]memoryWindow
+	calls   int
+	now     func() time.Time
+}
+
+// memoryWindow holds the times of a key's uses in its current window, oldest first
+type memoryWindow struct {
+	uses []time.Time
+	per  time.Duration
+}
+
+// NewMemory returns an in-memory limiter
+func NewMemory() *Memory {
+	return &Memory{windows: make(map[string]memoryWindow), now: time.Now}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	uses := inWindow(m.windows[key].uses, now, limit.Per)
+	var result Result
+	if len(uses) < limit.Count {
+		uses = append(uses, now)
+		result = Result{Allowed: true, Remaining: limit.Count - len(uses)}
+	} else if len(uses) > 0 {
+		result = Result{RetryAfter: uses[len(uses)-limit.Count].Add(limit.Per).Sub(now)}
+	}
+	if len(uses) == 0 {
+		delete(m.windows, key)
+	} else {
+		m.windows[key] = memoryWindow{uses: uses, per: limit.Per}
+	}
+
+	// Keys that are never used again would otherwise stay forever
+	m.calls++
+	if m.calls%sweepEvery == 0 {
+		for k, window := range m.windows {
+			if len(inWindow(window.uses, now, window.per)) == 0 {
+				delete(m.windows, k)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *Memory) Forget(_ context.Context, subject string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.windows {
+		if namesSubject(key, subject) {
+			delete(m.windows, key)
+		}
+	}
+	return nil
+}
+
+// inWindow drops the uses that are a window or more older than now
+func inWindow(uses []time.Time, now time.Time, per time.Duration) []time.Time {
+	for len(uses) > 0 && !uses[0].After(now.Add(-per)) {
+		uses = uses[1:]
+	}
+	return uses
+}
+
+// retryInterval is how long Fallback keeps using memory after Redis fails before it tries
+// Redis again
+const retryInterval = 10 * time.Second
+
+// Fallback is a Limiter that uses primary, usually Redis, and switches to secondary,
+// usually Memory, while primary fails. Limits are per instance until primary is back.
+type Fallback struct {
+	primary, secondary Limiter
+	log                logging.Logger
+	now                func() time.Time
+
+	mu        sync.Mutex
+	downSince time.Time // Zero while primary works
+	lastTry   time.Time
+}
+
+// NewFallback returns a limiter that uses secondary while primary fails
+func NewFallback(primary, secondary Limiter, logger logging.Logger) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary, log: logging.OrNop(logger), now: time.Now}
+}
+
+// Degraded reports whether the primary limiter failed on the last attempt
+func (f *Fallback) Degraded() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.downSince.IsZero()
+}
+
+func (f *Fallback) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	f.mu.Lock()
+	skip := !f.downSince.IsZero() && f.now().Sub(f.lastTry) < retryInterval
+	if !skip {
+		f.lastTry = f.now()
+	}
+	f.mu.Unlock()
+	if skip {
+		return f.secondary.Allow(ctx, key, limit)
+	}
+
+	result, err := f.primary.Allow(ctx, key, limit)
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up; that says nothing about the primary limiter
+		return result, err
+	}
+
+	f.mu.Lock()
+	if err != nil {
+		if f.downSince.IsZero() {
+			f.downSince = f.now()
+			f.log.With(logging.KeyError, err).Warnf("Rate limiter unavailable, limiting in memory until it is back")
+		}
+		f.mu.Unlock()
+		return f.secondary.Allow(ctx, key, limit)
+	}
+	if !f.downSince.IsZero() {
+		f.log.Infof("Rate limiter is reachable again after %s", f.now().Sub(f.downSince).Round(time.Second))
+		f.downSince = time.Time{}
+	}
+	f.mu.Unlock()
+	return result, nil
+}
+
+// Forget forgets subject in both limiters, since uses may have been recorded in either
+func (f *Fallback) Forget(ctx context.Context, subject string) error {
+	secondaryErr := f.secondary.Forget(ctx, subject)
+	if err := f.primary.Forget(ctx, subject); err != nil {
+		return err
+	}
+	return secondaryErr
+}
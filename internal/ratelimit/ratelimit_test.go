@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// expectWindow checks that a limiter allows three uses per minute as a sliding window
+func expectWindow(t *testing.T, l Limiter, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+	limit := Limit{Count: 3, Per: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		result, err := l.Allow(ctx, "gooplive:user:303", limit)
+		if err != nil || !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("use %d: got %+v (err %v)", i, result, err)
+		}
+		advance(10 * time.Second)
+	}
+
+	// The window is full until the first use is a minute old
+	result, err := l.Allow(ctx, "gooplive:user:303", limit)
+	if err != nil || result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Fatalf("use over the limit: got %+v (err %v)", result, err)
+	}
+	// Keys are limited separately
+	if result, _ := l.Allow(ctx, "gooplive:user:304", limit); !result.Allowed {
+		t.Fatalf("another key was limited: %+v", result)
+	}
+
+	// A denied use isn't recorded, so the window opens when the first use expires
+	advance(30 * time.Second)
+	if result, _ := l.Allow(ctx, "gooplive:user:303", limit); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("use after the first expired: got %+v", result)
+	}
+	if result, _ := l.Allow(ctx, "gooplive:user:303", limit); result.Allowed || result.RetryAfter != 10*time.Second {
+		t.Fatalf("use over the limit again: got %+v", result)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	expectWindow(t, m, func(d time.Duration) { now = now.Add(d) })
+}
+
+func TestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRedis(client)
+	r.now = func() time.Time { return now }
+	expectWindow(t, r, func(d time.Duration) { now = now.Add(d) })
+
+	// Two instances share the window
+	other := NewRedis(client)
+	other.now = r.now
+	if result, _ := other.Allow(context.Background(), "gooplive:user:303", Limit{Count: 3, Per: time.Minute}); result.Allowed {
+		t.Fatalf("the other instance ignored the shared window: %+v", result)
+	}
+	if ttl := server.TTL("ratelimit:gooplive:user:303"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("window key TTL %s, want at most a minute", ttl)
+	}
+}
+
+func TestForget(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	limit := Limit{Count: 1, Per: time.Minute}
+	for name, l := range map[string]Limiter{"memory": NewMemory(), "redis": NewRedis(client)} {
+		for _, key := range []string{"gooplive:user:303", "checkstreams:guild:100:notice:303", "gooplive:user:3030"} {
+			l.Allow(ctx, key, limit)
+		}
+		if err := l.Forget(ctx, "303"); err != nil {
+			t.Fatalf("%s: Forget: %v", name, err)
+		}
+		for key, want := range map[string]bool{"gooplive:user:303": true, "checkstreams:guild:100:notice:303": true, "gooplive:user:3030": false} {
+			if result, _ := l.Allow(ctx, key, limit); result.Allowed != want {
+				t.Errorf("%s: %s allowed %v after forgetting 303, want %v", name, key, result.Allowed, want)
+			}
+		}
+	}
+}
+
+func TestFallbackUsesMemoryWhileRedisIsDown(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFallback(NewRedis(client), NewMemory(), nil)
+	f.now = func() time.Time { return now }
+	limit := Limit{Count: 1, Per: time.Minute}
+
+	server.Close()
+	if result, err := f.Allow(ctx, "checkstreams:guild:100", limit); err != nil || !result.Allowed {
+		t.Fatalf("first use while Redis is down: got %+v (err %v)", result, err)
+	}
+	if !f.Degraded() {
+		t.Fatalf("expected the limiter to be degraded")
+	}
+	if result, _ := f.Allow(ctx, "checkstreams:guild:100", limit); result.Allowed {
+		t.Fatalf("the in-memory fallback didn't limit: %+v", result)
+	}
+
+	server.Restart()
+	now = now.Add(retryInterval)
+	if result, err := f.Allow(ctx, "checkstreams:guild:100", limit); err != nil || !result.Allowed {
+		t.Fatalf("first use after Redis is back: got %+v (err %v)", result, err)
+	}
+	if f.Degraded() {
+		t.Fatalf("expected the limiter to recover once Redis is back")
+	}
+}
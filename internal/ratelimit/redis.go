@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// allowScript keeps the uses of a key in a sorted set scored by time in milliseconds. It
+// drops the uses that left the window, then records this use if the window has room.
+// It returns whether the use was allowed, the uses left and, if denied, the milliseconds
+// until the oldest use in the window expires.
+var allowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local per = tonumber(ARGV[2])
+local count = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - per)
+local used = redis.call("ZCARD", KEYS[1])
+if used < count then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], per)
+	return {1, count - used - 1, 0}
+end
+local oldest = redis.call("ZRANGE", KEYS[1], used - count, used - count, "WITHSCORES")
+return {0, 0, tonumber(oldest[2]) + per - now}`)
+
+// Redis is a Limiter whose windows are stored under ratelimit:<key> keys, so every
+// instance sharing the Redis server shares the limits
+type Redis struct {
+	client *redis.Client
+	now    func() time.Time
+	prefix string       // Makes the uses recorded by this instance unique
+	seq    atomic.Int64 // Tells apart uses recorded by this instance in the same millisecond
+}
+
+// NewRedis returns a limiter on client
+func NewRedis(client *redis.Client) *Redis {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return &Redis{client: client, now: time.Now, prefix: hex.EncodeToString(suffix)}
+}
+
+// windowKey is the Redis key of the window of key
+func windowKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s", key)
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := r.now().UnixMilli()
+	member := fmt.Sprintf("%d-%s-%d", now, r.prefix, r.seq.Add(1))
+	reply, err := allowScript.Run(ctx, r.client, []string{windowKey(key)},
+		now, limit.Per.Milliseconds(), limit.Count, member).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check rate limit %s: %w", key, err)
+	}
+	if len(reply) != 3 {
+		return Result{}, fmt.Errorf("failed to check rate limit %s: unexpected reply %v", key, reply)
+	}
+	return Result{
+		Allowed:    reply[0] == 1,
+		Remaining:  int(reply[1]),
+		RetryAfter: time.Duration(reply[2]) * time.Millisecond,
+	}, nil
+}
+
+func (r *Redis) Forget(ctx context.Context, subject string) error {
+	iter := r.client.Scan(ctx, 0, windowKey("*"+subject+"*"), 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		if namesSubject(strings.TrimPrefix(iter.Val(), windowKey("")), subject) {
+			keys = append(keys, iter.Val())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to find the rate limits of %s: %w", subject, err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete the rate limits of %s: %w", subject, err)
+	}
+	return nil
+}
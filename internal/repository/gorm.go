@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"GoopBot/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewGorm returns a store backed by a GORM database. The models must already be migrated.
+func NewGorm(db *gorm.DB) *Store {
+	return &Store{
+		Creators:             gormCreators{db},
+		Streams:              gormStreams{db},
+		NotificationChannels: gormNotificationChannels{db},
+		Birthdays:            gormBirthdays{db},
+		BirthdayChannels:     gormBirthdayChannels{db},
+		RoleMessages:         gormRoleMessages{db},
+		DashboardTokens:      gormDashboardTokens{db},
+		AuditLog:             gormAuditLog{db},
+		PermissionRules:      gormPermissionRules{db},
+		Users:                gormUsers{db},
+	}
+}
+
+// notFound maps GORM's missing-record error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type gormCreators struct{ db *gorm.DB }
+
+func (r gormCreators) Link(ctx context.Context, creator models.GoopCreator) error {
+	// Look up unscoped so a previously unlinked (soft-deleted) record is restored
+	// instead of tripping the unique index on discord_id
+	return r.db.WithContext(ctx).Unscoped().Where(map[string]interface{}{"discord_id": creator.DiscordID}).
+		Assign(map[string]interface{}{
+			"username":        creator.Username,
+			"guild_id":        creator.GuildID,
+			"twitch_username": creator.TwitchUsername,
+			"is_active":       true,
+			"deleted_at":      nil,
+		}).
+		FirstOrCreate(&models.GoopCreator{}).Error
+}
+
+func (r gormCreators) Unlink(ctx context.Context, discordID string) error {
+	return r.db.WithContext(ctx).Where("discord_id = ?", discordID).Delete(&models.GoopCreator{}).Error
+}
+
+func (r gormCreators) Get(ctx context.Context, discordID string) (models.GoopCreator, error) {
+	var creator models.GoopCreator
+	err := r.db.WithContext(ctx).Where("discord_id = ?", discordID).First(&creator).Error
+	return creator, notFound(err)
+}
+
+func (r gormCreators) FindByTwitchUsername(ctx context.Context, twitchUsername string) (models.GoopCreator, error) {
+	var creator models.GoopCreator
+	err := r.db.WithContext(ctx).Where("twitch_username = ?", twitchUsername).First(&creator).Error
+	return creator, notFound(err)
+}
+
+func (r gormCreators) ListActive(ctx context.Context) ([]models.GoopCreator, error) {
+	var creators []models.GoopCreator
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Find(&creators).Error
+	return creators, err
+}
+
+func (r gormCreators) ListByGuild(ctx context.Context, guildID string) ([]models.GoopCreator, error) {
+	var creators []models.GoopCreator
+	err := r.db.WithContext(ctx).Where("guild_id = ?", guildID).Order("id").Find(&creators).Error
+	return creators, err
+}
+
+type gormStreams struct{ db *gorm.DB }
+
+func (r gormStreams) Get(ctx context.Context, twitchUsername string) (models.TwitchStream, error) {
+	var stream models.TwitchStream
+	err := r.db.WithContext(ctx).Where("twitch_username = ?", twitchUsername).First(&stream).Error
+	return stream, notFound(err)
+}
+
+func (r gormStreams) Save(ctx context.Context, stream models.TwitchStream) error {
+	// Upsert on twitch_username; a plain Save of a record without an ID would insert a
+	// second row and violate the unique index. Assign a map so false and zero values are written.
+	return r.db.WithContext(ctx).Where(map[string]interface{}{"twitch_username": stream.TwitchUsername}).
+		Assign(map[string]interface{}{
+			"is_live":      stream.IsLive,
+			"last_checked": stream.LastChecked,
+			"viewer_count": stream.ViewerCount,
+			"game_name":    stream.GameName,
+			"stream_title": stream.StreamTitle,
+			"discord_id":   stream.DiscordID,
+		}).
+		FirstOrCreate(&models.TwitchStream{}).Error
+}
+
+func (r gormStreams) ListLive(ctx context.Context, guildID string) ([]models.TwitchStream, error) {
+	var streams []models.TwitchStream
+	err := r.db.WithContext(ctx).Table("twitch_streams").
+		Select("twitch_streams.*").
+		Joins("JOIN goop_creators ON twitch_streams.discord_id = goop_creators.discord_id").
+		Where("goop_creators.guild_id = ? AND goop_creators.is_active = ? AND goop_creators.deleted_at IS NULL AND twitch_streams.is_live = ?",
+			guildID, true, true).
+		Find(&streams).Error
+	return streams, err
+}
+
+type gormNotificationChannels struct{ db *gorm.DB }
+
+func (r gormNotificationChannels) Activate(ctx context.Context, guildID, channelID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deactivate the guild's other channels first
+		if err := tx.Model(&models.NotificationChannel{}).Where("guild_id = ?", guildID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Where(map[string]interface{}{"channel_id": channelID}).
+			Assign(map[string]interface{}{"guild_id": guildID, "is_active": true}).
+			FirstOrCreate(&models.NotificationChannel{}).Error
+	})
+}
+
+func (r gormNotificationChannels) ListActive(ctx context.Context, guildID string) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := r.db.WithContext(ctx).Where("guild_id = ? AND is_active = ?", guildID, true).Find(&channels).Error
+	return channels, err
+}
+
+func (r gormNotificationChannels) Deactivate(ctx context.Context, guildID, channelID string) error {
+	result := r.db.WithContext(ctx).Model(&models.NotificationChannel{}).
+		Where("guild_id = ? AND channel_id = ? AND is_active = ?", guildID, channelID, true).
+		Update("is_active", false)
+	return affected(result)
+}
+
+// affected returns ErrNotFound when an update or delete matched no record
+func affected(result *gorm.DB) error {
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return result.Error
+}
+
+type gormBirthdays struct{ db *gorm.DB }
+
+func (r gormBirthdays) Set(ctx context.Context, birthday models.Birthday) error {
+	// Unscoped so a deleted birthday can be set again without tripping the unique index
+	return r.db.WithContext(ctx).Unscoped().Where(map[string]interface{}{"discord_id": birthday.DiscordID}).
+		Assign(map[string]interface{}{
+			"username":   birthday.Username,
+			"guild_id":   birthday.GuildID,
+			"month":      birthday.Month,
+			"day":        birthday.Day,
+			"year":       birthday.Year,
+			"deleted_at": nil,
+		}).
+		FirstOrCreate(&models.Birthday{}).Error
+}
+
+func (r gormBirthdays) Get(ctx context.Context, discordID string) (models.Birthday, error) {
+	var birthday models.Birthday
+	err := r.db.WithContext(ctx).Where("discord_id = ?", discordID).First(&birthday).Error
+	return birthday, notFound(err)
+}
+
+func (r gormBirthdays) Delete(ctx context.Context, discordID string) error {
+	return r.db.WithContext(ctx).Where("discord_id = ?", discordID).Delete(&models.Birthday{}).Error
+}
+
+func (r gormBirthdays) ListByGuild(ctx context.Context, guildID string) ([]models.Birthday, error) {
+	var birthdays []models.Birthday
+	err := r.db.WithContext(ctx).Where("guild_id = ?", guildID).Order("month ASC, day ASC, id ASC").Find(&birthdays).Error
+	return birthdays, err
+}
+
+func (r gormBirthdays) ListUpcoming(ctx context.Context, guildID string, month, day int) ([]models.Birthday, error) {
+	var birthdays []models.Birthday
+	err := r.db.WithContext(ctx).
+		Where("guild_id = ?", guildID).
+		Where("(month = ? AND day >= ?) OR (month = ?)", month, day, month%12+1).
+		Order("month ASC, day ASC").
+		Find(&birthdays).Error
+	return birthdays, err
+}
+
+func (r gormBirthdays) ListOn(ctx context.Context, month, day int) ([]models.Birthday, error) {
+	var birthdays []models.Birthday
+	err := r.db.WithContext(ctx).Where("month = ? AND day = ?", month, day).Find(&birthdays).Error
+	return birthdays, err
+}
+
+func (r gormBirthdays) MarkSent(ctx context.Context, discordID string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Birthday{}).Where("discord_id = ?", discordID).Update("last_sent", at).Error
+}
+
+type gormBirthdayChannels struct{ db *gorm.DB }
+
+func (r gormBirthdayChannels) Activate(ctx context.Context, guildID, channelID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deactivate the guild's other channels first
+		if err := tx.Model(&models.BirthdayChannel{}).Where("guild_id = ?", guildID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return tx.Where(map[string]interface{}{"channel_id": channelID}).
+			Assign(map[string]interface{}{"guild_id": guildID, "is_active": true}).
+			FirstOrCreate(&models.BirthdayChannel{}).Error
+	})
+}
+
+func (r gormBirthdayChannels) Active(ctx context.Context, guildID string) (models.BirthdayChannel, error) {
+	var channel models.BirthdayChannel
+	err := r.db.WithContext(ctx).Where("guild_id = ? AND is_active = ?", guildID, true).First(&channel).Error
+	return channel, notFound(err)
+}
+
+func (r gormBirthdayChannels) Deactivate(ctx context.Context, guildID string) error {
+	result := r.db.WithContext(ctx).Model(&models.BirthdayChannel{}).
+		Where("guild_id = ? AND is_active = ?", guildID, true).
+		Update("is_active", false)
+	return affected(result)
+}
+
+type gormRoleMessages struct{ db *gorm.DB }
+
+func (r gormRoleMessages) Set(ctx context.Context, msg models.RoleMessage) error {
+	// Unscoped so a removed message can be set up again without tripping the unique index
+	return r.db.WithContext(ctx).Unscoped().Where(map[string]interface{}{"message_id": msg.MessageID}).
+		Assign(map[string]interface{}{
+			"guild_id":   msg.GuildID,
+			"channel_id": msg.ChannelID,
+			"role_name":  msg.RoleName,
+			"is_active":  true,
+			"deleted_at": nil,
+		}).
+		FirstOrCreate(&models.RoleMessage{}).Error
+}
+
+func (r gormRoleMessages) Remove(ctx context.Context, messageID string) error {
+	return r.db.WithContext(ctx).Where("message_id = ?", messageID).Delete(&models.RoleMessage{}).Error
+}
+
+func (r gormRoleMessages) FindActive(ctx context.Context, messageID string) (models.RoleMessage, error) {
+	var msg models.RoleMessage
+	err := r.db.WithContext(ctx).Where("message_id = ? AND is_active = ?", messageID, true).First(&msg).Error
+	return msg, notFound(err)
+}
+
+func (r gormRoleMessages) ListActive(ctx context.Context, guildID string) ([]models.RoleMessage, error) {
+	var msgs []models.RoleMessage
+	err := r.db.WithContext(ctx).Where("guild_id = ? AND is_active = ?", guildID, true).Find(&msgs).Error
+	return msgs, err
+}
+
+type gormDashboardTokens struct{ db *gorm.DB }
+
+func (r gormDashboardTokens) Create(ctx context.Context, token models.DashboardToken) error {
+	return r.db.WithContext(ctx).Create(&token).Error
+}
+
+func (r gormDashboardTokens) FindValid(ctx context.Context, tokenHash string, now time.Time) (models.DashboardToken, error) {
+	var token models.DashboardToken
+	err := r.db.WithContext(ctx).Where("token_hash = ? AND expires_at > ?", tokenHash, now).First(&token).Error
+	return token, notFound(err)
+}
+
+func (r gormDashboardTokens) RevokeGuild(ctx context.Context, guildID string) error {
+	// Revoked tokens are of no use to anyone, so they are removed for good
+	return r.db.WithContext(ctx).Unscoped().Where("guild_id = ?", guildID).Delete(&models.DashboardToken{}).Error
+}
+
+type gormAuditLog struct{ db *gorm.DB }
+
+func (r gormAuditLog) Add(ctx context.Context, entry models.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(&entry).Error
+}
+
+func (r gormAuditLog) ListRecent(ctx context.Context, guildID string, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	err := r.db.WithContext(ctx).Where("guild_id = ?", guildID).Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+type gormPermissionRules struct{ db *gorm.DB }
+
+func (r gormPermissionRules) Set(ctx context.Context, rule models.PermissionRule) error {
+	return r.db.WithContext(ctx).
+		Where(map[string]interface{}{"guild_id": rule.GuildID, "target": rule.Target, "subject_id": rule.SubjectID}).
+		Assign(map[string]interface{}{"subject_type": rule.SubjectType, "allow": rule.Allow}).
+		FirstOrCreate(&models.PermissionRule{}).Error
+}
+
+func (r gormPermissionRules) Remove(ctx context.Context, guildID, target, subjectID string) error {
+	// Removed rules are of no use to anyone, so they are deleted for good
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("guild_id = ? AND target = ? AND subject_id = ?", guildID, target, subjectID).
+		Delete(&models.PermissionRule{})
+	return affected(result)
+}
+
+func (r gormPermissionRules) ListByGuild(ctx context.Context, guildID string) ([]models.PermissionRule, error) {
+	var rules []models.PermissionRule
+	err := r.db.WithContext(ctx).Where("guild_id = ?", guildID).Order("target, id").Find(&rules).Error
+	return rules, err
+}
+
+type gormUsers struct{ db *gorm.DB }
+
+func (r gormUsers) Records(ctx context.Context, discordID, guildID string) (UserRecords, error) {
+	return userRecords(r.db.WithContext(ctx), discordID, guildID)
+}
+
+func (r gormUsers) Forget(ctx context.Context, discordID, guildID string) (UserRecords, error) {
+	var records UserRecords
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if records, err = userRecords(tx, discordID, guildID); err != nil {
+			return err
+		}
+		// Deleting a slice deletes its records by primary key; an empty one would match everything
+		for _, table := range []struct {
+			records interface{}
+			n       int
+		}{
+			{&records.Creators, len(records.Creators)},
+			{&records.Streams, len(records.Streams)},
+			{&records.Birthdays, len(records.Birthdays)},
+			{&records.DashboardTokens, len(records.DashboardTokens)},
+			{&records.PermissionRules, len(records.PermissionRules)},
+		} {
+			if table.n == 0 {
+				continue
+			}
+			if err := tx.Unscoped().Delete(table.records).Error; err != nil {
+				return fmt.Errorf("failed to delete user records: %w", err)
+			}
+		}
+		for _, entry := range records.AuditEntries {
+			anonymizeUser(&entry, discordID)
+			err := tx.Unscoped().Model(&entry).Updates(map[string]interface{}{
+				"actor_id": entry.ActorID, "actor_name": entry.ActorName, "before": entry.Before, "after": entry.After,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to anonymize audit entries: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return UserRecords{}, err
+	}
+	return records, nil
+}
+
+// userRecords loads every record tied to a Discord user, soft-deleted ones included
+func userRecords(db *gorm.DB, discordID, guildID string) (UserRecords, error) {
+	inGuild := func(q *gorm.DB) *gorm.DB {
+		if guildID != "" {
+			return q.Where("guild_id = ?", guildID)
+		}
+		return q
+	}
+
+	var records UserRecords
+	if err := db.Unscoped().Scopes(inGuild).Where("discord_id = ?", discordID).Order("id").Find(&records.Creators).Error; err != nil {
+		return UserRecords{}, fmt.Errorf("failed to find creators: %w", err)
+	}
+	// Stream statuses have no guild of their own
+	if guildID == "" || len(records.Creators) > 0 {
+		if err := db.Unscoped().Where("discord_id = ?", discordID).Order("id").Find(&records.Streams).Error; err != nil {
+			return UserRecords{}, fmt.Errorf("failed to find streams: %w", err)
+		}
+	}
+	if err := db.Unscoped().Scopes(inGuild).Where("discord_id = ?", discordID).Order("id").Find(&records.Birthdays).Error; err != nil {
+		return UserRecords{}, fmt.Errorf("failed to find birthdays: %w", err)
+	}
+	if err := db.Unscoped().Scopes(inGuild).Where("issued_by = ?", discordID).Order("id").Find(&records.DashboardTokens).Error; err != nil {
+		return UserRecords{}, fmt.Errorf("failed to find dashboard tokens: %w", err)
+	}
+	err := db.Unscoped().Scopes(inGuild).Where("subject_type = ? AND subject_id = ?", "user", discordID).Order("id").Find(&records.PermissionRules).Error
+	if err != nil {
+		return UserRecords{}, fmt.Errorf("failed to find permission rules: %w", err)
+	}
+	// LIKE only narrows the search; "_" in an ID would match any character
+	mention := "%<@" + discordID + ">%"
+	var entries []models.AuditEntry
+	err = db.Unscoped().Scopes(inGuild).Where(clause.Or(
+		clause.Eq{Column: clause.Column{Name: "actor_id"}, Value: discordID},
+		clause.Like{Column: clause.Column{Name: "before"}, Value: mention},
+		clause.Like{Column: clause.Column{Name: "after"}, Value: mention},
+	)).Order("id").Find(&entries).Error
+	if err != nil {
+		return UserRecords{}, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+	for _, entry := range entries {
+		if mentionsUser(entry, discordID) {
+			records.AuditEntries = append(records.AuditEntries, entry)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,555 @@
+package repository
+
+import (
+	"GoopBot/internal/models"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// memory holds every record of an in-memory store. Records are keyed by the same
+// unique column the database uses.
+type memory struct {
+	mu     sync.Mutex
+	nextID uint
+
+	creators         map[string]models.GoopCreator  // by Discord ID
+	streams          map[string]models.TwitchStream // by Twitch username
+	channels         map[string]models.NotificationChannel
+	birthdays        map[string]models.Birthday // by Discord ID
+	birthdayChannels map[string]models.BirthdayChannel
+	roleMessages     map[string]models.RoleMessage    // by message ID
+	dashboardTokens  map[string]models.DashboardToken // by token hash
+	auditLog         []models.AuditEntry              // oldest first
+	permissionRules  map[permissionRuleKey]models.PermissionRule
+}
+
+// permissionRuleKey is the unique index of a permission rule
+type permissionRuleKey struct {
+	guildID, target, subjectID string
+}
+
+// NewMemory returns a store that keeps everything in memory, for tests
+func NewMemory() *Store {
+	m := &memory{
+		creators:         make(map[string]models.GoopCreator),
+		streams:          make(map[string]models.TwitchStream),
+		channels:         make(map[string]models.NotificationChannel),
+		birthdays:        make(map[string]models.Birthday),
+		birthdayChannels: make(map[string]models.BirthdayChannel),
+		roleMessages:     make(map[string]models.RoleMessage),
+		dashboardTokens:  make(map[string]models.DashboardToken),
+		permissionRules:  make(map[permissionRuleKey]models.PermissionRule),
+	}
+	return &Store{
+		Creators:             memoryCreators{m},
+		Streams:              memoryStreams{m},
+		NotificationChannels: memoryNotificationChannels{m},
+		Birthdays:            memoryBirthdays{m},
+		BirthdayChannels:     memoryBirthdayChannels{m},
+		RoleMessages:         memoryRoleMessages{m},
+		DashboardTokens:      memoryDashboardTokens{m},
+		AuditLog:             memoryAuditLog{m},
+		PermissionRules:      memoryPermissionRules{m},
+		Users:                memoryUsers{m},
+	}
+}
+
+// touch sets the ID and timestamps of a record being saved. Callers hold mu.
+func (m *memory) touch(model *gorm.Model) {
+	now := time.Now()
+	if model.ID == 0 {
+		m.nextID++
+		model.ID = m.nextID
+		model.CreatedAt = now
+	}
+	model.UpdatedAt = now
+}
+
+type memoryCreators struct{ *memory }
+
+func (r memoryCreators) Link(_ context.Context, creator models.GoopCreator) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := r.creators[creator.DiscordID]
+	existing.DiscordID = creator.DiscordID
+	existing.Username = creator.Username
+	existing.GuildID = creator.GuildID
+	existing.TwitchUsername = creator.TwitchUsername
+	existing.IsActive = true
+	r.touch(&existing.Model)
+	r.creators[creator.DiscordID] = existing
+	return nil
+}
+
+func (r memoryCreators) Unlink(_ context.Context, discordID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.creators, discordID)
+	return nil
+}
+
+func (r memoryCreators) Get(_ context.Context, discordID string) (models.GoopCreator, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	creator, ok := r.creators[discordID]
+	if !ok {
+		return models.GoopCreator{}, ErrNotFound
+	}
+	return creator, nil
+}
+
+func (r memoryCreators) FindByTwitchUsername(_ context.Context, twitchUsername string) (models.GoopCreator, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, creator := range r.creators {
+		if creator.TwitchUsername == twitchUsername {
+			return creator, nil
+		}
+	}
+	return models.GoopCreator{}, ErrNotFound
+}
+
+func (r memoryCreators) ListActive(context.Context) ([]models.GoopCreator, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var creators []models.GoopCreator
+	for _, creator := range r.creators {
+		if creator.IsActive {
+			creators = append(creators, creator)
+		}
+	}
+	sort.Slice(creators, func(i, j int) bool { return creators[i].ID < creators[j].ID })
+	return creators, nil
+}
+
+func (r memoryCreators) ListByGuild(_ context.Context, guildID string) ([]models.GoopCreator, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var creators []models.GoopCreator
+	for _, creator := range r.creators {
+		if creator.GuildID == guildID {
+			creators = append(creators, creator)
+		}
+	}
+	sort.Slice(creators, func(i, j int) bool { return creators[i].ID < creators[j].ID })
+	return creators, nil
+}
+
+type memoryStreams struct{ *memory }
+
+func (r memoryStreams) Get(_ context.Context, twitchUsername string) (models.TwitchStream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stream, ok := r.streams[twitchUsername]
+	if !ok {
+		return models.TwitchStream{}, ErrNotFound
+	}
+	return stream, nil
+}
+
+func (r memoryStreams) Save(_ context.Context, stream models.TwitchStream) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stream.Model = r.streams[stream.TwitchUsername].Model
+	r.touch(&stream.Model)
+	r.streams[stream.TwitchUsername] = stream
+	return nil
+}
+
+func (r memoryStreams) ListLive(_ context.Context, guildID string) ([]models.TwitchStream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var streams []models.TwitchStream
+	for _, stream := range r.streams {
+		creator, ok := r.creators[stream.DiscordID]
+		if stream.IsLive && ok && creator.IsActive && creator.GuildID == guildID {
+			streams = append(streams, stream)
+		}
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].ID < streams[j].ID })
+	return streams, nil
+}
+
+type memoryNotificationChannels struct{ *memory }
+
+func (r memoryNotificationChannels) Activate(_ context.Context, guildID, channelID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, channel := range r.channels {
+		if channel.GuildID == guildID {
+			channel.IsActive = false
+			r.channels[id] = channel
+		}
+	}
+	channel := r.channels[channelID]
+	channel.GuildID, channel.ChannelID, channel.IsActive = guildID, channelID, true
+	r.touch(&channel.Model)
+	r.channels[channelID] = channel
+	return nil
+}
+
+func (r memoryNotificationChannels) ListActive(_ context.Context, guildID string) ([]models.NotificationChannel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var channels []models.NotificationChannel
+	for _, channel := range r.channels {
+		if channel.GuildID == guildID && channel.IsActive {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].ID < channels[j].ID })
+	return channels, nil
+}
+
+func (r memoryNotificationChannels) Deactivate(_ context.Context, guildID, channelID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	channel, ok := r.channels[channelID]
+	if !ok || channel.GuildID != guildID || !channel.IsActive {
+		return ErrNotFound
+	}
+	channel.IsActive = false
+	r.touch(&channel.Model)
+	r.channels[channelID] = channel
+	return nil
+}
+
+type memoryBirthdays struct{ *memory }
+
+func (r memoryBirthdays) Set(_ context.Context, birthday models.Birthday) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing := r.birthdays[birthday.DiscordID]
+	birthday.Model, birthday.LastSent = existing.Model, existing.LastSent
+	r.touch(&birthday.Model)
+	r.birthdays[birthday.DiscordID] = birthday
+	return nil
+}
+
+func (r memoryBirthdays) Get(_ context.Context, discordID string) (models.Birthday, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	birthday, ok := r.birthdays[discordID]
+	if !ok {
+		return models.Birthday{}, ErrNotFound
+	}
+	return birthday, nil
+}
+
+func (r memoryBirthdays) Delete(_ context.Context, discordID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.birthdays, discordID)
+	return nil
+}
+
+func (r memoryBirthdays) ListByGuild(_ context.Context, guildID string) ([]models.Birthday, error) {
+	return r.list(func(b models.Birthday) bool { return b.GuildID == guildID }), nil
+}
+
+func (r memoryBirthdays) ListUpcoming(_ context.Context, guildID string, month, day int) ([]models.Birthday, error) {
+	return r.list(func(b models.Birthday) bool {
+		return b.GuildID == guildID && upcoming(b.Month, b.Day, month, day)
+	}), nil
+}
+
+func (r memoryBirthdays) ListOn(_ context.Context, month, day int) ([]models.Birthday, error) {
+	return r.list(func(b models.Birthday) bool { return b.Month == month && b.Day == day }), nil
+}
+
+// list returns the matching birthdays ordered by month and day
+func (r memoryBirthdays) list(match func(models.Birthday) bool) []models.Birthday {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var birthdays []models.Birthday
+	for _, birthday := range r.birthdays {
+		if match(birthday) {
+			birthdays = append(birthdays, birthday)
+		}
+	}
+	sort.Slice(birthdays, func(i, j int) bool {
+		if birthdays[i].Month != birthdays[j].Month {
+			return birthdays[i].Month < birthdays[j].Month
+		}
+		if birthdays[i].Day != birthdays[j].Day {
+			return birthdays[i].Day < birthdays[j].Day
+		}
+		return birthdays[i].ID < birthdays[j].ID
+	})
+	return birthdays
+}
+
+func (r memoryBirthdays) MarkSent(_ context.Context, discordID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if birthday, ok := r.birthdays[discordID]; ok {
+		birthday.LastSent = at
+		r.touch(&birthday.Model)
+		r.birthdays[discordID] = birthday
+	}
+	return nil
+}
+
+type memoryBirthdayChannels struct{ *memory }
+
+func (r memoryBirthdayChannels) Activate(_ context.Context, guildID, channelID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, channel := range r.birthdayChannels {
+		if channel.GuildID == guildID {
+			channel.IsActive = false
+			r.birthdayChannels[id] = channel
+		}
+	}
+	channel := r.birthdayChannels[channelID]
+	channel.GuildID, channel.ChannelID, channel.IsActive = guildID, channelID, true
+	r.touch(&channel.Model)
+	r.birthdayChannels[channelID] = channel
+	return nil
+}
+
+func (r memoryBirthdayChannels) Active(_ context.Context, guildID string) (models.BirthdayChannel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, channel := range r.birthdayChannels {
+		if channel.GuildID == guildID && channel.IsActive {
+			return channel, nil
+		}
+	}
+	return models.BirthdayChannel{}, ErrNotFound
+}
+
+func (r memoryBirthdayChannels) Deactivate(_ context.Context, guildID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, channel := range r.birthdayChannels {
+		if channel.GuildID == guildID && channel.IsActive {
+			channel.IsActive = false
+			r.touch(&channel.Model)
+			r.birthdayChannels[id] = channel
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+type memoryRoleMessages struct{ *memory }
+
+func (r memoryRoleMessages) Set(_ context.Context, msg models.RoleMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.Model, msg.IsActive = r.roleMessages[msg.MessageID].Model, true
+	r.touch(&msg.Model)
+	r.roleMessages[msg.MessageID] = msg
+	return nil
+}
+
+func (r memoryRoleMessages) Remove(_ context.Context, messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.roleMessages, messageID)
+	return nil
+}
+
+func (r memoryRoleMessages) FindActive(_ context.Context, messageID string) (models.RoleMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg, ok := r.roleMessages[messageID]
+	if !ok || !msg.IsActive {
+		return models.RoleMessage{}, ErrNotFound
+	}
+	return msg, nil
+}
+
+func (r memoryRoleMessages) ListActive(_ context.Context, guildID string) ([]models.RoleMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var msgs []models.RoleMessage
+	for _, msg := range r.roleMessages {
+		if msg.GuildID == guildID && msg.IsActive {
+			msgs = append(msgs, msg)
+		}
+	}
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].ID < msgs[j].ID })
+	return msgs, nil
+}
+
+type memoryDashboardTokens struct{ *memory }
+
+func (r memoryDashboardTokens) Create(_ context.Context, token models.DashboardToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.dashboardTokens[token.TokenHash]; ok {
+		return errors.New("dashboard token already exists")
+	}
+	token.Model = gorm.Model{}
+	r.touch(&token.Model)
+	r.dashboardTokens[token.TokenHash] = token
+	return nil
+}
+
+func (r memoryDashboardTokens) FindValid(_ context.Context, tokenHash string, now time.Time) (models.DashboardToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.dashboardTokens[tokenHash]
+	if !ok || !token.ExpiresAt.After(now) {
+		return models.DashboardToken{}, ErrNotFound
+	}
+	return token, nil
+}
+
+func (r memoryDashboardTokens) RevokeGuild(_ context.Context, guildID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, token := range r.dashboardTokens {
+		if token.GuildID == guildID {
+			delete(r.dashboardTokens, hash)
+		}
+	}
+	return nil
+}
+
+type memoryAuditLog struct{ *memory }
+
+func (r memoryAuditLog) Add(_ context.Context, entry models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.Model = gorm.Model{}
+	r.touch(&entry.Model)
+	r.auditLog = append(r.auditLog, entry)
+	return nil
+}
+
+func (r memoryAuditLog) ListRecent(_ context.Context, guildID string, limit int) ([]models.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []models.AuditEntry
+	for i := len(r.auditLog) - 1; i >= 0 && len(entries) < limit; i-- {
+		if r.auditLog[i].GuildID == guildID {
+			entries = append(entries, r.auditLog[i])
+		}
+	}
+	return entries, nil
+}
+
+type memoryPermissionRules struct{ *memory }
+
+func (r memoryPermissionRules) Set(_ context.Context, rule models.PermissionRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := permissionRuleKey{rule.GuildID, rule.Target, rule.SubjectID}
+	rule.Model = r.permissionRules[key].Model
+	r.touch(&rule.Model)
+	r.permissionRules[key] = rule
+	return nil
+}
+
+func (r memoryPermissionRules) Remove(_ context.Context, guildID, target, subjectID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := permissionRuleKey{guildID, target, subjectID}
+	if _, ok := r.permissionRules[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.permissionRules, key)
+	return nil
+}
+
+func (r memoryPermissionRules) ListByGuild(_ context.Context, guildID string) ([]models.PermissionRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rules []models.PermissionRule
+	for _, rule := range r.permissionRules {
+		if rule.GuildID == guildID {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Target != rules[j].Target {
+			return rules[i].Target < rules[j].Target
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+type memoryUsers struct{ *memory }
+
+func (r memoryUsers) Records(_ context.Context, discordID, guildID string) (UserRecords, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.records(discordID, guildID), nil
+}
+
+func (r memoryUsers) Forget(_ context.Context, discordID, guildID string) (UserRecords, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := r.records(discordID, guildID)
+	for _, creator := range records.Creators {
+		delete(r.creators, creator.DiscordID)
+	}
+	for _, stream := range records.Streams {
+		delete(r.streams, stream.TwitchUsername)
+	}
+	for _, birthday := range records.Birthdays {
+		delete(r.birthdays, birthday.DiscordID)
+	}
+	for _, token := range records.DashboardTokens {
+		delete(r.dashboardTokens, token.TokenHash)
+	}
+	for _, rule := range records.PermissionRules {
+		delete(r.permissionRules, permissionRuleKey{rule.GuildID, rule.Target, rule.SubjectID})
+	}
+	for i := range r.auditLog {
+		entry := &r.auditLog[i]
+		if (guildID == "" || entry.GuildID == guildID) && mentionsUser(*entry, discordID) {
+			anonymizeUser(entry, discordID)
+		}
+	}
+	return records, nil
+}
+
+// records collects the records tied to a Discord user. Callers hold mu.
+func (r memoryUsers) records(discordID, guildID string) UserRecords {
+	inGuild := func(id string) bool { return guildID == "" || id == guildID }
+
+	var records UserRecords
+	if creator, ok := r.creators[discordID]; ok && inGuild(creator.GuildID) {
+		records.Creators = append(records.Creators, creator)
+	}
+	if guildID == "" || len(records.Creators) > 0 {
+		for _, stream := range r.streams {
+			if stream.DiscordID == discordID {
+				records.Streams = append(records.Streams, stream)
+			}
+		}
+		sort.Slice(records.Streams, func(i, j int) bool { return records.Streams[i].ID < records.Streams[j].ID })
+	}
+	if birthday, ok := r.birthdays[discordID]; ok && inGuild(birthday.GuildID) {
+		records.Birthdays = append(records.Birthdays, birthday)
+	}
+	for _, token := range r.dashboardTokens {
+		if token.IssuedBy == discordID && inGuild(token.GuildID) {
+			records.DashboardTokens = append(records.DashboardTokens, token)
+		}
+	}
+	sort.Slice(records.DashboardTokens, func(i, j int) bool { return records.DashboardTokens[i].ID < records.DashboardTokens[j].ID })
+	for _, rule := range r.permissionRules {
+		if rule.SubjectType == "user" && rule.SubjectID == discordID && inGuild(rule.GuildID) {
+			records.PermissionRules = append(records.PermissionRules, rule)
+		}
+	}
+	sort.Slice(records.PermissionRules, func(i, j int) bool { return records.PermissionRules[i].ID < records.PermissionRules[j].ID })
+	for _, entry := range r.auditLog {
+		if inGuild(entry.GuildID) && mentionsUser(entry, discordID) {
+			records.AuditEntries = append(records.AuditEntries, entry)
+		}
+	}
+	return records
+}
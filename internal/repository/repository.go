@@ -0,0 +1,190 @@
+// Package repository is the storage layer between the bot and the database. Feature modules
+// use these typed interfaces instead of building queries, so their logic can be tested against
+// the in-memory implementation and the storage engine can change without touching handlers.
+package repository
+
+import (
+	"GoopBot/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a lookup matches no record
+var ErrNotFound = errors.New("record not found")
+
+// Creators stores the Twitch accounts linked by Goop Creators
+type Creators interface {
+	// Link creates or updates the creator with creator.DiscordID and marks it active,
+	// restoring a previously unlinked record
+	Link(ctx context.Context, creator models.GoopCreator) error
+	// Unlink removes the creator with this Discord ID
+	Unlink(ctx context.Context, discordID string) error
+	// Get returns the creator with this Discord ID
+	Get(ctx context.Context, discordID string) (models.GoopCreator, error)
+	// FindByTwitchUsername returns the creator linked to a Twitch account
+	FindByTwitchUsername(ctx context.Context, twitchUsername string) (models.GoopCreator, error)
+	// ListActive returns every creator with notifications enabled
+	ListActive(ctx context.Context) ([]models.GoopCreator, error)
+	// ListByGuild returns every creator linked in a guild
+	ListByGuild(ctx context.Context, guildID string) ([]models.GoopCreator, error)
+}
+
+// Streams stores the last known status of each Twitch stream
+type Streams interface {
+	// Get returns the status of a Twitch account
+	Get(ctx context.Context, twitchUsername string) (models.TwitchStream, error)
+	// Save creates or updates the status for stream.TwitchUsername
+	Save(ctx context.Context, stream models.TwitchStream) error
+	// ListLive returns the live streams of a guild's active creators
+	ListLive(ctx context.Context, guildID string) ([]models.TwitchStream, error)
+}
+
+// NotificationChannels stores where going-live notifications are posted
+type NotificationChannels interface {
+	// Activate makes channelID the guild's only active notification channel
+	Activate(ctx context.Context, guildID, channelID string) error
+	// ListActive returns the guild's active notification channels
+	ListActive(ctx context.Context, guildID string) ([]models.NotificationChannel, error)
+	// Deactivate stops posting to one of the guild's channels, or returns ErrNotFound if it
+	// is not active
+	Deactivate(ctx context.Context, guildID, channelID string) error
+}
+
+// Birthdays stores member birthdays
+type Birthdays interface {
+	// Set creates or updates the birthday for birthday.DiscordID
+	Set(ctx context.Context, birthday models.Birthday) error
+	// Get returns the birthday of the member with this Discord ID
+	Get(ctx context.Context, discordID string) (models.Birthday, error)
+	// Delete removes the birthday of the member with this Discord ID
+	Delete(ctx context.Context, discordID string) error
+	// ListByGuild returns every birthday in a guild, ordered by month and day
+	ListByGuild(ctx context.Context, guildID string) ([]models.Birthday, error)
+	// ListUpcoming returns the guild's birthdays from month/day to the end of the following
+	// month, ordered by month and day
+	ListUpcoming(ctx context.Context, guildID string, month, day int) ([]models.Birthday, error)
+	// ListOn returns the birthdays in every guild that fall on month/day
+	ListOn(ctx context.Context, month, day int) ([]models.Birthday, error)
+	// MarkSent records when the birthday message for a member was last sent
+	MarkSent(ctx context.Context, discordID string, at time.Time) error
+}
+
+// BirthdayChannels stores where birthday messages are posted
+type BirthdayChannels interface {
+	// Activate makes channelID the guild's only active birthday channel
+	Activate(ctx context.Context, guildID, channelID string) error
+	// Active returns the guild's active birthday channel
+	Active(ctx context.Context, guildID string) (models.BirthdayChannel, error)
+	// Deactivate stops posting birthday messages in the guild, or returns ErrNotFound if it
+	// has no active channel
+	Deactivate(ctx context.Context, guildID string) error
+}
+
+// RoleMessages stores messages that grant a role when reacted to
+type RoleMessages interface {
+	// Set creates or updates the role message for msg.MessageID and marks it active
+	Set(ctx context.Context, msg models.RoleMessage) error
+	// Remove stops a message from granting its role
+	Remove(ctx context.Context, messageID string) error
+	// FindActive returns the active role message with this message ID
+	FindActive(ctx context.Context, messageID string) (models.RoleMessage, error)
+	// ListActive returns the guild's active role messages
+	ListActive(ctx context.Context, guildID string) ([]models.RoleMessage, error)
+}
+
+// DashboardTokens stores logins for the web dashboard
+type DashboardTokens interface {
+	// Create stores a new token
+	Create(ctx context.Context, token models.DashboardToken) error
+	// FindValid returns the token with this hash unless it has expired by now
+	FindValid(ctx context.Context, tokenHash string, now time.Time) (models.DashboardToken, error)
+	// RevokeGuild removes every token of a guild
+	RevokeGuild(ctx context.Context, guildID string) error
+}
+
+// AuditLog stores the admin-level changes made to each guild
+type AuditLog interface {
+	// Add records an entry
+	Add(ctx context.Context, entry models.AuditEntry) error
+	// ListRecent returns up to limit of the guild's entries, newest first
+	ListRecent(ctx context.Context, guildID string, limit int) ([]models.AuditEntry, error)
+}
+
+// PermissionRules stores the per-guild rules that grant or deny commands to roles and members
+type PermissionRules interface {
+	// Set creates or replaces the guild's rule for rule.Target and rule.SubjectID
+	Set(ctx context.Context, rule models.PermissionRule) error
+	// Remove deletes the guild's rule for a target and subject, or returns ErrNotFound if
+	// there is none
+	Remove(ctx context.Context, guildID, target, subjectID string) error
+	// ListByGuild returns the guild's rules ordered by target
+	ListByGuild(ctx context.Context, guildID string) ([]models.PermissionRule, error)
+}
+
+// UserRecords is everything stored about one Discord user
+type UserRecords struct {
+	Creators        []models.GoopCreator    `json:"creators"`
+	Streams         []models.TwitchStream   `json:"twitch_streams"`
+	Birthdays       []models.Birthday       `json:"birthdays"`
+	DashboardTokens []models.DashboardToken `json:"dashboard_tokens"` // Dashboard logins the user asked for
+	PermissionRules []models.PermissionRule `json:"permission_rules"` // Rules that grant or deny the user commands
+	AuditEntries    []models.AuditEntry     `json:"audit_entries"`    // Changes the user made or that mention them
+}
+
+// Len returns the number of records
+func (r UserRecords) Len() int {
+	return len(r.Creators) + len(r.Streams) + len(r.Birthdays) + len(r.DashboardTokens) + len(r.PermissionRules) + len(r.AuditEntries)
+}
+
+// Users finds and erases the records tied to a Discord user, for privacy requests. A
+// non-empty guildID limits both to the records kept for that guild; the stream status of
+// a creator goes with their creator record.
+type Users interface {
+	// Records returns every record tied to the Discord ID, including deleted ones that are
+	// still kept in the database
+	Records(ctx context.Context, discordID, guildID string) (UserRecords, error)
+	// Forget permanently deletes the records Records returns and returns what it deleted.
+	// Audit entries are anonymized instead, so the guild's log still shows what changed.
+	Forget(ctx context.Context, discordID, guildID string) (UserRecords, error)
+}
+
+// forgottenMember stands in for a forgotten user in the audit log
+const forgottenMember = "a forgotten member"
+
+// mentionsUser reports whether an audit entry was made by the Discord user or mentions them
+func mentionsUser(entry models.AuditEntry, discordID string) bool {
+	mention := "<@" + discordID + ">"
+	return entry.ActorID == discordID || strings.Contains(entry.Before, mention) || strings.Contains(entry.After, mention)
+}
+
+// anonymizeUser removes the Discord user from an audit entry
+func anonymizeUser(entry *models.AuditEntry, discordID string) {
+	if entry.ActorID == discordID {
+		entry.ActorID, entry.ActorName = "", forgottenMember
+	}
+	mention := "<@" + discordID + ">"
+	entry.Before = strings.ReplaceAll(entry.Before, mention, forgottenMember)
+	entry.After = strings.ReplaceAll(entry.After, mention, forgottenMember)
+}
+
+// Store groups the repositories used by the bot and its modules
+type Store struct {
+	Creators             Creators
+	Streams              Streams
+	NotificationChannels NotificationChannels
+	Birthdays            Birthdays
+	BirthdayChannels     BirthdayChannels
+	RoleMessages         RoleMessages
+	DashboardTokens      DashboardTokens
+	AuditLog             AuditLog
+	PermissionRules      PermissionRules
+	Users                Users
+}
+
+// upcoming reports whether month/day falls between fromMonth/fromDay and the end of the
+// following month
+func upcoming(month, day, fromMonth, fromDay int) bool {
+	return (month == fromMonth && day >= fromDay) || month == fromMonth%12+1
+}
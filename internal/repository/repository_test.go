@@ -0,0 +1,413 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+)
+
+// stores runs a test against every implementation so they behave the same
+func stores(t *testing.T, test func(t *testing.T, store *repository.Store)) {
+	t.Run("gorm", func(t *testing.T) {
+		test(t, repository.NewGorm(bottest.NewDB(t)))
+	})
+	t.Run("memory", func(t *testing.T) {
+		test(t, repository.NewMemory())
+	})
+}
+
+func TestCreators(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		if _, err := store.Creators.FindByTwitchUsername(ctx, "goopy"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+
+		creator := models.GoopCreator{DiscordID: "1", Username: "creator", GuildID: "100", TwitchUsername: "goopy"}
+		if err := store.Creators.Link(ctx, creator); err != nil {
+			t.Fatalf("link: %v", err)
+		}
+		creator.TwitchUsername = "goopy2"
+		if err := store.Creators.Link(ctx, creator); err != nil {
+			t.Fatalf("relink: %v", err)
+		}
+		found, err := store.Creators.FindByTwitchUsername(ctx, "goopy2")
+		if err != nil || found.DiscordID != "1" || !found.IsActive {
+			t.Fatalf("unexpected creator %+v (err %v)", found, err)
+		}
+
+		if err := store.Creators.Unlink(ctx, "1"); err != nil {
+			t.Fatalf("unlink: %v", err)
+		}
+		if active, _ := store.Creators.ListActive(ctx); len(active) != 0 {
+			t.Fatalf("unlinked creator still active: %+v", active)
+		}
+
+		// Linking again after unlinking restores the creator
+		if err := store.Creators.Link(ctx, creator); err != nil {
+			t.Fatalf("link after unlink: %v", err)
+		}
+		if active, _ := store.Creators.ListActive(ctx); len(active) != 1 {
+			t.Fatalf("expected 1 active creator, got %+v", active)
+		}
+
+		store.Creators.Link(ctx, models.GoopCreator{DiscordID: "2", GuildID: "999", TwitchUsername: "other"})
+		if got, err := store.Creators.Get(ctx, "2"); err != nil || got.GuildID != "999" {
+			t.Fatalf("unexpected creator %+v (err %v)", got, err)
+		}
+		if list, _ := store.Creators.ListByGuild(ctx, "100"); len(list) != 1 || list[0].DiscordID != "1" {
+			t.Fatalf("unexpected guild creators %+v", list)
+		}
+	})
+}
+
+func TestStreams(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		store.Creators.Link(ctx, models.GoopCreator{DiscordID: "1", GuildID: "100", TwitchUsername: "goopy"})
+		store.Creators.Link(ctx, models.GoopCreator{DiscordID: "2", GuildID: "999", TwitchUsername: "other"})
+
+		now := time.Now()
+		if err := store.Streams.Save(ctx, models.TwitchStream{TwitchUsername: "goopy", IsLive: true, ViewerCount: 5, DiscordID: "1", LastChecked: &now}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		store.Streams.Save(ctx, models.TwitchStream{TwitchUsername: "other", IsLive: true, DiscordID: "2"})
+
+		live, err := store.Streams.ListLive(ctx, "100")
+		if err != nil || len(live) != 1 || live[0].TwitchUsername != "goopy" {
+			t.Fatalf("unexpected live streams %+v (err %v)", live, err)
+		}
+
+		// Saving again updates the same record, including false and zero values
+		if err := store.Streams.Save(ctx, models.TwitchStream{TwitchUsername: "goopy", IsLive: false, DiscordID: "1"}); err != nil {
+			t.Fatalf("save update: %v", err)
+		}
+		stream, err := store.Streams.Get(ctx, "goopy")
+		if err != nil || stream.IsLive || stream.ViewerCount != 0 {
+			t.Fatalf("stream was not updated: %+v (err %v)", stream, err)
+		}
+		if live, _ := store.Streams.ListLive(ctx, "100"); len(live) != 0 {
+			t.Fatalf("offline stream listed as live: %+v", live)
+		}
+
+		// Unlinked creators are not listed
+		store.Streams.Save(ctx, models.TwitchStream{TwitchUsername: "goopy", IsLive: true, DiscordID: "1"})
+		store.Creators.Unlink(ctx, "1")
+		if live, _ := store.Streams.ListLive(ctx, "100"); len(live) != 0 {
+			t.Fatalf("stream of an unlinked creator listed as live: %+v", live)
+		}
+	})
+}
+
+func TestNotificationChannels(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		store.NotificationChannels.Activate(ctx, "100", "200")
+		store.NotificationChannels.Activate(ctx, "100", "201")
+		store.NotificationChannels.Activate(ctx, "999", "900")
+
+		channels, err := store.NotificationChannels.ListActive(ctx, "100")
+		if err != nil || len(channels) != 1 || channels[0].ChannelID != "201" {
+			t.Fatalf("unexpected channels %+v (err %v)", channels, err)
+		}
+
+		store.NotificationChannels.Activate(ctx, "100", "200")
+		if channels, _ := store.NotificationChannels.ListActive(ctx, "100"); len(channels) != 1 || channels[0].ChannelID != "200" {
+			t.Fatalf("reactivation failed: %+v", channels)
+		}
+
+		// Another guild's channel can't be deactivated
+		if err := store.NotificationChannels.Deactivate(ctx, "100", "900"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if err := store.NotificationChannels.Deactivate(ctx, "100", "200"); err != nil {
+			t.Fatalf("deactivate: %v", err)
+		}
+		if channels, _ := store.NotificationChannels.ListActive(ctx, "100"); len(channels) != 0 {
+			t.Fatalf("deactivated channel still active: %+v", channels)
+		}
+	})
+}
+
+func TestBirthdays(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "1", Username: "a", GuildID: "100", Month: 3, Day: 20})
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "2", Username: "b", GuildID: "100", Month: 4, Day: 1})
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "3", Username: "c", GuildID: "100", Month: 3, Day: 10})
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "4", Username: "d", GuildID: "999", Month: 3, Day: 15})
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "5", Username: "e", GuildID: "100", Month: 3, Day: 15})
+
+		upcoming, err := store.Birthdays.ListUpcoming(ctx, "100", 3, 15)
+		if err != nil || len(upcoming) != 3 || upcoming[0].DiscordID != "5" || upcoming[1].DiscordID != "1" || upcoming[2].DiscordID != "2" {
+			t.Fatalf("unexpected upcoming birthdays %+v (err %v)", upcoming, err)
+		}
+
+		// December wraps around to January
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "6", Username: "f", GuildID: "100", Month: 1, Day: 2})
+		if upcoming, _ := store.Birthdays.ListUpcoming(ctx, "100", 12, 1); len(upcoming) != 1 || upcoming[0].DiscordID != "6" {
+			t.Fatalf("unexpected birthdays across the new year: %+v", upcoming)
+		}
+
+		today, _ := store.Birthdays.ListOn(ctx, 3, 15)
+		if len(today) != 2 {
+			t.Fatalf("expected 2 birthdays on 03/15, got %+v", today)
+		}
+
+		sent := time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)
+		if err := store.Birthdays.MarkSent(ctx, "5", sent); err != nil {
+			t.Fatalf("mark sent: %v", err)
+		}
+		// Changing the date keeps the last sent time
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "5", Username: "e", GuildID: "100", Month: 3, Day: 16})
+		moved, _ := store.Birthdays.ListOn(ctx, 3, 16)
+		if len(moved) != 1 || !moved[0].LastSent.Equal(sent) {
+			t.Fatalf("unexpected birthday after update: %+v", moved)
+		}
+
+		if all, _ := store.Birthdays.ListByGuild(ctx, "100"); len(all) != 5 || all[0].DiscordID != "6" || all[4].DiscordID != "2" {
+			t.Fatalf("unexpected guild birthdays %+v", all)
+		}
+
+		if err := store.Birthdays.Delete(ctx, "1"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := store.Birthdays.Get(ctx, "1"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound after delete, got %v", err)
+		}
+		// A deleted birthday can be set again
+		if err := store.Birthdays.Set(ctx, models.Birthday{DiscordID: "1", Username: "a", GuildID: "100", Month: 5, Day: 5}); err != nil {
+			t.Fatalf("set after delete: %v", err)
+		}
+		if got, err := store.Birthdays.Get(ctx, "1"); err != nil || got.Month != 5 {
+			t.Fatalf("unexpected birthday %+v (err %v)", got, err)
+		}
+	})
+}
+
+func TestBirthdayChannels(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		if _, err := store.BirthdayChannels.Active(ctx, "100"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		store.BirthdayChannels.Activate(ctx, "100", "200")
+		store.BirthdayChannels.Activate(ctx, "100", "201")
+		channel, err := store.BirthdayChannels.Active(ctx, "100")
+		if err != nil || channel.ChannelID != "201" {
+			t.Fatalf("unexpected channel %+v (err %v)", channel, err)
+		}
+
+		if err := store.BirthdayChannels.Deactivate(ctx, "100"); err != nil {
+			t.Fatalf("deactivate: %v", err)
+		}
+		if err := store.BirthdayChannels.Deactivate(ctx, "100"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound with no active channel, got %v", err)
+		}
+	})
+}
+
+func TestRoleMessages(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		msg := models.RoleMessage{GuildID: "100", ChannelID: "200", MessageID: "555", RoleName: "member"}
+		if err := store.RoleMessages.Set(ctx, msg); err != nil {
+			t.Fatalf("set: %v", err)
+		}
+		found, err := store.RoleMessages.FindActive(ctx, "555")
+		if err != nil || found.RoleName != "member" || !found.IsActive {
+			t.Fatalf("unexpected role message %+v (err %v)", found, err)
+		}
+
+		if err := store.RoleMessages.Remove(ctx, "555"); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if _, err := store.RoleMessages.FindActive(ctx, "555"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound after remove, got %v", err)
+		}
+
+		// A removed message can be set up again
+		msg.RoleName = "vip"
+		if err := store.RoleMessages.Set(ctx, msg); err != nil {
+			t.Fatalf("set after remove: %v", err)
+		}
+		list, _ := store.RoleMessages.ListActive(ctx, "100")
+		if len(list) != 1 || list[0].RoleName != "vip" {
+			t.Fatalf("unexpected role messages: %+v", list)
+		}
+	})
+}
+
+func TestDashboardTokens(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+		now := time.Now()
+
+		store.DashboardTokens.Create(ctx, models.DashboardToken{GuildID: "100", TokenHash: "live", IssuedBy: "301", ExpiresAt: now.Add(time.Hour)})
+		store.DashboardTokens.Create(ctx, models.DashboardToken{GuildID: "100", TokenHash: "old", ExpiresAt: now.Add(-time.Minute)})
+		store.DashboardTokens.Create(ctx, models.DashboardToken{GuildID: "999", TokenHash: "other", ExpiresAt: now.Add(time.Hour)})
+
+		token, err := store.DashboardTokens.FindValid(ctx, "live", now)
+		if err != nil || token.GuildID != "100" || token.IssuedBy != "301" {
+			t.Fatalf("unexpected token %+v (err %v)", token, err)
+		}
+		if _, err := store.DashboardTokens.FindValid(ctx, "old", now); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound for an expired token, got %v", err)
+		}
+
+		if err := store.DashboardTokens.RevokeGuild(ctx, "100"); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if _, err := store.DashboardTokens.FindValid(ctx, "live", now); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound after revoking, got %v", err)
+		}
+		if _, err := store.DashboardTokens.FindValid(ctx, "other", now); err != nil {
+			t.Fatalf("another guild's token was revoked: %v", err)
+		}
+	})
+}
+
+func TestPermissionRules(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		rules := []models.PermissionRule{
+			{GuildID: "100", Target: "checkstreams", SubjectType: "role", SubjectID: "400", Allow: true},
+			{GuildID: "100", Target: "all", SubjectType: "user", SubjectID: "303"},
+			{GuildID: "999", Target: "checkstreams", SubjectType: "role", SubjectID: "400", Allow: true},
+		}
+		for _, rule := range rules {
+			if err := store.PermissionRules.Set(ctx, rule); err != nil {
+				t.Fatalf("set: %v", err)
+			}
+		}
+		// Setting the same target and subject again replaces the rule
+		store.PermissionRules.Set(ctx, models.PermissionRule{GuildID: "100", Target: "all", SubjectType: "user", SubjectID: "303", Allow: true})
+
+		got, err := store.PermissionRules.ListByGuild(ctx, "100")
+		if err != nil || len(got) != 2 || got[0].Target != "all" || !got[0].Allow || got[1].Target != "checkstreams" {
+			t.Fatalf("unexpected rules %+v (err %v)", got, err)
+		}
+
+		if err := store.PermissionRules.Remove(ctx, "100", "checkstreams", "400"); err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+		if err := store.PermissionRules.Remove(ctx, "100", "checkstreams", "400"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("removing a missing rule: got %v, want ErrNotFound", err)
+		}
+		if got, _ := store.PermissionRules.ListByGuild(ctx, "100"); len(got) != 1 {
+			t.Fatalf("expected 1 rule left, got %+v", got)
+		}
+		// A removed rule can be set again
+		if err := store.PermissionRules.Set(ctx, rules[0]); err != nil {
+			t.Fatalf("set again: %v", err)
+		}
+		if got, _ := store.PermissionRules.ListByGuild(ctx, "999"); len(got) != 1 {
+			t.Fatalf("other guild's rules changed: %+v", got)
+		}
+	})
+}
+
+func TestUsers(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+		now := time.Now()
+
+		store.Creators.Link(ctx, models.GoopCreator{DiscordID: "1", GuildID: "100", TwitchUsername: "goopy"})
+		store.Streams.Save(ctx, models.TwitchStream{TwitchUsername: "goopy", DiscordID: "1", LastChecked: &now})
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "1", GuildID: "100", Month: 3, Day: 15})
+		store.DashboardTokens.Create(ctx, models.DashboardToken{GuildID: "100", TokenHash: "a", IssuedBy: "1", ExpiresAt: now.Add(time.Hour)})
+		store.Creators.Link(ctx, models.GoopCreator{DiscordID: "2", GuildID: "100", TwitchUsername: "other"})
+		store.Birthdays.Set(ctx, models.Birthday{DiscordID: "2", GuildID: "100", Month: 4, Day: 1})
+		store.PermissionRules.Set(ctx, models.PermissionRule{GuildID: "100", Target: "all", SubjectType: "user", SubjectID: "1"})
+		store.PermissionRules.Set(ctx, models.PermissionRule{GuildID: "100", Target: "help", SubjectType: "role", SubjectID: "1"})
+		store.AuditLog.Add(ctx, models.AuditEntry{GuildID: "100", ActorID: "1", ActorName: "one", Action: "set prefix", After: "?"})
+		store.AuditLog.Add(ctx, models.AuditEntry{GuildID: "100", ActorID: "2", Action: "forget user", Before: "<@1>: a birthday"})
+		store.AuditLog.Add(ctx, models.AuditEntry{GuildID: "100", ActorID: "2", Action: "forget user", Before: "<@12>: a birthday"})
+		store.AuditLog.Add(ctx, models.AuditEntry{GuildID: "998", ActorID: "1", Action: "set prefix", After: "$"})
+
+		records, err := store.Users.Records(ctx, "1", "")
+		if err != nil || len(records.Creators) != 1 || len(records.Streams) != 1 || len(records.Birthdays) != 1 || len(records.DashboardTokens) != 1 || len(records.PermissionRules) != 1 || len(records.AuditEntries) != 3 {
+			t.Fatalf("unexpected records %+v (err %v)", records, err)
+		}
+		if records, _ := store.Users.Records(ctx, "1", "999"); records.Len() != 0 {
+			t.Fatalf("another guild sees the user's records: %+v", records)
+		}
+
+		forgotten, err := store.Users.Forget(ctx, "1", "100")
+		if err != nil || forgotten.Len() != 7 {
+			t.Fatalf("forget deleted %+v (err %v), want 7 records", forgotten, err)
+		}
+		// A role that happens to share the ID isn't the user
+		if rules, _ := store.PermissionRules.ListByGuild(ctx, "100"); len(rules) != 1 || rules[0].SubjectType != "role" {
+			t.Fatalf("unexpected permission rules after forgetting %+v", rules)
+		}
+		if records, _ := store.Users.Records(ctx, "1", ""); records.Len() != 1 || records.AuditEntries[0].GuildID != "998" {
+			t.Fatalf("records left after forgetting: %+v", records)
+		}
+		if records, _ := store.Users.Records(ctx, "2", ""); records.Len() != 4 {
+			t.Fatalf("another user's records were deleted: %+v", records)
+		}
+
+		// Audit entries are kept without the user in them
+		entries, _ := store.AuditLog.ListRecent(ctx, "100", 10)
+		if len(entries) != 3 || entries[0].Before != "<@12>: a birthday" || entries[1].Before != "a forgotten member: a birthday" ||
+			entries[2].ActorID != "" || entries[2].ActorName != "a forgotten member" || entries[2].After != "?" {
+			t.Fatalf("unexpected audit entries after forgetting %+v", entries)
+		}
+	})
+}
+
+func TestUsersIncludeDeletedRecords(t *testing.T) {
+	ctx := context.Background()
+	conn := bottest.NewDB(t)
+	store := repository.NewGorm(conn)
+
+	store.Creators.Link(ctx, models.GoopCreator{DiscordID: "1", GuildID: "100", TwitchUsername: "goopy"})
+	store.Creators.Unlink(ctx, "1")
+
+	records, err := store.Users.Records(ctx, "1", "")
+	if err != nil || len(records.Creators) != 1 || !records.Creators[0].DeletedAt.Valid {
+		t.Fatalf("unlinked creator missing from %+v (err %v)", records, err)
+	}
+	if _, err := store.Users.Forget(ctx, "1", ""); err != nil {
+		t.Fatalf("forget: %v", err)
+	}
+	var count int64
+	conn.Unscoped().Model(&models.GoopCreator{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d creator rows left after forgetting, want 0", count)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	stores(t, func(t *testing.T, store *repository.Store) {
+		ctx := context.Background()
+
+		for _, after := range []string{"?", "$", "!"} {
+			if err := store.AuditLog.Add(ctx, models.AuditEntry{GuildID: "100", ActorID: "301", Action: "set prefix", After: after}); err != nil {
+				t.Fatalf("add: %v", err)
+			}
+		}
+		store.AuditLog.Add(ctx, models.AuditEntry{GuildID: "999", Action: "set prefix", After: "#"})
+
+		entries, err := store.AuditLog.ListRecent(ctx, "100", 2)
+		if err != nil || len(entries) != 2 || entries[0].After != "!" || entries[1].After != "$" {
+			t.Fatalf("unexpected entries %+v (err %v)", entries, err)
+		}
+		if entries[0].CreatedAt.IsZero() {
+			t.Fatalf("entry has no timestamp: %+v", entries[0])
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits. Tests use a FakeClock to control both.
+type Clock interface {
+	Now() time.Time
+	// Timer returns a channel that receives once d has passed, and a function that stops it
+	Timer(d time.Duration) (<-chan time.Time, func())
+}
+
+// SystemClock is the real clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Timer(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTimer(d)
+	return t.C, func() { t.Stop() }
+}
+
+// FakeClock is a Clock that only moves when Advance or Set is called
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters map[*fakeTimer]bool
+	changed chan struct{} // Closed and replaced whenever a timer starts or stops
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock returns a fake clock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, waiters: make(map[*fakeTimer]bool), changed: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Timer(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+	} else {
+		c.waiters[t] = true
+		c.notify()
+	}
+	return t.ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.waiters[t] {
+			delete(c.waiters, t)
+			c.notify()
+		}
+	}
+}
+
+// Advance moves the clock forward by d and fires the timers that are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to now and fires the timers that are due
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	for t := range c.waiters {
+		if !t.at.After(now) {
+			t.ch <- now
+			delete(c.waiters, t)
+		}
+	}
+	c.notify()
+}
+
+// WaitForTimers blocks until n timers are waiting, so a test can advance the clock once
+// the code under test is waiting for it. It gives up after a second of real time and
+// reports whether n timers were reached.
+func (c *FakeClock) WaitForTimers(n int) bool {
+	deadline := time.After(time.Second)
+	for {
+		c.mu.Lock()
+		waiting, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if waiting >= n {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+// notify wakes WaitForTimers; c.mu must be held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
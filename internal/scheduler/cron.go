@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a job is due
+type Schedule interface {
+	// Next returns the first time after t the job is due, or the zero time if it never is
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Every returns a schedule that is due every d, counting from the previous run
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+func (e every) String() string {
+	return "every " + formatDuration(time.Duration(e))
+}
+
+// formatDuration formats d without trailing zero units, e.g. 5m instead of 5m0s
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// descriptors are the cron shorthands ParseCron accepts besides @every
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField is one of the five fields of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	// 7 is Sunday as well as 0
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// cron is a schedule parsed from a cron expression. Each field is a bit set of the values
+// it matches.
+type cron struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	// With both day fields restricted, a day matching either one is due, as in cron(8)
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+// ParseCron parses a standard five-field cron expression (minute, hour, day of month, month,
+// day of week) evaluated in loc, e.g. "0 9 * * *" for 09:00 every day. Fields take *, values,
+// ranges (1-5), steps (*/15, 0-30/10), lists (1,15) and month and weekday names. The
+// shorthands @hourly, @daily, @weekly, @monthly, @yearly and @every <duration> work too.
+func ParseCron(expr string, loc *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: @every needs a duration of at least 1s", expr)
+		}
+		return Every(interval), nil
+	}
+	fieldsExpr := expr
+	if strings.HasPrefix(expr, "@") {
+		var ok bool
+		if fieldsExpr, ok = descriptors[strings.ToLower(expr)]; !ok {
+			return nil, fmt.Errorf("invalid schedule %q: unknown shorthand", expr)
+		}
+	}
+
+	fields := strings.Fields(fieldsExpr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid schedule %q: want 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	dow := sets[4]
+	if dow&(1<<7) != 0 {
+		dow = dow&^(1<<7) | 1
+	}
+	return &cron{
+		expr:   expr,
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: dow,
+		domAny: strings.HasPrefix(fields[2], "*"),
+		dowAny: strings.HasPrefix(fields[4], "*"),
+		loc:    loc,
+	}, nil
+}
+
+// parseCronField parses one field into the bit set of the values it matches
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(from, f); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = cronValue(to, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end in steps of 15
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// cronValue parses a number or name in a field and checks its range
+func cronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q, want %d-%d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// maxSearch bounds how far ahead Next looks, for expressions like "0 0 31 2 *" that never match
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for t.Before(limit) {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		case !has(c.hour, t.Hour()):
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+			if next.Day() == t.Day() && next.Hour() != t.Hour()+1 && has(c.hour, t.Hour()+1) {
+				// The clocks go forward over the due hour, so the runs in it happen as they do
+				return next
+			}
+			t = next
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (c *cron) String() string {
+	return c.expr
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,397 @@
+// Package scheduler runs background jobs on cron or interval schedules. A run is skipped
+// while the previous one is still going, and runs missed while the process was down are
+// caught up once it is back, using a History that instances can share. A job with no known
+// earlier run waits until it is due, unless it asks to run at start.
+package scheduler
+
+import (
+	"GoopBot/internal/logging"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrUnknownJob is returned for a job name that was never added
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrRunning is returned by RunNow while the job is still running
+	ErrRunning = errors.New("the job is already running")
+	// ErrStopped is returned by RunNow once runs can no longer be started
+	ErrStopped = errors.New("the scheduler is shutting down")
+)
+
+// Job is work that runs on a schedule
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// InitialDelay is how long to wait after Start before the job may first run
+	InitialDelay time.Duration
+	// Jitter delays each scheduled run by a random duration of up to this, so instances
+	// and jobs don't all hit an API at the same moment
+	Jitter time.Duration
+	// RunAtStart runs the job as soon as it may, once InitialDelay has passed, when the
+	// history knows of no earlier run. Without it such a job waits until the schedule is
+	// next due, so e.g. a daily job doesn't run at whatever time the process started.
+	RunAtStart bool
+	// Active reports whether this instance runs the job right now, e.g. while it holds the
+	// job's lease; scheduled runs are skipped while it returns false. Nil means always.
+	Active func() bool
+	Run    func(ctx context.Context)
+}
+
+// Status is what the scheduler knows about a job
+type Status struct {
+	Name     string
+	Schedule string
+	Active   bool
+	Running  bool
+	LastRun  time.Time     // When the job last started, on any instance sharing the history
+	Duration time.Duration // How long the job's last run on this instance took
+	NextRun  time.Time     // When the job is due next; zero before Start
+	Runs     int           // Runs on this instance, including those started with RunNow
+	Skipped  int           // Scheduled runs skipped because the previous run was still going
+}
+
+// History remembers when each job last started, so a job that was due while no instance
+// was running is caught up. Share it between instances to share that knowledge.
+type History interface {
+	// LastRun returns when the job last started, or the zero time if it is unknown
+	LastRun(ctx context.Context, job string) (time.Time, error)
+	SetLastRun(ctx context.Context, job string, t time.Time) error
+}
+
+// MemoryHistory is a History in process memory, forgotten on restart
+type MemoryHistory struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+// NewMemoryHistory returns an empty in-memory history
+func NewMemoryHistory() *MemoryHistory {
+	return &MemoryHistory{runs: make(map[string]time.Time)}
+}
+
+func (h *MemoryHistory) LastRun(_ context.Context, job string) (time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.runs[job], nil
+}
+
+func (h *MemoryHistory) SetLastRun(_ context.Context, job string, t time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.runs[job] = t
+	return nil
+}
+
+// Options configure a Scheduler. Every field is optional.
+type Options struct {
+	Clock   Clock   // Defaults to SystemClock
+	History History // Defaults to a MemoryHistory
+	Logger  logging.Logger
+	// Context is passed to every run, so an in-flight run can outlive the context given
+	// to Start during shutdown. Defaults to context.Background().
+	Context context.Context
+	// Go runs fn in a goroutine and reports whether it did, so the caller can track and
+	// refuse work. Defaults to a plain go statement.
+	Go func(fn func()) bool
+}
+
+// Scheduler runs jobs on their schedules
+type Scheduler struct {
+	clock   Clock
+	history History
+	log     logging.Logger
+	ctx     context.Context
+	goFn    func(fn func()) bool
+
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+}
+
+// job is a Job with its run state
+type job struct {
+	Job
+	trigger chan struct{} // Asks the job's loop to catch up
+	running atomic.Bool
+
+	mu        sync.Mutex
+	next      time.Time
+	lastStart time.Time
+	duration  time.Duration
+	runs      int
+	skipped   int
+}
+
+// New returns a scheduler without jobs
+func New(opts Options) *Scheduler {
+	s := &Scheduler{clock: opts.Clock, history: opts.History, log: logging.OrNop(opts.Logger), ctx: opts.Context, goFn: opts.Go}
+	if s.clock == nil {
+		s.clock = SystemClock
+	}
+	if s.history == nil {
+		s.history = NewMemoryHistory()
+	}
+	if s.ctx == nil {
+		s.ctx = context.Background()
+	}
+	if s.goFn == nil {
+		s.goFn = func(fn func()) bool {
+			go fn()
+			return true
+		}
+	}
+	return s
+}
+
+// Add adds a job. Jobs must be added before Start.
+func (s *Scheduler) Add(j Job) error {
+	if j.Name == "" || j.Schedule == nil || j.Run == nil {
+		return fmt.Errorf("job %q needs a name, a schedule and a run function", j.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("failed to add job %s: the scheduler has started", j.Name)
+	}
+	for _, existing := range s.jobs {
+		if strings.EqualFold(existing.Name, j.Name) {
+			return fmt.Errorf("job %q is added twice", j.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &job{Job: j, trigger: make(chan struct{}, 1)})
+	return nil
+}
+
+// Names returns the names of the jobs, in the order they were added
+func (s *Scheduler) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.jobs))
+	for i, j := range s.jobs {
+		names[i] = j.Name
+	}
+	return names
+}
+
+// Start runs every job on its schedule until ctx is cancelled. Each job first waits for
+// its initial delay, then runs straight away if it missed a run, going by the history, or
+// if it never ran and runs at start.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		if s.goFn(func() { s.loop(ctx, j) }) {
+			s.log.With("job", j.Name).Infof("Scheduled %s (%s)", j.Name, j.Schedule)
+		}
+	}
+}
+
+// loop waits for a job's runs until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	log := s.log.With("job", j.Name)
+	if j.InitialDelay > 0 {
+		j.setNext(s.clock.Now().Add(j.InitialDelay))
+		timer, stop := s.clock.Timer(j.InitialDelay)
+		select {
+		case <-ctx.Done():
+			stop()
+			return
+		case <-timer:
+		}
+	}
+
+	next := s.catchUp(ctx, j)
+	for {
+		j.setNext(next)
+		if next.IsZero() {
+			log.Warnf("%s (%s) is never due", j.Name, j.Schedule)
+			<-ctx.Done()
+			return
+		}
+
+		timer, stop := s.clock.Timer(next.Sub(s.clock.Now()))
+		select {
+		case <-ctx.Done():
+			stop()
+			log.Infof("Stopped %s", j.Name)
+			return
+		case <-timer:
+			s.fire(j)
+			next = s.nextRun(j, s.clock.Now())
+		case <-j.trigger:
+			stop()
+			next = s.catchUp(ctx, j)
+		}
+	}
+}
+
+// catchUp runs a job if it missed a run since it last started anywhere, and returns when
+// it is due next. A job that never ran, as far as the history knows, only counts as
+// missed if it runs at start.
+func (s *Scheduler) catchUp(ctx context.Context, j *job) time.Time {
+	now := s.clock.Now()
+	last, err := s.history.LastRun(ctx, j.Name)
+	if err != nil {
+		s.log.With("job", j.Name, logging.KeyError, err).Warnf("Failed to read when %s last ran", j.Name)
+	}
+	if local := j.lastRun(); local.After(last) {
+		last = local
+	}
+
+	switch {
+	case last.IsZero() && !j.RunAtStart:
+		return s.nextRun(j, now)
+	case !last.IsZero():
+		due := j.Schedule.Next(last)
+		if due.After(now) {
+			return due
+		}
+		s.log.With("job", j.Name).Infof("Catching up on %s, last run %s ago", j.Name, now.Sub(last).Round(time.Second))
+	}
+	s.fire(j)
+	return s.nextRun(j, now)
+}
+
+// nextRun returns when a job is due next after now, with jitter
+func (s *Scheduler) nextRun(j *job, now time.Time) time.Time {
+	next := j.Schedule.Next(now)
+	if !next.IsZero() && j.Jitter > 0 {
+		next = next.Add(rand.N(j.Jitter))
+	}
+	return next
+}
+
+// fire starts a scheduled run unless another instance runs the job or it is still running
+func (s *Scheduler) fire(j *job) {
+	log := s.log.With("job", j.Name)
+	if j.Active != nil && !j.Active() {
+		log.Debugf("Skipping %s, another instance runs it", j.Name)
+		return
+	}
+	if err := s.start(j); errors.Is(err, ErrRunning) {
+		j.mu.Lock()
+		j.skipped++
+		j.mu.Unlock()
+		log.Warnf("Skipping %s, the previous run is still going", j.Name)
+	}
+}
+
+// start runs a job in the background unless it is already running
+func (s *Scheduler) start(j *job) error {
+	if !j.running.CompareAndSwap(false, true) {
+		return ErrRunning
+	}
+	start := s.clock.Now()
+	j.mu.Lock()
+	previous := j.lastStart
+	j.lastStart = start
+	j.mu.Unlock()
+
+	started := s.goFn(func() {
+		defer j.running.Store(false)
+		if err := s.history.SetLastRun(s.ctx, j.Name, start); err != nil {
+			s.log.With("job", j.Name, logging.KeyError, err).Warnf("Failed to record the run of %s", j.Name)
+		}
+
+		j.Run(s.ctx)
+
+		j.mu.Lock()
+		j.duration = s.clock.Now().Sub(start)
+		j.runs++
+		j.mu.Unlock()
+	})
+	if !started {
+		j.mu.Lock()
+		j.lastStart = previous
+		j.mu.Unlock()
+		j.running.Store(false)
+		return ErrStopped
+	}
+	return nil
+}
+
+// RunNow starts a run of the job straight away, outside its schedule and whether or not
+// this instance is active for it. It fails with ErrRunning while the job is running.
+func (s *Scheduler) RunNow(name string) error {
+	j := s.find(name)
+	if j == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return s.start(j)
+}
+
+// CatchUp asks a job to run straight away if it missed a run, e.g. after this instance
+// took it over from another one
+func (s *Scheduler) CatchUp(name string) {
+	if j := s.find(name); j != nil {
+		select {
+		case j.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Status returns the status of every job, in the order they were added
+func (s *Scheduler) Status(ctx context.Context) []Status {
+	s.mu.Lock()
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	statuses := make([]Status, len(jobs))
+	for i, j := range jobs {
+		last, err := s.history.LastRun(ctx, j.Name)
+		if err != nil {
+			s.log.With("job", j.Name, logging.KeyError, err).Warnf("Failed to read when %s last ran", j.Name)
+		}
+		j.mu.Lock()
+		if j.lastStart.After(last) {
+			last = j.lastStart
+		}
+		statuses[i] = Status{
+			Name:     j.Name,
+			Schedule: j.Schedule.String(),
+			Active:   j.Active == nil || j.Active(),
+			Running:  j.running.Load(),
+			LastRun:  last,
+			Duration: j.duration,
+			NextRun:  j.next,
+			Runs:     j.runs,
+			Skipped:  j.skipped,
+		}
+		j.mu.Unlock()
+	}
+	return statuses
+}
+
+func (s *Scheduler) find(name string) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if strings.EqualFold(j.Name, name) {
+			return j
+		}
+	}
+	return nil
+}
+
+func (j *job) setNext(t time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.next = t
+}
+
+func (j *job) lastRun() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastStart
+}
@@ -0,0 +1,218 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	utc := func(s string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("bad test time %q: %v", s, err)
+		}
+		return parsed
+	}
+
+	for _, tc := range []struct {
+		expr string
+		loc  *time.Location
+		from string
+		want string // Empty when the schedule is never due
+	}{
+		{"0 9 * * *", time.UTC, "2024-06-01T08:59:30Z", "2024-06-01T09:00:00Z"},
+		{"0 9 * * *", time.UTC, "2024-06-01T09:00:00Z", "2024-06-02T09:00:00Z"},
+		{"*/15 * * * *", time.UTC, "2024-06-01T12:07:00Z", "2024-06-01T12:15:00Z"},
+		{"30 8 * * mon-fri", time.UTC, "2024-06-01T10:00:00Z", "2024-06-03T08:30:00Z"}, // From a Saturday
+		{"0 0 * * 7", time.UTC, "2024-06-01T10:00:00Z", "2024-06-02T00:00:00Z"},        // 7 is Sunday
+		{"0 12 13 * fri", time.UTC, "2024-06-01T00:00:00Z", "2024-06-07T12:00:00Z"},    // Either day field matches
+		{"0 0 1 jan,jul *", time.UTC, "2024-06-01T00:00:00Z", "2024-07-01T00:00:00Z"},
+		{"5/20 * * * *", time.UTC, "2024-06-01T12:46:00Z", "2024-06-01T13:05:00Z"},
+		{"@daily", time.UTC, "2024-06-01T12:00:00Z", "2024-06-02T00:00:00Z"},
+		{"@every 90m", time.UTC, "2024-06-01T12:00:00Z", "2024-06-01T13:30:00Z"},
+		{"0 0 30 2 *", time.UTC, "2024-06-01T12:00:00Z", ""},
+		// 09:00 in Berlin is 07:00 UTC in summer and 08:00 UTC in winter
+		{"0 9 * * *", berlin, "2024-06-01T12:00:00Z", "2024-06-02T07:00:00Z"},
+		{"0 9 * * *", berlin, "2024-12-01T12:00:00Z", "2024-12-02T08:00:00Z"},
+		// 02:30 doesn't exist on the night clocks go forward, so it runs at 03:00
+		{"30 2 * * *", berlin, "2024-03-30T12:00:00Z", "2024-03-31T01:00:00Z"},
+	} {
+		schedule, err := ParseCron(tc.expr, tc.loc)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tc.expr, err)
+		}
+		got := schedule.Next(utc(tc.from))
+		if tc.want == "" {
+			if !got.IsZero() {
+				t.Errorf("%q from %s: got %s, want never", tc.expr, tc.from, got)
+			}
+			continue
+		}
+		if want := utc(tc.want); !got.Equal(want) {
+			t.Errorf("%q in %s from %s: got %s, want %s", tc.expr, tc.loc, tc.from, got.UTC(), want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "@fortnightly", "@every 1ms", "0 9 * * someday"} {
+		if _, err := ParseCron(expr, time.UTC); err == nil {
+			t.Errorf("ParseCron(%q) accepted an invalid expression", expr)
+		}
+	}
+	if s, _ := ParseCron("@every 24h", time.UTC); s.String() != "every 24h" {
+		t.Errorf("got %q, want \"every 24h\"", s)
+	}
+}
+
+// counter is a job run that counts its runs and blocks until released
+type counter struct {
+	runs    atomic.Int32
+	release chan struct{}
+}
+
+func (c *counter) run(context.Context) {
+	c.runs.Add(1)
+	if c.release != nil {
+		<-c.release
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestSchedulerCatchesUpMissedRuns(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	history := NewMemoryHistory()
+	daily, _ := ParseCron("0 9 * * *", time.UTC)
+
+	// The job last ran yesterday at 09:00, so today's 09:00 run was missed while the bot was down
+	history.SetLastRun(context.Background(), "birthdays", time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC))
+	c := &counter{}
+	s := New(Options{Clock: clock, History: history})
+	if err := s.Add(Job{Name: "birthdays", Schedule: daily, Run: c.run}); err != nil {
+		t.Fatalf("failed to add job: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	waitFor(t, "the missed run", func() bool { return c.runs.Load() == 1 })
+
+	// Then it waits for tomorrow's run
+	if !clock.WaitForTimers(1) {
+		t.Fatalf("the job isn't waiting for its next run")
+	}
+	if next := s.Status(ctx)[0].NextRun; !next.Equal(time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next run %s, want tomorrow at 09:00", next)
+	}
+	clock.Set(time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC))
+	waitFor(t, "the scheduled run", func() bool { return c.runs.Load() == 2 })
+	if last, _ := history.LastRun(ctx, "birthdays"); !last.Equal(clock.Now()) {
+		t.Fatalf("history has last run %s, want %s", last, clock.Now())
+	}
+
+	// A restarted scheduler sharing the history doesn't run the job again
+	restarted := New(Options{Clock: clock, History: history})
+	again := &counter{}
+	restarted.Add(Job{Name: "birthdays", Schedule: daily, Run: again.run})
+	restarted.Start(ctx)
+	if !clock.WaitForTimers(2) {
+		t.Fatalf("the restarted job isn't waiting for its next run")
+	}
+	if runs := again.runs.Load(); runs != 0 {
+		t.Fatalf("the restarted scheduler ran the job %d times", runs)
+	}
+}
+
+func TestSchedulerWaitsForJobsThatNeverRan(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	daily, _ := ParseCron("0 9 * * *", time.UTC)
+	birthdays, streams := &counter{}, &counter{}
+	s := New(Options{Clock: clock})
+	s.Add(Job{Name: "birthdays", Schedule: daily, Run: birthdays.run})
+	s.Add(Job{Name: "streams", Schedule: Every(time.Minute), RunAtStart: true, Run: streams.run})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	// Without a known last run, only the job that runs at start runs before it is due
+	waitFor(t, "the job that runs at start", func() bool { return streams.runs.Load() == 1 })
+	if !clock.WaitForTimers(2) {
+		t.Fatalf("the jobs aren't waiting for their next runs")
+	}
+	if runs := birthdays.runs.Load(); runs != 0 {
+		t.Fatalf("the daily job ran %d times at start", runs)
+	}
+	if next := s.Status(ctx)[0].NextRun; !next.Equal(time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next run %s, want tomorrow at 09:00", next)
+	}
+}
+
+func TestSchedulerPreventsOverlap(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	c := &counter{release: make(chan struct{})}
+	s := New(Options{Clock: clock})
+	s.Add(Job{Name: "streams", Schedule: Every(time.Minute), RunAtStart: true, Run: c.run})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	waitFor(t, "the first run", func() bool { return c.runs.Load() == 1 })
+
+	// The first run is still going when the next one is due
+	clock.WaitForTimers(1)
+	clock.Advance(time.Minute)
+	waitFor(t, "the overlapping run to be skipped", func() bool { return s.Status(ctx)[0].Skipped == 1 })
+	if err := s.RunNow("STREAMS"); !errors.Is(err, ErrRunning) {
+		t.Fatalf("RunNow while running: got %v, want ErrRunning", err)
+	}
+	if runs := c.runs.Load(); runs != 1 {
+		t.Fatalf("the job ran %d times at once", runs)
+	}
+
+	c.release <- struct{}{}
+	waitFor(t, "the first run to finish", func() bool { return !s.Status(ctx)[0].Running })
+	close(c.release)
+	if err := s.RunNow("streams"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	waitFor(t, "the manual run", func() bool { return c.runs.Load() == 2 })
+	if err := s.RunNow("nosuchjob"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("RunNow of an unknown job: got %v", err)
+	}
+}
+
+func TestSchedulerSkipsInactiveJobs(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	var active atomic.Bool
+	c := &counter{}
+	s := New(Options{Clock: clock})
+	s.Add(Job{Name: "streams", Schedule: Every(time.Minute), InitialDelay: 10 * time.Second, RunAtStart: true, Active: active.Load, Run: c.run})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+
+	clock.WaitForTimers(1)
+	clock.Advance(10 * time.Second)
+	clock.WaitForTimers(1)
+	if runs := c.runs.Load(); runs != 0 {
+		t.Fatalf("an inactive job ran %d times", runs)
+	}
+
+	// Taking the job over runs it straight away, since it never ran
+	active.Store(true)
+	s.CatchUp("streams")
+	waitFor(t, "the job to catch up", func() bool { return c.runs.Load() == 1 })
+}
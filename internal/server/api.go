@@ -0,0 +1,591 @@
+package server
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxRequestBody caps the size of API request bodies
+const maxRequestBody = 1 << 20
+
+// How many audit entries the API returns by default and at most
+const (
+	defaultAuditEntries = 50
+	maxAuditEntries     = 500
+)
+
+// apiError is an error with the HTTP status to answer it with
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// badRequest returns an error answered with 400 Bad Request
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// errNotInGuild hides records of other guilds, as if they did not exist
+var errNotInGuild = &apiError{status: http.StatusNotFound, message: "record not found"}
+
+// errInOtherGuild refuses to overwrite a member's record that another guild holds, since
+// a member has one linked account and one birthday across all guilds
+var errInOtherGuild = &apiError{status: http.StatusConflict, message: "the member's record belongs to another guild"}
+
+// existingBefore describes the record a PUT replaces for the audit log, or "" if there is
+// none. A record of another guild is refused rather than moved to the path's guild.
+func existingBefore[T any](record T, err error, describe func(T) string) (string, error) {
+	switch {
+	case err == nil:
+		return describe(record), nil
+	case errors.Is(err, errNotInGuild):
+		return "", errInOtherGuild
+	case errors.Is(err, repository.ErrNotFound):
+		return "", nil
+	}
+	return "", err
+}
+
+// apiHandler handles an API request. Handlers write their response on success; returned
+// errors are turned into a JSON error response.
+type apiHandler func(w http.ResponseWriter, r *http.Request) error
+
+// apiRoutes adds the admin API to mux. Every route requires the bearer token.
+func (s *Server) apiRoutes(mux *http.ServeMux, token string) {
+	handle := func(pattern string, h apiHandler) {
+		mux.Handle(pattern, s.authorize(token, h))
+	}
+
+	handle("GET /api/jobs", s.listJobs)
+	handle("POST /api/jobs/{job}/run", s.runJob)
+
+	handle("GET /api/guilds/{guild}/creators", s.listCreators)
+	handle("GET /api/guilds/{guild}/creators/{user}", s.getCreator)
+	handle("PUT /api/guilds/{guild}/creators/{user}", s.putCreator)
+	handle("DELETE /api/guilds/{guild}/creators/{user}", s.deleteCreator)
+
+	handle("GET /api/guilds/{guild}/notification-channels", s.listNotificationChannels)
+	handle("PUT /api/guilds/{guild}/notification-channels/{channel}", s.putNotificationChannel)
+	handle("DELETE /api/guilds/{guild}/notification-channels/{channel}", s.deleteNotificationChannel)
+
+	handle("GET /api/guilds/{guild}/birthdays", s.listBirthdays)
+	handle("GET /api/guilds/{guild}/birthdays/{user}", s.getBirthday)
+	handle("PUT /api/guilds/{guild}/birthdays/{user}", s.putBirthday)
+	handle("DELETE /api/guilds/{guild}/birthdays/{user}", s.deleteBirthday)
+
+	handle("GET /api/guilds/{guild}/birthday-channel", s.getBirthdayChannel)
+	handle("PUT /api/guilds/{guild}/birthday-channel", s.putBirthdayChannel)
+	handle("DELETE /api/guilds/{guild}/birthday-channel", s.deleteBirthdayChannel)
+
+	handle("GET /api/guilds/{guild}/role-messages", s.listRoleMessages)
+	handle("GET /api/guilds/{guild}/role-messages/{message}", s.getRoleMessage)
+	handle("PUT /api/guilds/{guild}/role-messages/{message}", s.putRoleMessage)
+	handle("DELETE /api/guilds/{guild}/role-messages/{message}", s.deleteRoleMessage)
+
+	handle("GET /api/guilds/{guild}/audit-log", s.listAuditLog)
+
+	handle("GET /api/guilds/{guild}/users/{user}/data", s.getUserData)
+	handle("DELETE /api/guilds/{guild}/users/{user}/data", s.deleteUserData)
+}
+
+// authorize rejects requests without the bearer token, checks the Discord IDs in the path
+// and renders the handler's errors
+func (s *Server) authorize(token string, h apiHandler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="goopbot"`)
+			writeJSON(w, http.StatusUnauthorized, errorBody{Error: "missing or invalid API token"})
+			return
+		}
+
+		log := s.log.With("method", r.Method, "path", r.URL.Path)
+		if r.Method != http.MethodGet {
+			log.Infof("API %s %s", r.Method, r.URL.Path)
+		}
+
+		err := checkIDs(r)
+		if err == nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+			err = h(w, r)
+		}
+		if err == nil {
+			return
+		}
+
+		var apiErr *apiError
+		switch {
+		case errors.As(err, &apiErr):
+			writeJSON(w, apiErr.status, errorBody{Error: apiErr.message})
+		case errors.Is(err, repository.ErrNotFound), errors.Is(err, bot.ErrUnknownJob):
+			writeJSON(w, http.StatusNotFound, errorBody{Error: err.Error()})
+		case errors.Is(err, bot.ErrJobRunning):
+			writeJSON(w, http.StatusConflict, errorBody{Error: err.Error()})
+		default:
+			log.With(logging.KeyError, err).Errorf("API request failed")
+			writeJSON(w, http.StatusInternalServerError, errorBody{Error: "internal error"})
+		}
+	})
+}
+
+// checkIDs rejects path values that can't be Discord IDs
+func checkIDs(r *http.Request) error {
+	for _, name := range []string{"guild", "user", "channel", "message"} {
+		if id := r.PathValue(name); id != "" && !isSnowflake(id) {
+			return badRequest("%s must be a Discord ID, got %q", name, id)
+		}
+	}
+	return nil
+}
+
+// isSnowflake reports whether id looks like a Discord ID
+func isSnowflake(id string) bool {
+	if len(id) > 20 {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes v as the response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decode reads a JSON request body into v, rejecting unknown fields so typos don't go unnoticed
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// required reports the first empty field by its JSON name
+func required(fields ...string) error {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if strings.TrimSpace(fields[i+1]) == "" {
+			return badRequest("%s is required", fields[i])
+		}
+	}
+	return nil
+}
+
+// audit records a change made through the API in the path guild's audit log
+func (s *Server) audit(r *http.Request, action, before, after string) {
+	s.bot.Audit(r.Context(), models.AuditEntry{
+		GuildID:   r.PathValue("guild"),
+		ActorName: "admin API",
+		Action:    action,
+		Before:    before,
+		After:     after,
+		Via:       bot.AuditViaAPI,
+	})
+}
+
+// list renders records as a JSON array, which is empty rather than null when there are none
+func list[T any](w http.ResponseWriter, records []T, err error) error {
+	if err != nil {
+		return err
+	}
+	if records == nil {
+		records = []T{}
+	}
+	writeJSON(w, http.StatusOK, records)
+	return nil
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, _ *http.Request) error {
+	return list(w, s.bot.JobNames(), nil)
+}
+
+// runJob starts a job such as "stream monitoring" or "birthday monitoring". Dashes in the
+// path stand for spaces, so /api/jobs/stream-monitoring/run works without escaping.
+func (s *Server) runJob(w http.ResponseWriter, r *http.Request) error {
+	name := strings.ReplaceAll(r.PathValue("job"), "-", " ")
+	if err := s.bot.RunJob(name); err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"job": name, "status": "started"})
+	return nil
+}
+
+// Creators
+
+type creatorRequest struct {
+	Username       string `json:"username"`
+	TwitchUsername string `json:"twitch_username"`
+}
+
+func (s *Server) listCreators(w http.ResponseWriter, r *http.Request) error {
+	creators, err := s.bot.Store().Creators.ListByGuild(r.Context(), r.PathValue("guild"))
+	return list(w, creators, err)
+}
+
+// creator returns the creator named in the path if it belongs to the path's guild
+func (s *Server) creator(r *http.Request) (models.GoopCreator, error) {
+	creator, err := s.bot.Store().Creators.Get(r.Context(), r.PathValue("user"))
+	if err == nil && creator.GuildID != r.PathValue("guild") {
+		err = errNotInGuild
+	}
+	return creator, err
+}
+
+func (s *Server) getCreator(w http.ResponseWriter, r *http.Request) error {
+	creator, err := s.creator(r)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, creator)
+	return nil
+}
+
+func (s *Server) putCreator(w http.ResponseWriter, r *http.Request) error {
+	var req creatorRequest
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	if err := required("twitch_username", req.TwitchUsername); err != nil {
+		return err
+	}
+	existing, err := s.creator(r)
+	before, err := existingBefore(existing, err, func(c models.GoopCreator) string {
+		return describeMember(c.DiscordID)
+	})
+	if err != nil {
+		return err
+	}
+	err = s.bot.Store().Creators.Link(r.Context(), models.GoopCreator{
+		DiscordID:      r.PathValue("user"),
+		Username:       req.Username,
+		GuildID:        r.PathValue("guild"),
+		TwitchUsername: req.TwitchUsername,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link Twitch account: %w", err)
+	}
+	s.audit(r, "link Twitch account", before, describeMember(r.PathValue("user")))
+	return s.getCreator(w, r)
+}
+
+func (s *Server) deleteCreator(w http.ResponseWriter, r *http.Request) error {
+	creator, err := s.creator(r)
+	if err != nil {
+		return err
+	}
+	if err := s.bot.Store().Creators.Unlink(r.Context(), creator.DiscordID); err != nil {
+		return fmt.Errorf("failed to unlink Twitch account: %w", err)
+	}
+	s.audit(r, "unlink Twitch account", describeMember(creator.DiscordID), "")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Notification channels
+
+func (s *Server) listNotificationChannels(w http.ResponseWriter, r *http.Request) error {
+	channels, err := s.bot.Store().NotificationChannels.ListActive(r.Context(), r.PathValue("guild"))
+	return list(w, channels, err)
+}
+
+func (s *Server) putNotificationChannel(w http.ResponseWriter, r *http.Request) error {
+	store := s.bot.Store().NotificationChannels
+	previous, err := store.ListActive(r.Context(), r.PathValue("guild"))
+	if err != nil {
+		return fmt.Errorf("failed to get notification channels: %w", err)
+	}
+	if err := store.Activate(r.Context(), r.PathValue("guild"), r.PathValue("channel")); err != nil {
+		return fmt.Errorf("failed to set notification channel: %w", err)
+	}
+
+	var before []string
+	for _, c := range previous {
+		before = append(before, mention(c.ChannelID))
+	}
+	s.audit(r, "set notification channel", strings.Join(before, ", "), mention(r.PathValue("channel")))
+	return s.listNotificationChannels(w, r)
+}
+
+func (s *Server) deleteNotificationChannel(w http.ResponseWriter, r *http.Request) error {
+	if err := s.bot.Store().NotificationChannels.Deactivate(r.Context(), r.PathValue("guild"), r.PathValue("channel")); err != nil {
+		return err
+	}
+	s.audit(r, "remove notification channel", mention(r.PathValue("channel")), "")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Birthdays
+
+type birthdayRequest struct {
+	Username string `json:"username"`
+	Month    int    `json:"month"`
+	Day      int    `json:"day"`
+	Year     *int   `json:"year"`
+}
+
+func (s *Server) listBirthdays(w http.ResponseWriter, r *http.Request) error {
+	birthdays, err := s.bot.Store().Birthdays.ListByGuild(r.Context(), r.PathValue("guild"))
+	return list(w, birthdays, err)
+}
+
+// birthday returns the birthday named in the path if it belongs to the path's guild
+func (s *Server) birthday(r *http.Request) (models.Birthday, error) {
+	birthday, err := s.bot.Store().Birthdays.Get(r.Context(), r.PathValue("user"))
+	if err == nil && birthday.GuildID != r.PathValue("guild") {
+		err = errNotInGuild
+	}
+	return birthday, err
+}
+
+func (s *Server) getBirthday(w http.ResponseWriter, r *http.Request) error {
+	birthday, err := s.birthday(r)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, birthday)
+	return nil
+}
+
+func (s *Server) putBirthday(w http.ResponseWriter, r *http.Request) error {
+	var req birthdayRequest
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	if req.Month < 1 || req.Month > 12 {
+		return badRequest("month must be 1-12")
+	}
+	if req.Day < 1 || req.Day > 31 {
+		return badRequest("day must be 1-31")
+	}
+	existing, err := s.birthday(r)
+	before, err := existingBefore(existing, err, func(b models.Birthday) string {
+		return describeMember(b.DiscordID)
+	})
+	if err != nil {
+		return err
+	}
+	err = s.bot.Store().Birthdays.Set(r.Context(), models.Birthday{
+		DiscordID: r.PathValue("user"),
+		Username:  req.Username,
+		GuildID:   r.PathValue("guild"),
+		Month:     req.Month,
+		Day:       req.Day,
+		Year:      req.Year,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set birthday: %w", err)
+	}
+	s.audit(r, "set birthday", before, describeMember(r.PathValue("user")))
+	return s.getBirthday(w, r)
+}
+
+func (s *Server) deleteBirthday(w http.ResponseWriter, r *http.Request) error {
+	birthday, err := s.birthday(r)
+	if err != nil {
+		return err
+	}
+	if err := s.bot.Store().Birthdays.Delete(r.Context(), birthday.DiscordID); err != nil {
+		return fmt.Errorf("failed to delete birthday: %w", err)
+	}
+	s.audit(r, "delete birthday", describeMember(birthday.DiscordID), "")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// describeMember names whose record changed for the audit log. The Twitch account or date
+// itself is personal data, so it stays out of the log; forgetting the member anonymizes the mention.
+func describeMember(discordID string) string {
+	return fmt.Sprintf("<@%s>", discordID)
+}
+
+// Birthday channel
+
+type birthdayChannelRequest struct {
+	ChannelID string `json:"channel_id"`
+}
+
+func (s *Server) getBirthdayChannel(w http.ResponseWriter, r *http.Request) error {
+	channel, err := s.bot.Store().BirthdayChannels.Active(r.Context(), r.PathValue("guild"))
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, channel)
+	return nil
+}
+
+func (s *Server) putBirthdayChannel(w http.ResponseWriter, r *http.Request) error {
+	var req birthdayChannelRequest
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	if req.ChannelID == "" || !isSnowflake(req.ChannelID) {
+		return badRequest("channel_id must be a Discord ID")
+	}
+	store := s.bot.Store().BirthdayChannels
+	var before string
+	if previous, err := store.Active(r.Context(), r.PathValue("guild")); err == nil {
+		before = mention(previous.ChannelID)
+	}
+	if err := store.Activate(r.Context(), r.PathValue("guild"), req.ChannelID); err != nil {
+		return fmt.Errorf("failed to set birthday channel: %w", err)
+	}
+	s.audit(r, "set birthday channel", before, mention(req.ChannelID))
+	return s.getBirthdayChannel(w, r)
+}
+
+func (s *Server) deleteBirthdayChannel(w http.ResponseWriter, r *http.Request) error {
+	store := s.bot.Store().BirthdayChannels
+	previous, err := store.Active(r.Context(), r.PathValue("guild"))
+	if err != nil {
+		return err
+	}
+	if err := store.Deactivate(r.Context(), r.PathValue("guild")); err != nil {
+		return err
+	}
+	s.audit(r, "remove birthday channel", mention(previous.ChannelID), "")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Role messages
+
+type roleMessageRequest struct {
+	ChannelID string `json:"channel_id"`
+	RoleName  string `json:"role_name"`
+}
+
+func (s *Server) listRoleMessages(w http.ResponseWriter, r *http.Request) error {
+	msgs, err := s.bot.Store().RoleMessages.ListActive(r.Context(), r.PathValue("guild"))
+	return list(w, msgs, err)
+}
+
+// roleMessage returns the role message named in the path if it belongs to the path's guild
+func (s *Server) roleMessage(r *http.Request) (models.RoleMessage, error) {
+	msg, err := s.bot.Store().RoleMessages.FindActive(r.Context(), r.PathValue("message"))
+	if err == nil && msg.GuildID != r.PathValue("guild") {
+		err = errNotInGuild
+	}
+	return msg, err
+}
+
+func (s *Server) getRoleMessage(w http.ResponseWriter, r *http.Request) error {
+	msg, err := s.roleMessage(r)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, msg)
+	return nil
+}
+
+func (s *Server) putRoleMessage(w http.ResponseWriter, r *http.Request) error {
+	var req roleMessageRequest
+	if err := decode(r, &req); err != nil {
+		return err
+	}
+	if err := required("channel_id", req.ChannelID, "role_name", req.RoleName); err != nil {
+		return err
+	}
+	if !isSnowflake(req.ChannelID) {
+		return badRequest("channel_id must be a Discord ID")
+	}
+	var before string
+	if existing, err := s.roleMessage(r); err == nil {
+		before = describeRoleMessage(existing.MessageID, existing.RoleName)
+	}
+	err := s.bot.Store().RoleMessages.Set(r.Context(), models.RoleMessage{
+		GuildID:   r.PathValue("guild"),
+		ChannelID: req.ChannelID,
+		MessageID: r.PathValue("message"),
+		RoleName:  req.RoleName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set role message: %w", err)
+	}
+	s.audit(r, "set role message", before, describeRoleMessage(r.PathValue("message"), req.RoleName))
+	return s.getRoleMessage(w, r)
+}
+
+func (s *Server) deleteRoleMessage(w http.ResponseWriter, r *http.Request) error {
+	msg, err := s.roleMessage(r)
+	if err != nil {
+		return err
+	}
+	if err := s.bot.Store().RoleMessages.Remove(r.Context(), msg.MessageID); err != nil {
+		return fmt.Errorf("failed to remove role message: %w", err)
+	}
+	s.audit(r, "remove role message", describeRoleMessage(msg.MessageID, msg.RoleName), "")
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// describeRoleMessage describes a role message for the audit log
+func describeRoleMessage(messageID, roleName string) string {
+	return fmt.Sprintf("message %s grants '%s'", messageID, roleName)
+}
+
+// Audit log
+
+// listAuditLog returns the guild's newest audit entries; ?limit= picks how many
+func (s *Server) listAuditLog(w http.ResponseWriter, r *http.Request) error {
+	limit := defaultAuditEntries
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxAuditEntries {
+			return badRequest("limit must be a number from 1 to %d", maxAuditEntries)
+		}
+		limit = n
+	}
+	entries, err := s.bot.Store().AuditLog.ListRecent(r.Context(), r.PathValue("guild"), limit)
+	return list(w, entries, err)
+}
+
+// User data
+
+func (s *Server) getUserData(w http.ResponseWriter, r *http.Request) error {
+	data, err := s.bot.ExportUserData(r.Context(), r.PathValue("user"), r.PathValue("guild"))
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, data)
+	return nil
+}
+
+// deleteUserData permanently deletes what the guild's records hold about a user, for privacy requests
+func (s *Server) deleteUserData(w http.ResponseWriter, r *http.Request) error {
+	deleted, err := s.bot.ForgetUser(r.Context(), r.PathValue("user"), r.PathValue("guild"))
+	if err != nil {
+		return err
+	}
+	if deleted.Len() > 0 {
+		// The forgotten ID itself stays out of the log
+		s.audit(r, "forget user", fmt.Sprintf("%d record(s)", deleted.Len()), "")
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
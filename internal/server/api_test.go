@@ -0,0 +1,212 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+	"GoopBot/internal/features/birthdays"
+	"GoopBot/internal/server"
+)
+
+const apiToken = "test-token-0123456789"
+
+// newAPI returns the routes of a bot with the admin API enabled
+func newAPI(t *testing.T, modules ...bot.Module) http.Handler {
+	t.Helper()
+	cfg := config.Default()
+	cfg.APIToken = apiToken
+	b, _ := bottest.NewWithDeps(t, bot.Deps{Config: cfg}, modules...)
+	return server.New(b, nil).Handler()
+}
+
+// call sends an authenticated API request and decodes a JSON response into out, if given
+func call(t *testing.T, handler http.Handler, method, path, body string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+apiToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	data, _ := io.ReadAll(rec.Body)
+	if out != nil && rec.Code < 300 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, data, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAPINeedsToken(t *testing.T) {
+	handler := newAPI(t)
+
+	for _, auth := range []string{"", "Bearer wrong", apiToken} {
+		req := httptest.NewRequest(http.MethodGet, "/api/guilds/100/creators", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: got %d, want 401", auth, rec.Code)
+		}
+	}
+
+	// Without a configured token there is no API at all
+	b, _ := bottest.New(t)
+	if code, _ := get(t, server.New(b, nil).Handler(), "/api/jobs"); code != http.StatusNotFound {
+		t.Errorf("API without a token: got %d, want 404", code)
+	}
+}
+
+func TestAPICreators(t *testing.T) {
+	handler := newAPI(t)
+
+	var creator struct {
+		DiscordID      string `json:"discord_id"`
+		GuildID        string `json:"guild_id"`
+		TwitchUsername string `json:"twitch_username"`
+		IsActive       bool   `json:"is_active"`
+	}
+	if code := call(t, handler, http.MethodPut, "/api/guilds/100/creators/302", `{"twitch_usrname": "goopy"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("unknown field: got %d, want 400", code)
+	}
+	if code := call(t, handler, http.MethodPut, "/api/guilds/100/creators/302", `{"username": "creator", "twitch_username": "goopy"}`, &creator); code != http.StatusOK {
+		t.Fatalf("put creator: got %d", code)
+	}
+	if creator.DiscordID != "302" || creator.GuildID != "100" || creator.TwitchUsername != "goopy" || !creator.IsActive {
+		t.Fatalf("unexpected creator %+v", creator)
+	}
+
+	var creators []json.RawMessage
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/creators", "", &creators); code != http.StatusOK || len(creators) != 1 {
+		t.Fatalf("list creators: got %d with %d creators", code, len(creators))
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/999/creators", "", &creators); code != http.StatusOK || len(creators) != 0 {
+		t.Fatalf("list another guild's creators: got %d with %d creators", code, len(creators))
+	}
+
+	// Another guild can't take the creator over, see them or remove them
+	if code := call(t, handler, http.MethodPut, "/api/guilds/999/creators/302", `{"twitch_username": "goopy2"}`, nil); code != http.StatusConflict {
+		t.Fatalf("put a creator of another guild: got %d, want 409", code)
+	}
+	if code := call(t, handler, http.MethodDelete, "/api/guilds/999/creators/302", "", nil); code != http.StatusNotFound {
+		t.Fatalf("delete from another guild: got %d, want 404", code)
+	}
+	if code := call(t, handler, http.MethodDelete, "/api/guilds/100/creators/302", "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete creator: got %d, want 204", code)
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/creators/302", "", nil); code != http.StatusNotFound {
+		t.Fatalf("get deleted creator: got %d, want 404", code)
+	}
+
+	if code := call(t, handler, http.MethodGet, "/api/guilds/not-a-guild/creators", "", nil); code != http.StatusBadRequest {
+		t.Fatalf("invalid guild ID: got %d, want 400", code)
+	}
+}
+
+func TestAPIBirthdaysAndJobs(t *testing.T) {
+	handler := newAPI(t, birthdays.New())
+
+	if code := call(t, handler, http.MethodPut, "/api/guilds/100/birthdays/303", `{"month": 13, "day": 1}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("invalid month: got %d, want 400", code)
+	}
+	var birthday struct {
+		Month, Day int
+		Year       *int
+	}
+	if code := call(t, handler, http.MethodPut, "/api/guilds/100/birthdays/303", `{"username": "member", "month": 3, "day": 15, "year": 2000}`, &birthday); code != http.StatusOK {
+		t.Fatalf("put birthday: got %d", code)
+	}
+	if birthday.Month != 3 || birthday.Day != 15 || birthday.Year == nil || *birthday.Year != 2000 {
+		t.Fatalf("unexpected birthday %+v", birthday)
+	}
+	if code := call(t, handler, http.MethodPut, "/api/guilds/999/birthdays/303", `{"month": 4, "day": 1}`, nil); code != http.StatusConflict {
+		t.Fatalf("put a birthday of another guild: got %d, want 409", code)
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/birthdays/303", "", &birthday); code != http.StatusOK || birthday.Month != 3 {
+		t.Fatalf("the birthday moved to another guild: got %d %+v", code, birthday)
+	}
+
+	var channel struct {
+		ChannelID string `json:"channel_id"`
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/birthday-channel", "", nil); code != http.StatusNotFound {
+		t.Fatalf("birthday channel before setting one: got %d, want 404", code)
+	}
+	if code := call(t, handler, http.MethodPut, "/api/guilds/100/birthday-channel", `{"channel_id": "201"}`, &channel); code != http.StatusOK || channel.ChannelID != "201" {
+		t.Fatalf("put birthday channel: got %d %+v", code, channel)
+	}
+	if code := call(t, handler, http.MethodDelete, "/api/guilds/100/birthday-channel", "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete birthday channel: got %d, want 204", code)
+	}
+	if code := call(t, handler, http.MethodDelete, "/api/guilds/100/birthday-channel", "", nil); code != http.StatusNotFound {
+		t.Fatalf("delete a missing birthday channel: got %d, want 404", code)
+	}
+
+	var jobs []string
+	if code := call(t, handler, http.MethodGet, "/api/jobs", "", &jobs); code != http.StatusOK || len(jobs) != 1 || jobs[0] != "birthday monitoring" {
+		t.Fatalf("list jobs: got %d %v", code, jobs)
+	}
+	if code := call(t, handler, http.MethodPost, "/api/jobs/birthday-monitoring/run", "", nil); code != http.StatusAccepted {
+		t.Fatalf("run job: got %d, want 202", code)
+	}
+	if code := call(t, handler, http.MethodPost, "/api/jobs/stream-monitoring/run", "", nil); code != http.StatusNotFound {
+		t.Fatalf("run a job of a module that isn't loaded: got %d, want 404", code)
+	}
+}
+
+func TestAPIUserData(t *testing.T) {
+	handler := newAPI(t)
+
+	if code := call(t, handler, http.MethodPut, "/api/guilds/100/birthdays/303", `{"month": 3, "day": 15}`, nil); code != http.StatusOK {
+		t.Fatalf("put birthday: got %d", code)
+	}
+	var data struct {
+		DiscordID string            `json:"discord_id"`
+		Birthdays []json.RawMessage `json:"birthdays"`
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/users/303/data", "", &data); code != http.StatusOK || data.DiscordID != "303" || len(data.Birthdays) != 1 {
+		t.Fatalf("get user data: got %d %+v", code, data)
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/999/users/303/data", "", &data); code != http.StatusOK || len(data.Birthdays) != 0 {
+		t.Fatalf("another guild's user data: got %d %+v", code, data)
+	}
+
+	if code := call(t, handler, http.MethodDelete, "/api/guilds/100/users/303/data", "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete user data: got %d, want 204", code)
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/birthdays/303", "", nil); code != http.StatusNotFound {
+		t.Fatalf("birthday after deleting user data: got %d, want 404", code)
+	}
+}
+
+func TestAPIAuditLog(t *testing.T) {
+	handler := newAPI(t)
+
+	call(t, handler, http.MethodPut, "/api/guilds/100/notification-channels/200", "", nil)
+	call(t, handler, http.MethodPut, "/api/guilds/100/notification-channels/201", "", nil)
+
+	var entries []struct {
+		ActorName string `json:"actor_name"`
+		Action    string `json:"action"`
+		Before    string `json:"before"`
+		After     string `json:"after"`
+		Via       string `json:"via"`
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/audit-log?limit=1", "", &entries); code != http.StatusOK || len(entries) != 1 {
+		t.Fatalf("audit log: got %d %+v", code, entries)
+	}
+	if e := entries[0]; e.Action != "set notification channel" || e.Before != "<#200>" || e.After != "<#201>" || e.Via != "api" || e.ActorName != "admin API" {
+		t.Fatalf("unexpected audit entry %+v", e)
+	}
+	if code := call(t, handler, http.MethodGet, "/api/guilds/100/audit-log?limit=0", "", nil); code != http.StatusBadRequest {
+		t.Fatalf("invalid limit: got %d, want 400", code)
+	}
+}
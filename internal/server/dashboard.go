@@ -0,0 +1,404 @@
+package server
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/features/birthdays"
+	"GoopBot/internal/features/rolemessages"
+	"GoopBot/internal/features/twitchlive"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"context"
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// dashboardCookie holds the login token of a dashboard session
+const dashboardCookie = "goopbot_dashboard"
+
+// channelOption is a text channel admins can pick in a form
+type channelOption struct {
+	ID   string
+	Name string
+}
+
+var dashboardTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"monthName": func(month int) string { return time.Month(month).String() },
+	"channelPicker": func(channels []channelOption, selected string) map[string]interface{} {
+		return map[string]interface{}{"Channels": channels, "Selected": selected}
+	},
+	"channelName": func(channels []channelOption, id string) string {
+		for _, c := range channels {
+			if c.ID == id {
+				return "#" + c.Name
+			}
+		}
+		return id
+	},
+}).ParseFS(dashboardFiles, "dashboard/*.html"))
+
+// dashboardPage is what the dashboard templates render
+type dashboardPage struct {
+	CSRF  string // Empty when logged out
+	Error string
+	Saved string
+
+	GuildID   string
+	GuildName string
+	Channels  []channelOption
+
+	// Sections for the modules the guild runs
+	Twitch       bool
+	Birthdays    bool
+	RoleMessages bool
+
+	Live                []models.TwitchStream
+	Creators            []models.GoopCreator
+	NotificationChannel string
+	Upcoming            []models.Birthday
+	BirthdayChannel     string
+	RoleMsgs            []models.RoleMessage
+}
+
+// dashboardRoutes adds the web dashboard to mux
+func (s *Server) dashboardRoutes(mux *http.ServeMux) {
+	static, err := fs.Sub(dashboardFiles, "dashboard/static")
+	if err != nil {
+		panic(err)
+	}
+	mux.Handle("GET /dashboard/static/", http.StripPrefix("/dashboard/static/", http.FileServerFS(static)))
+	mux.HandleFunc("GET /dashboard/{$}", s.dashboard)
+	mux.HandleFunc("GET /dashboard/login", s.dashboardLogin)
+	mux.HandleFunc("POST /dashboard/logout", s.dashboardLogout)
+	mux.HandleFunc("POST /dashboard/notification-channel", s.dashboardForm(s.saveNotificationChannel))
+	mux.HandleFunc("POST /dashboard/birthday-channel", s.dashboardForm(s.saveBirthdayChannel))
+}
+
+// csrfToken is the form token of a session, tied to its login so it can't be reused elsewhere
+func csrfToken(loginToken string) string {
+	return bot.HashDashboardToken("csrf:" + loginToken)
+}
+
+// dashboardSession returns the login and login token of the request's session
+func (s *Server) dashboardSession(r *http.Request) (login models.DashboardToken, token string, err error) {
+	cookie, err := r.Cookie(dashboardCookie)
+	if err != nil {
+		return models.DashboardToken{}, "", err
+	}
+	login, err = s.bot.DashboardLogin(r.Context(), cookie.Value)
+	return login, cookie.Value, err
+}
+
+// render writes a dashboard page
+func (s *Server) render(w http.ResponseWriter, status int, name string, page dashboardPage) {
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Content-Security-Policy", "default-src 'self'")
+	h.Set("X-Frame-Options", "DENY")
+	h.Set("Referrer-Policy", "no-referrer")
+	h.Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := dashboardTemplates.ExecuteTemplate(w, name, page); err != nil {
+		s.log.With(logging.KeyError, err).Errorf("Failed to render dashboard page %s", name)
+	}
+}
+
+// dashboard shows the logged in guild, or how to log in
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
+	login, token, err := s.dashboardSession(r)
+	if err != nil {
+		s.render(w, http.StatusOK, "login", dashboardPage{})
+		return
+	}
+
+	page, err := s.guildPage(r.Context(), login.GuildID)
+	if err != nil {
+		s.log.With(logging.KeyGuildID, login.GuildID, logging.KeyError, err).Errorf("Failed to load dashboard")
+		s.render(w, http.StatusInternalServerError, "login", dashboardPage{Error: "Something went wrong loading the dashboard, try again later"})
+		return
+	}
+	page.CSRF = csrfToken(token)
+	page.Saved = savedMessages[r.URL.Query().Get("saved")]
+	s.render(w, http.StatusOK, "guild", page)
+}
+
+// savedMessages confirm a change after the redirect back to the dashboard. The redirect
+// carries a key rather than the text so links can't put arbitrary messages on the page.
+var savedMessages = map[string]string{
+	"notification-channel": "Live notification channel saved",
+	"birthday-channel":     "Birthday channel saved",
+}
+
+// guildPage gathers what the dashboard shows for a guild
+func (s *Server) guildPage(ctx context.Context, guildID string) (dashboardPage, error) {
+	store := s.bot.Store()
+	settings := s.bot.GuildConfig(guildID)
+	runs := func(module string) bool {
+		return slices.Contains(s.bot.Modules(), module) && settings.ModuleEnabled(module)
+	}
+
+	page := dashboardPage{
+		GuildID:      guildID,
+		GuildName:    guildID,
+		Twitch:       runs(twitchlive.Name),
+		Birthdays:    runs(birthdays.Name),
+		RoleMessages: runs(rolemessages.Name),
+	}
+	if guild, err := s.bot.Session().Guild(guildID); err == nil && guild.Name != "" {
+		page.GuildName = guild.Name
+	}
+
+	var err error
+	if page.Channels, err = s.textChannels(guildID); err != nil {
+		return page, err
+	}
+
+	if page.Twitch {
+		if page.Live, err = store.Streams.ListLive(ctx, guildID); err != nil {
+			return page, fmt.Errorf("failed to get live creators: %w", err)
+		}
+		if page.Creators, err = store.Creators.ListByGuild(ctx, guildID); err != nil {
+			return page, fmt.Errorf("failed to get creators: %w", err)
+		}
+		channels, err := store.NotificationChannels.ListActive(ctx, guildID)
+		if err != nil {
+			return page, fmt.Errorf("failed to get notification channels: %w", err)
+		}
+		if len(channels) > 0 {
+			page.NotificationChannel = channels[0].ChannelID
+		}
+	}
+
+	if page.Birthdays {
+		// Upcoming birthdays follow the bot's clock and time zone, like !birthdays
+		now := s.bot.Now()
+		if page.Upcoming, err = store.Birthdays.ListUpcoming(ctx, guildID, int(now.Month()), now.Day()); err != nil {
+			return page, fmt.Errorf("failed to get birthdays: %w", err)
+		}
+		channel, err := store.BirthdayChannels.Active(ctx, guildID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return page, fmt.Errorf("failed to get birthday channel: %w", err)
+		}
+		page.BirthdayChannel = channel.ChannelID
+	}
+
+	if page.RoleMessages {
+		if page.RoleMsgs, err = store.RoleMessages.ListActive(ctx, guildID); err != nil {
+			return page, fmt.Errorf("failed to get role messages: %w", err)
+		}
+	}
+	return page, nil
+}
+
+// textChannels returns the guild's text channels, ordered by name
+func (s *Server) textChannels(guildID string) ([]channelOption, error) {
+	channels, err := s.bot.Session().GuildChannels(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+	var options []channelOption
+	for _, c := range channels {
+		if c.Type != discordgo.ChannelTypeGuildText && c.Type != discordgo.ChannelTypeGuildNews {
+			continue
+		}
+		name := c.Name
+		if name == "" {
+			name = c.ID
+		}
+		options = append(options, channelOption{ID: c.ID, Name: name})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].Name < options[j].Name })
+	return options, nil
+}
+
+// dashboardLogin exchanges the token of a login link for a session cookie
+func (s *Server) dashboardLogin(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	login, err := s.bot.DashboardLogin(r.Context(), token)
+	if err != nil {
+		s.render(w, http.StatusUnauthorized, "login", dashboardPage{Error: "This login link is invalid or has expired"})
+		return
+	}
+	s.log.With(logging.KeyGuildID, login.GuildID, logging.KeyUserID, login.IssuedBy).Infof("Dashboard login")
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     dashboardCookie,
+		Value:    token,
+		Path:     "/dashboard/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil || strings.HasPrefix(s.bot.Config().PublicURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	// Redirect so the token leaves the address bar and browser history
+	http.Redirect(w, r, "/dashboard/", http.StatusSeeOther)
+}
+
+// dashboardAction handles a form posted by a logged in guild admin and returns the
+// savedMessages key to confirm it with
+type dashboardAction func(r *http.Request, login models.DashboardToken) (string, error)
+
+// dashboardForm checks the session and form token of a posted form, runs the action and
+// redirects back to the dashboard. A failed action shows the dashboard with the error.
+func (s *Server) dashboardForm(action dashboardAction) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		login, token, err := s.dashboardSession(r)
+		if err != nil {
+			s.render(w, http.StatusUnauthorized, "login", dashboardPage{Error: "Your session has expired, log in again"})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+		if subtle.ConstantTimeCompare([]byte(r.PostFormValue("csrf")), []byte(csrfToken(token))) != 1 {
+			s.render(w, http.StatusForbidden, "login", dashboardPage{Error: "The form has expired, reload the dashboard and try again"})
+			return
+		}
+
+		guildID := login.GuildID
+		log := s.log.With(logging.KeyGuildID, guildID, logging.KeyUserID, login.IssuedBy)
+		saved, err := action(r, login)
+		if err == nil {
+			log.Infof("Dashboard: %s", savedMessages[saved])
+			http.Redirect(w, r, "/dashboard/?saved="+url.QueryEscape(saved), http.StatusSeeOther)
+			return
+		}
+
+		status := http.StatusBadRequest
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) {
+			log.With(logging.KeyError, err).Errorf("Dashboard change failed")
+			status = http.StatusInternalServerError
+			err = errors.New("Something went wrong saving your change, try again later")
+		}
+		page, loadErr := s.guildPage(r.Context(), guildID)
+		if loadErr != nil {
+			s.render(w, status, "login", dashboardPage{Error: err.Error()})
+			return
+		}
+		page.CSRF = csrfToken(token)
+		page.Error = err.Error()
+		s.render(w, status, "guild", page)
+	}
+}
+
+// formChannel returns the channel picked in a form, checking it belongs to the guild.
+// It is empty when the admin picked none.
+func (s *Server) formChannel(r *http.Request, guildID string) (string, error) {
+	channelID := r.PostFormValue("channel_id")
+	if channelID == "" {
+		return "", nil
+	}
+	channels, err := s.textChannels(guildID)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range channels {
+		if c.ID == channelID {
+			return channelID, nil
+		}
+	}
+	return "", badRequest("That channel is not a text channel of this server")
+}
+
+func (s *Server) saveNotificationChannel(r *http.Request, login models.DashboardToken) (string, error) {
+	guildID := login.GuildID
+	channelID, err := s.formChannel(r, guildID)
+	if err != nil {
+		return "", err
+	}
+	store := s.bot.Store().NotificationChannels
+	channels, err := store.ListActive(r.Context(), guildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get notification channels: %w", err)
+	}
+	var before []string
+	for _, c := range channels {
+		before = append(before, mention(c.ChannelID))
+	}
+
+	if channelID != "" {
+		if err := store.Activate(r.Context(), guildID, channelID); err != nil {
+			return "", fmt.Errorf("failed to set notification channel: %w", err)
+		}
+		s.auditDashboard(r, login, "set notification channel", strings.Join(before, ", "), mention(channelID))
+		return "notification-channel", nil
+	}
+
+	for _, c := range channels {
+		if err := store.Deactivate(r.Context(), guildID, c.ChannelID); err != nil {
+			return "", fmt.Errorf("failed to remove notification channel: %w", err)
+		}
+	}
+	if len(channels) > 0 {
+		s.auditDashboard(r, login, "remove notification channel", strings.Join(before, ", "), "")
+	}
+	return "notification-channel", nil
+}
+
+func (s *Server) saveBirthdayChannel(r *http.Request, login models.DashboardToken) (string, error) {
+	guildID := login.GuildID
+	channelID, err := s.formChannel(r, guildID)
+	if err != nil {
+		return "", err
+	}
+	store := s.bot.Store().BirthdayChannels
+	var before string
+	if previous, err := store.Active(r.Context(), guildID); err == nil {
+		before = mention(previous.ChannelID)
+	}
+
+	if channelID != "" {
+		if err := store.Activate(r.Context(), guildID, channelID); err != nil {
+			return "", fmt.Errorf("failed to set birthday channel: %w", err)
+		}
+		s.auditDashboard(r, login, "set birthday channel", before, mention(channelID))
+		return "birthday-channel", nil
+	}
+	err = store.Deactivate(r.Context(), guildID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "birthday-channel", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to remove birthday channel: %w", err)
+	}
+	s.auditDashboard(r, login, "remove birthday channel", before, "")
+	return "birthday-channel", nil
+}
+
+// auditDashboard records a change made on the dashboard in the guild's audit log, on behalf
+// of the admin the login was issued to
+func (s *Server) auditDashboard(r *http.Request, login models.DashboardToken, action, before, after string) {
+	s.bot.Audit(r.Context(), models.AuditEntry{
+		GuildID: login.GuildID,
+		ActorID: login.IssuedBy,
+		Action:  action,
+		Before:  before,
+		After:   after,
+		Via:     bot.AuditViaDashboard,
+	})
+}
+
+// mention formats a channel ID as a channel mention
+func mention(channelID string) string {
+	return fmt.Sprintf("<#%s>", channelID)
+}
+
+// dashboardLogout ends the session in this browser. The login link itself stays valid until
+// it expires; !dashboard revoke signs out everywhere.
+func (s *Server) dashboardLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: dashboardCookie, Path: "/dashboard/", MaxAge: -1, HttpOnly: true})
+	http.Redirect(w, r, "/dashboard/", http.StatusSeeOther)
+}
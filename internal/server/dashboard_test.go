@@ -0,0 +1,182 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/config"
+	"GoopBot/internal/discordtest"
+	"GoopBot/internal/features/birthdays"
+	"GoopBot/internal/features/twitchlive"
+	"GoopBot/internal/models"
+	"GoopBot/internal/scheduler"
+	"GoopBot/internal/server"
+	"GoopBot/internal/twitch"
+)
+
+// browser sends requests with the cookies it was given, like a browser would
+type browser struct {
+	t       *testing.T
+	handler http.Handler
+	cookies []*http.Cookie
+}
+
+func (b *browser) do(req *http.Request) (*http.Response, string) {
+	b.t.Helper()
+	for _, c := range b.cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	b.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	for _, c := range resp.Cookies() {
+		b.cookies = append(b.cookies, c)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func (b *browser) get(path string) (*http.Response, string) {
+	return b.do(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func (b *browser) post(path string, form url.Values) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return b.do(req)
+}
+
+// noStreams is a Twitch API where nobody is live
+type noStreams struct{}
+
+func (noStreams) GetMultipleStreams(context.Context, []string) ([]twitch.StreamData, error) {
+	return nil, nil
+}
+
+var (
+	loginLink = regexp.MustCompile(`/dashboard/login\?token=[0-9a-f]+`)
+	csrfField = regexp.MustCompile(`name="csrf" value="([0-9a-f]+)"`)
+)
+
+// newDashboard builds a bot serving the dashboard and returns a login link issued to the admin
+func newDashboard(t *testing.T) (*bot.Bot, *discordtest.Session, http.Handler, string) {
+	t.Helper()
+	return newDashboardWith(t, bot.Deps{Config: config.Default()}, twitchlive.New(noStreams{}))
+}
+
+// newDashboardWith is like newDashboard but builds the bot from deps and modules
+func newDashboardWith(t *testing.T, deps bot.Deps, modules ...bot.Module) (*bot.Bot, *discordtest.Session, http.Handler, string) {
+	t.Helper()
+	deps.Config.HTTPAddr = ":8080"
+	b, fake := bottest.NewWithDeps(t, deps, modules...)
+
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!dashboard"), "direct message")
+	dms := fake.SentToUser(bottest.AdminID)
+	if len(dms) != 1 {
+		t.Fatalf("expected one DM with the login link, got %+v", dms)
+	}
+	if !strings.Contains(dms[0].Content, "http://localhost:8080/dashboard/login?token=") {
+		t.Fatalf("unexpected login DM %q", dms[0].Content)
+	}
+	return b, fake, server.New(b, nil).Handler(), loginLink.FindString(dms[0].Content)
+}
+
+func TestDashboardLogin(t *testing.T) {
+	b, fake, handler, link := newDashboard(t)
+	b.Store().Creators.Link(context.Background(), models.GoopCreator{DiscordID: bottest.CreatorID, Username: "creator", GuildID: bottest.GuildID, TwitchUsername: "goopy"})
+
+	anonymous := &browser{t: t, handler: handler}
+	if _, body := anonymous.get("/dashboard/"); !strings.Contains(body, "Run <code>!dashboard</code>") {
+		t.Fatalf("expected login instructions, got %s", body)
+	}
+	if resp, body := anonymous.get("/dashboard/static/style.css"); resp.StatusCode != http.StatusOK || !strings.Contains(body, "body {") {
+		t.Fatalf("stylesheet: got %d", resp.StatusCode)
+	}
+	if resp, _ := anonymous.get("/dashboard/login?token=nope"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("invalid token: got %d, want 401", resp.StatusCode)
+	}
+
+	admin := &browser{t: t, handler: handler}
+	resp, _ := admin.get(link)
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "/dashboard/" {
+		t.Fatalf("login: got %d to %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, body := admin.get("/dashboard/")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "goopy") || !strings.Contains(body, "Linked Twitch Accounts") {
+		t.Fatalf("dashboard: got %d %s", resp.StatusCode, body)
+	}
+	if strings.Contains(body, "Upcoming Birthdays") {
+		t.Fatal("dashboard shows the birthdays section without the birthdays module")
+	}
+
+	// Non-admins can't get a link, and revoking signs everyone out
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.MemberID, "!dashboard"), "Administrator")
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!dashboard revoke"), "revoked")
+	if _, body := admin.get("/dashboard/"); strings.Contains(body, "goopy") {
+		t.Fatal("revoked session still sees the dashboard")
+	}
+}
+
+func TestDashboardUpcomingBirthdaysFollowBotClock(t *testing.T) {
+	cfg := config.Default()
+	cfg.Timezone = "UTC"
+	clock := scheduler.NewFakeClock(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	b, _, handler, link := newDashboardWith(t, bot.Deps{Config: cfg, Clock: clock}, birthdays.New())
+	b.Store().Birthdays.Set(context.Background(), models.Birthday{DiscordID: bottest.MemberID, Username: "member", GuildID: bottest.GuildID, Month: 3, Day: 20})
+
+	admin := &browser{t: t, handler: handler}
+	admin.get(link)
+	if _, body := admin.get("/dashboard/"); !strings.Contains(body, "March 20") {
+		t.Fatalf("expected the birthday upcoming on the bot's clock, got %s", body)
+	}
+}
+
+func TestDashboardSavesNotificationChannel(t *testing.T) {
+	b, _, handler, link := newDashboard(t)
+	admin := &browser{t: t, handler: handler}
+	admin.get(link)
+	_, body := admin.get("/dashboard/")
+	match := csrfField.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("no form token on the dashboard: %s", body)
+	}
+	csrf := match[1]
+
+	if resp, _ := admin.post("/dashboard/notification-channel", url.Values{"channel_id": {bottest.LiveChannelID}}); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("form without token: got %d, want 403", resp.StatusCode)
+	}
+	if resp, _ := admin.post("/dashboard/notification-channel", url.Values{"csrf": {csrf}, "channel_id": {"999"}}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("channel of another server: got %d, want 400", resp.StatusCode)
+	}
+
+	resp, _ := admin.post("/dashboard/notification-channel", url.Values{"csrf": {csrf}, "channel_id": {bottest.LiveChannelID}})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("save: got %d, want 303", resp.StatusCode)
+	}
+	channels, _ := b.Store().NotificationChannels.ListActive(context.Background(), bottest.GuildID)
+	if len(channels) != 1 || channels[0].ChannelID != bottest.LiveChannelID {
+		t.Fatalf("unexpected notification channels %+v", channels)
+	}
+	// The change is audited on behalf of the admin the login was issued to
+	entries, _ := b.Store().AuditLog.ListRecent(context.Background(), bottest.GuildID, 1)
+	if len(entries) != 1 || entries[0].ActorID != bottest.AdminID || entries[0].Via != bot.AuditViaDashboard || entries[0].After != "<#201>" {
+		t.Fatalf("unexpected audit entries %+v", entries)
+	}
+	if _, body := admin.get(resp.Header.Get("Location")); !strings.Contains(body, "notification channel saved") {
+		t.Fatalf("expected a confirmation, got %s", body)
+	}
+}
+
+func TestDashboardCommandNeedsHTTPServer(t *testing.T) {
+	b, fake := bottest.New(t)
+	bottest.ExpectReply(t, bottest.Send(b, fake, bottest.AdminID, "!dashboard"), "not enabled")
+}
@@ -0,0 +1,156 @@
+// Package server runs the bot's optional HTTP server: health checks for orchestrators,
+// Prometheus metrics, the web dashboard for guild admins and, when an API token is
+// configured, the admin API.
+package server
+
+import (
+	"GoopBot/internal/bot"
+	"GoopBot/internal/logging"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server serves /healthz, /readyz, /metrics, the dashboard under /dashboard and the admin
+// API under /api for a bot
+type Server struct {
+	bot  *bot.Bot
+	log  logging.Logger
+	http *http.Server
+}
+
+// New returns a server for b; call Start to serve it
+func New(b *bot.Bot, logger logging.Logger) *Server {
+	s := &Server{bot: b, log: logging.OrNop(logger)}
+	s.http = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	return s
+}
+
+// Handler returns the routes, for serving them elsewhere or testing them
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /readyz", s.readyz)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(s.bot.Metrics().Registry(), promhttp.HandlerOpts{}))
+	s.dashboardRoutes(mux)
+	if token := s.bot.Config().APIToken; token != "" {
+		s.apiRoutes(mux, token)
+	}
+	return mux
+}
+
+// Start listens on addr and serves in the background. It fails straight away if addr
+// can't be listened on.
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.log.Infof("Serving health checks and metrics on http://%s", listener.Addr())
+	if s.bot.Config().APIToken != "" {
+		s.log.Infof("Admin API enabled under http://%s/api", listener.Addr())
+	}
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.With(logging.KeyError, err).Errorf("HTTP server stopped")
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops accepting requests and waits for in-flight ones until ctx expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// healthz reports that the process is up, for liveness probes
+func (s *Server) healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// Readiness status values
+const (
+	statusReady    = "ready"
+	statusDegraded = "degraded" // Serving, but without the cache
+	statusNotReady = "not ready"
+)
+
+// readiness is the /readyz response body
+type readiness struct {
+	Status   string        `json:"status"`
+	Database string        `json:"database"`
+	Cache    string        `json:"cache"`
+	Discord  string        `json:"discord"`
+	Shards   []shardHealth `json:"shards"`
+}
+
+type shardHealth struct {
+	ID        int       `json:"id"`
+	Count     int       `json:"count"`
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+	Guilds    int       `json:"guilds"`
+	LatencyMS int64     `json:"latency_ms"`
+}
+
+// check renders a dependency check for the response
+func check(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// readyz reports whether the bot can serve Discord: the database answers and every shard
+// is connected. A cache outage only degrades it, since the bot falls back to the database.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	health := s.bot.Health(r.Context())
+	body := readiness{
+		Status:   statusReady,
+		Database: check(health.Database),
+		Cache:    check(health.Cache),
+		Discord:  "ok",
+		Shards:   []shardHealth{},
+	}
+
+	disconnected := 0
+	for _, shard := range health.Shards {
+		if !shard.Connected {
+			disconnected++
+		}
+		body.Shards = append(body.Shards, shardHealth{
+			ID:        shard.ID,
+			Count:     shard.Count,
+			Connected: shard.Connected,
+			Since:     shard.Since,
+			Guilds:    shard.Guilds,
+			LatencyMS: shard.Latency.Milliseconds(),
+		})
+	}
+	switch {
+	case len(health.Shards) == 0:
+		body.Discord = "no gateway connection"
+	case disconnected > 0:
+		body.Discord = fmt.Sprintf("%d of %d shards disconnected", disconnected, len(health.Shards))
+	}
+
+	code := http.StatusOK
+	switch {
+	case health.Database != nil || body.Discord != "ok":
+		body.Status = statusNotReady
+		code = http.StatusServiceUnavailable
+	case health.Cache != nil:
+		body.Status = statusDegraded
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,74 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GoopBot/internal/bottest"
+	"GoopBot/internal/server"
+)
+
+// get requests path from the server and returns the status code and body
+func get(t *testing.T, handler http.Handler, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(body)
+}
+
+func TestHealthz(t *testing.T) {
+	b, _ := bottest.New(t)
+	handler := server.New(b, nil).Handler()
+
+	if code, body := get(t, handler, "/healthz"); code != http.StatusOK || strings.TrimSpace(body) != "ok" {
+		t.Fatalf("healthz: got %d %q", code, body)
+	}
+}
+
+func TestReadyzNeedsAGatewayConnection(t *testing.T) {
+	b, _ := bottest.New(t)
+	handler := server.New(b, nil).Handler()
+
+	code, body := get(t, handler, "/readyz")
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz without shards: got %d, want 503", code)
+	}
+	var ready struct {
+		Status, Database, Cache, Discord string
+	}
+	if err := json.Unmarshal([]byte(body), &ready); err != nil {
+		t.Fatalf("decode %q: %v", body, err)
+	}
+	if ready.Status != "not ready" || ready.Database != "ok" || ready.Cache != "ok" || ready.Discord != "no gateway connection" {
+		t.Fatalf("unexpected readiness %+v", ready)
+	}
+}
+
+func TestMetricsCountCommands(t *testing.T) {
+	b, fake := bottest.New(t)
+	handler := server.New(b, nil).Handler()
+
+	bottest.Send(b, fake, bottest.StrangerID, "!help")
+	bottest.Send(b, fake, bottest.MemberID, "!settings")
+
+	code, body := get(t, handler, "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("metrics: got %d", code)
+	}
+	for _, want := range []string{
+		`goopbot_commands_total{command="help",result="ok"} 1`,
+		`goopbot_commands_total{command="settings",result="denied"} 1`,
+		// One message per help category plus the settings refusal
+		`goopbot_discord_api_requests_total{method="ChannelMessageSend",result="ok"} 4`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+}
@@ -2,6 +2,8 @@
 package twitch
 
 import (
+	"GoopBot/internal/logging"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,12 +19,18 @@ type Client struct {
 	clientSecret string
 	accessToken  string
 	httpClient   *http.Client
+	log          logging.Logger
+	onResponse   func(endpoint string, statusCode int)
 }
 
 // Config holds Twitch API configuration
 type Config struct {
 	ClientID     string
 	ClientSecret string
+	Logger       logging.Logger // Optional; nil discards log output
+	// OnResponse is called after every request with the endpoint ("token" or "streams")
+	// and the HTTP status code, or 0 if no response arrived. Optional.
+	OnResponse func(endpoint string, statusCode int)
 }
 
 // StreamData represents Twitch stream information
@@ -66,18 +74,33 @@ func NewClient(config Config) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		log:        logging.OrNop(config.Logger),
+		onResponse: config.OnResponse,
 	}
 
 	// Get OAuth token
-	if err := client.authenticate(); err != nil {
+	if err := client.authenticate(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to authenticate with Twitch API: %w", err)
 	}
 
 	return client, nil
 }
 
+// do sends a request and reports its status code to OnResponse
+func (c *Client) do(req *http.Request, endpoint string) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if c.onResponse != nil {
+		code := 0
+		if err == nil {
+			code = resp.StatusCode
+		}
+		c.onResponse(endpoint, code)
+	}
+	return resp, err
+}
+
 // authenticate gets an OAuth token using client credentials flow
-func (c *Client) authenticate() error {
+func (c *Client) authenticate(ctx context.Context) error {
 	tokenURL := "https://id.twitch.tv/oauth2/token"
 
 	data := url.Values{}
@@ -85,14 +108,14 @@ func (c *Client) authenticate() error {
 	data.Set("client_secret", c.clientSecret)
 	data.Set("grant_type", "client_credentials")
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return fmt.Errorf("failed to create token request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "token")
 	if err != nil {
 		return fmt.Errorf("failed to send token request: %w", err)
 	}
@@ -109,14 +132,15 @@ func (c *Client) authenticate() error {
 	}
 
 	c.accessToken = tokenResp.AccessToken
+	c.log.Debugf("Obtained Twitch access token (expires in %ds)", tokenResp.ExpiresIn)
 	return nil
 }
 
 // GetStreamByUsername checks if a specific user is currently streaming
-func (c *Client) GetStreamByUsername(username string) (*StreamData, error) {
+func (c *Client) GetStreamByUsername(ctx context.Context, username string) (*StreamData, error) {
 	apiURL := fmt.Sprintf("https://api.twitch.tv/helix/streams?user_login=%s", url.QueryEscape(username))
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create streams request: %w", err)
 	}
@@ -124,7 +148,7 @@ func (c *Client) GetStreamByUsername(username string) (*StreamData, error) {
 	req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	req.Header.Set("Client-Id", c.clientID)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "streams")
 	if err != nil {
 		return nil, fmt.Errorf("failed to send streams request: %w", err)
 	}
@@ -132,11 +156,12 @@ func (c *Client) GetStreamByUsername(username string) (*StreamData, error) {
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		// Token might be expired, try to re-authenticate
-		if authErr := c.authenticate(); authErr != nil {
+		c.log.With(logging.KeyTwitchUsername, username).Warnf("Twitch API returned 401, re-authenticating")
+		if authErr := c.authenticate(ctx); authErr != nil {
 			return nil, fmt.Errorf("failed to re-authenticate: %w", authErr)
 		}
 		// Retry the request with new token
-		return c.GetStreamByUsername(username)
+		return c.GetStreamByUsername(ctx, username)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -158,13 +183,14 @@ func (c *Client) GetStreamByUsername(username string) (*StreamData, error) {
 }
 
 // GetMultipleStreams checks multiple usernames at once (more efficient)
-func (c *Client) GetMultipleStreams(usernames []string) ([]StreamData, error) {
+func (c *Client) GetMultipleStreams(ctx context.Context, usernames []string) ([]StreamData, error) {
 	if len(usernames) == 0 {
 		return []StreamData{}, nil
 	}
 
 	// Twitch API allows up to 100 usernames per request
 	if len(usernames) > 100 {
+		c.log.Warnf("Only checking the first 100 of %d usernames", len(usernames))
 		usernames = usernames[:100]
 	}
 
@@ -176,7 +202,7 @@ func (c *Client) GetMultipleStreams(usernames []string) ([]StreamData, error) {
 
 	apiURL := "https://api.twitch.tv/helix/streams?" + params.Encode()
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create streams request: %w", err)
 	}
@@ -184,7 +210,7 @@ func (c *Client) GetMultipleStreams(usernames []string) ([]StreamData, error) {
 	req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	req.Header.Set("Client-Id", c.clientID)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "streams")
 	if err != nil {
 		return nil, fmt.Errorf("failed to send streams request: %w", err)
 	}
@@ -192,11 +218,12 @@ func (c *Client) GetMultipleStreams(usernames []string) ([]StreamData, error) {
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		// Token might be expired, try to re-authenticate
-		if authErr := c.authenticate(); authErr != nil {
+		c.log.Warnf("Twitch API returned 401, re-authenticating")
+		if authErr := c.authenticate(ctx); authErr != nil {
 			return nil, fmt.Errorf("failed to re-authenticate: %w", authErr)
 		}
 		// Retry the request with new token
-		return c.GetMultipleStreams(usernames)
+		return c.GetMultipleStreams(ctx, usernames)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -209,12 +236,13 @@ func (c *Client) GetMultipleStreams(usernames []string) ([]StreamData, error) {
 		return nil, fmt.Errorf("failed to decode streams response: %w", err)
 	}
 
+	c.log.Debugf("Twitch reported %d live streams for %d usernames", len(streamsResp.Data), len(usernames))
 	return streamsResp.Data, nil
 }
 
 // IsUserLive is a convenience method to check if a single user is live
-func (c *Client) IsUserLive(username string) (bool, *StreamData, error) {
-	stream, err := c.GetStreamByUsername(username)
+func (c *Client) IsUserLive(ctx context.Context, username string) (bool, *StreamData, error) {
+	stream, err := c.GetStreamByUsername(ctx, username)
 	if err != nil {
 		return false, nil, err
 	}
@@ -1,38 +1,125 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	_ "time/tzdata" // TIMEZONE works on hosts and images without a time zone database
 
 	"GoopBot/internal/bot"
+	"GoopBot/internal/config"
+	"GoopBot/internal/features"
+	"GoopBot/internal/logging"
+	"GoopBot/internal/server"
 )
 
-type Config struct {
-	DiscordToken       string
-	DBPath             string
-	RedisAddr          string
-	TwitchClientID     string
-	TwitchClientSecret string
+const usage = `usage: goopbot [command] [args] [flags]
+
+Commands:
+  run                             run the bot (the default)
+  migrate up|down [n]|status      inspect or change the database schema
+  check-twitch <user...>          show whether Twitch users are live
+  list-creators [guild]           list the linked Twitch accounts
+  export [file]                   write every record as JSON to file, or stdout
+  import <file>                   load an export into an empty database
+  backup [dir]                    back up the SQLite database while the bot runs
+  restore <backup>                replace the SQLite database with a backup
+  send-test-notification <guild>  post a sample going-live notification
+  doctor                          check the configuration and every connection
+
+Every command takes the bot's flags after its arguments, e.g. -config config.yaml,
+-db ./GoopBot.db or -database-url postgres://...`
+
+// commands are the subcommands of the binary; each returns the process exit code
+var commands = map[string]func(args []string) int{
+	"run":                    runBot,
+	"migrate":                runMigrate,
+	"check-twitch":           runCheckTwitch,
+	"list-creators":          runListCreators,
+	"export":                 runExport,
+	"import":                 runImport,
+	"backup":                 runBackup,
+	"restore":                runRestore,
+	"send-test-notification": runSendTestNotification,
+	"doctor":                 runDoctor,
 }
 
 func main() {
-	config := Config{
-		DiscordToken:       os.Getenv("DISCORD_TOKEN"),
-		DBPath:             "./GoopBot.db",
-		RedisAddr:          os.Getenv("REDIS_ADDR"),
-		TwitchClientID:     os.Getenv("TWITCH_CLIENT_ID"),
-		TwitchClientSecret: os.Getenv("TWITCH_CLIENT_SECRET"),
+	args := os.Args[1:]
+
+	// Without a command the bot runs, so existing service setups that only pass flags keep working
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		os.Exit(runBot(args))
 	}
 
-	bot, err := bot.NewBot(config.DiscordToken, config.DBPath, config.RedisAddr, config.TwitchClientID, config.TwitchClientSecret)
+	if args[0] == "help" {
+		fmt.Println(usage)
+		return
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", args[0], usage)
+		os.Exit(2)
+	}
+	os.Exit(command(args[1:]))
+}
+
+// runBot implements the run command: it connects to Discord and runs until a shutdown signal
+func runBot(args []string) int {
+	cfg, err := config.Load(args)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		return 2
 	}
 
-	// Main event loop
-	bot.Run()
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
 
-	log.Println("Bot is running!")
-	select {}
+	// Cancel the root context on Ctrl+C or a service stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	modules, err := features.Select(cfg.Modules)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	bot, err := bot.NewBot(cfg, logger, modules...)
+	if err != nil {
+		logger.Errorf("Failed to start bot: %v", err)
+		return 1
+	}
+
+	var httpServer *server.Server
+	if cfg.HTTPAddr != "" {
+		httpServer = server.New(bot, logger.With(logging.KeyComponent, "http"))
+		if err := httpServer.Start(cfg.HTTPAddr); err != nil {
+			logger.Errorf("Failed to start HTTP server: %v", err)
+			bot.Close(context.Background())
+			return 1
+		}
+	}
+
+	// Main event loop, returns once a shutdown signal arrives
+	bot.Run(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout.Duration)
+	defer cancel()
+	if httpServer != nil {
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Errorf("Error stopping HTTP server: %v", err)
+		}
+	}
+	if err := bot.Close(shutdownCtx); err != nil {
+		logger.Errorf("Error during shutdown: %v", err)
+	}
+	logger.Infof("Bot stopped")
+	return 0
 }
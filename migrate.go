@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"GoopBot/storage/db"
+)
+
+const migrateUsage = `usage: goopbot migrate up|down [n]|status [flags]
+
+  up        apply every pending migration
+  down [n]  revert the last n applied migrations (default 1)
+  status    list migrations and when they were applied
+
+Flags are the same as for the bot, e.g. -db ./GoopBot.db, -database-url postgres://...
+or -config config.yaml.`
+
+// runMigrate implements the migrate command and returns the process exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	action, args := args[0], args[1:]
+
+	steps := 1
+	if action == "down" && len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n < 1 {
+				fmt.Fprintln(os.Stderr, "down needs a positive number of migrations")
+				return 2
+			}
+			steps, args = n, args[1:]
+		}
+	}
+
+	cfg, logger, err := setup(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.Database() == "" {
+		fmt.Fprintln(os.Stderr, errNoDatabase)
+		return 2
+	}
+
+	conn, closeDB, err := openDatabase(cfg, true)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	defer closeDB()
+
+	migrator, err := db.NewMigrator(conn, db.Migrations(), logger)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		n, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Errorf("%v", err)
+			return 1
+		}
+		fmt.Printf("Applied %d migration(s), schema is at version %d\n", n, migrator.Latest())
+	case "down":
+		n, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logger.Errorf("%v", err)
+			return 1
+		}
+		version, _ := migrator.Version(ctx)
+		fmt.Printf("Reverted %d migration(s), schema is at version %d\n", n, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Errorf("%v", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate action %q\n\n%s\n", action, migrateUsage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"GoopBot/internal/bot"
+	"GoopBot/internal/features/twitchlive"
+	"GoopBot/internal/repository"
+
+	"github.com/bwmarrin/discordgo"
+	"gorm.io/gorm"
+)
+
+const sendTestNotificationUsage = `usage: goopbot send-test-notification <guild> [flags]
+
+Posts a sample going-live notification to the guild's notification channel, to check that the
+bot can post there. No gateway connection is opened, so a running bot is not disturbed.`
+
+// runSendTestNotification implements the send-test-notification command and returns the
+// process exit code
+func runSendTestNotification(args []string) int {
+	positional, flags := splitArgs(args)
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, sendTestNotificationUsage)
+		return 2
+	}
+	guildID := positional[0]
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.DiscordToken == "" {
+		fmt.Fprintln(os.Stderr, "Discord token is required (set DISCORD_TOKEN or -discord-token)")
+		return 2
+	}
+
+	conn, closeDB, err := openDatabase(cfg, false)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	if err := requireLatestSchema(ctx, conn); err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	channels, err := notificationChannels(ctx, conn, guildID)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+	if len(channels) == 0 {
+		fmt.Fprintf(os.Stderr, "Guild %s has no notification channel; run !setnotifications in the channel to use\n", guildID)
+		return 1
+	}
+
+	session, err := discordgo.New("Bot " + cfg.DiscordToken)
+	if err != nil {
+		logger.Errorf("failed to create Discord session: %v", err)
+		return 1
+	}
+
+	message := &discordgo.MessageSend{
+		Content: "🧪 This is a test notification. Real ones look like this:",
+		Embed:   twitchlive.LiveEmbed("twitch", "GoopBot", "Just Chatting", "Test stream", 0),
+	}
+	failed := 0
+	for _, channelID := range channels {
+		if _, err := session.ChannelMessageSendComplex(channelID, message); err != nil {
+			fmt.Printf("❌ Failed to post in channel %s: %v\n", channelID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✅ Posted a test notification in channel %s\n", channelID)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// notificationChannels returns where going-live notifications for a guild are posted, the
+// same way the bot decides
+func notificationChannels(ctx context.Context, conn *gorm.DB, guildID string) ([]string, error) {
+	return twitchlive.NotificationChannelIDs(ctx, repository.NewGorm(conn), guildID, func() (string, error) {
+		settings, err := bot.LoadGuildSettings(ctx, conn, guildID)
+		return settings.NotificationChannelID, err
+	})
+}
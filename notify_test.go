@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"GoopBot/internal/config"
+	"GoopBot/internal/models"
+	"GoopBot/internal/repository"
+	"GoopBot/storage/db"
+)
+
+func TestNotificationChannels(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default()
+	cfg.DBPath = filepath.Join(t.TempDir(), "goopbot.db")
+	conn, closeDB, err := openDatabase(cfg, true)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer closeDB()
+	if err := db.Migrate(ctx, conn, nil); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if channels, err := notificationChannels(ctx, conn, "100"); err != nil || len(channels) != 0 {
+		t.Fatalf("guild without channels: got %v (err %v)", channels, err)
+	}
+
+	// Without active notification channels the default channel is used, as the bot does
+	conn.Create(&models.GuildSettings{GuildID: "100", NotificationChannelID: "200"})
+	if channels, _ := notificationChannels(ctx, conn, "100"); !reflect.DeepEqual(channels, []string{"200"}) {
+		t.Fatalf("default channel: got %v", channels)
+	}
+	repository.NewGorm(conn).NotificationChannels.Activate(ctx, "100", "201")
+	if channels, _ := notificationChannels(ctx, conn, "100"); !reflect.DeepEqual(channels, []string{"201"}) {
+		t.Fatalf("active channel: got %v", channels)
+	}
+}
@@ -0,0 +1,112 @@
+// Package leader elects one of several bot instances to run each background job, using
+// leases in Redis. An instance holds a lease only as long as it keeps renewing it, so when
+// the leader stops or loses Redis, another instance takes over once the lease expires.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultTTL is how long a lease lasts without being renewed, and so roughly how long a job
+// goes without a leader after its leader disappears
+const DefaultTTL = 30 * time.Second
+
+// renewScript extends a lease if this instance still holds it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes a lease if this instance still holds it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Redis hands out leases stored under leader:<name> keys. It implements bot.Elector.
+type Redis struct {
+	client *redis.Client
+	id     string
+	ttl    time.Duration
+}
+
+// New returns an elector on client whose leases last ttl, usually DefaultTTL
+func New(client *redis.Client, ttl time.Duration) *Redis {
+	return &Redis{client: client, id: instanceID(), ttl: ttl}
+}
+
+// instanceID identifies this process in the leases it holds
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// ID returns the identifier this instance stores in its leases
+func (r *Redis) ID() string {
+	return r.id
+}
+
+// leaseKey is the Redis key of the lease on name
+func leaseKey(name string) string {
+	return fmt.Sprintf("leader:%s", name)
+}
+
+// Acquire takes the lease on name if it is free, or renews it if this instance holds it,
+// and reports whether this instance holds it now
+func (r *Redis) Acquire(ctx context.Context, name string) (bool, error) {
+	key := leaseKey(name)
+	taken, err := r.client.SetNX(ctx, key, r.id, r.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to take lease %s: %w", name, err)
+	}
+	if taken {
+		return true, nil
+	}
+
+	renewed, err := renewScript.Run(ctx, r.client, []string{key}, r.id, r.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lease %s: %w", name, err)
+	}
+	return renewed == 1, nil
+}
+
+// Release gives up the lease on name so another instance can take it at once.
+// It does nothing if another instance holds the lease.
+func (r *Redis) Release(ctx context.Context, name string) error {
+	if err := releaseScript.Run(ctx, r.client, []string{leaseKey(name)}, r.id).Err(); err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// Holder returns the ID of the instance holding the lease on name, or "" if it is free
+func (r *Redis) Holder(ctx context.Context, name string) (string, error) {
+	holder, err := r.client.Get(ctx, leaseKey(name)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read lease %s: %w", name, err)
+	}
+	return holder, nil
+}
+
+// RenewInterval is how often a lease should be renewed: a third of its TTL, so one failed
+// renewal does not lose it
+func (r *Redis) RenewInterval() time.Duration {
+	return r.ttl / 3
+}
@@ -0,0 +1,87 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newPair returns two electors, as two bot instances would have, on one Redis server
+func newPair(t *testing.T) (*miniredis.Miniredis, *Redis, *Redis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, New(client, DefaultTTL), New(client, DefaultTTL)
+}
+
+func mustAcquire(t *testing.T, r *Redis, name string) bool {
+	t.Helper()
+	held, err := r.Acquire(context.Background(), name)
+	if err != nil {
+		t.Fatalf("acquire %s: %v", name, err)
+	}
+	return held
+}
+
+func TestOneInstanceHoldsEachLease(t *testing.T) {
+	_, a, b := newPair(t)
+	ctx := context.Background()
+
+	if !mustAcquire(t, a, "stream monitoring") {
+		t.Fatalf("the first instance should take a free lease")
+	}
+	if mustAcquire(t, b, "stream monitoring") {
+		t.Fatalf("a second instance took a held lease")
+	}
+	if !mustAcquire(t, a, "stream monitoring") {
+		t.Fatalf("the holder should be able to renew its lease")
+	}
+	// Leases are per job
+	if !mustAcquire(t, b, "birthday monitoring") {
+		t.Fatalf("the second instance should take a different free lease")
+	}
+
+	// Releasing someone else's lease does nothing
+	if err := b.Release(ctx, "stream monitoring"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if holder, _ := a.Holder(ctx, "stream monitoring"); holder != a.ID() {
+		t.Fatalf("lease holder: got %q, want %q", holder, a.ID())
+	}
+
+	if err := a.Release(ctx, "stream monitoring"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if !mustAcquire(t, b, "stream monitoring") {
+		t.Fatalf("a released lease should pass to the other instance")
+	}
+}
+
+func TestLeaseFailsOverWhenNotRenewed(t *testing.T) {
+	server, a, b := newPair(t)
+
+	if !mustAcquire(t, a, "stream monitoring") {
+		t.Fatalf("the first instance should take a free lease")
+	}
+
+	// Renewing keeps the lease alive past its original expiry
+	server.FastForward(DefaultTTL - time.Second)
+	mustAcquire(t, a, "stream monitoring")
+	server.FastForward(DefaultTTL - time.Second)
+	if mustAcquire(t, b, "stream monitoring") {
+		t.Fatalf("a renewed lease passed to another instance")
+	}
+
+	// The leader stops renewing, so its lease expires and the other instance takes over
+	server.FastForward(DefaultTTL)
+	if !mustAcquire(t, b, "stream monitoring") {
+		t.Fatalf("the lease did not fail over after expiring")
+	}
+	if mustAcquire(t, a, "stream monitoring") {
+		t.Fatalf("the old leader took the lease back")
+	}
+}
@@ -0,0 +1,106 @@
+// Package cache stores short-lived values such as stream status and cached guild settings,
+// in Redis or in process memory. The database stays the source of truth: callers treat every
+// cache error as a miss and fall back to it.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnavailable is returned while the cache backend cannot be reached
+var ErrUnavailable = errors.New("cache unavailable")
+
+// Cache is a key-value store with per-key expiry
+type Cache interface {
+	// Get returns the value stored under key. It reports false when the key does not exist or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl; a zero ttl keeps it until it is deleted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys; missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+	// DeleteMatching removes every key matching pattern, in which * stands for any run of
+	// characters, e.g. discord:member:*:303
+	DeleteMatching(ctx context.Context, pattern string) error
+	// Ping checks that the backend is reachable
+	Ping(ctx context.Context) error
+	// Close releases the backend connection
+	Close() error
+}
+
+// SetJSON caches v as JSON under key for ttl
+func SetJSON(ctx context.Context, c Cache, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	if err := c.Set(ctx, key, data, ttl); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetJSON loads a value cached with SetJSON into v. It reports false when the key does not exist.
+func GetJSON(ctx context.Context, c Cache, key string, v interface{}) (bool, error) {
+	data, found, err := c.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	if !found {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// StreamStatus represents stream status cache entry
+type StreamStatus struct {
+	Username string
+	IsLive   bool
+	LastSeen time.Time
+}
+
+// streamStatusTTL is how long a stream status stays cached without being refreshed
+const streamStatusTTL = 24 * time.Hour
+
+// SetStreamStatus caches stream status
+func SetStreamStatus(ctx context.Context, c Cache, username string, isLive bool) error {
+	status := StreamStatus{
+		Username: username,
+		IsLive:   isLive,
+		LastSeen: time.Now(),
+	}
+	return SetJSON(ctx, c, fmt.Sprintf("stream:%s", username), status, streamStatusTTL)
+}
+
+// GetStreamStatus retrieves cached stream status, or nil when none is cached
+func GetStreamStatus(ctx context.Context, c Cache, username string) (*StreamStatus, error) {
+	var status StreamStatus
+	found, err := GetJSON(ctx, c, fmt.Sprintf("stream:%s", username), &status)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// SetStreamCheckCooldown sets a cooldown to prevent too frequent API calls
+func SetStreamCheckCooldown(ctx context.Context, c Cache, username string, duration time.Duration) error {
+	return c.Set(ctx, fmt.Sprintf("cooldown:%s", username), []byte("checked"), duration)
+}
+
+// IsStreamCheckOnCooldown checks if a stream check is on cooldown. Nothing is on cooldown
+// while the cache is unavailable.
+func IsStreamCheckOnCooldown(ctx context.Context, c Cache, username string) bool {
+	_, found, err := c.Get(ctx, fmt.Sprintf("cooldown:%s", username))
+	return err == nil && found
+}
+
+// DeleteStream removes the cached status and check cooldown of a stream
+func DeleteStream(ctx context.Context, c Cache, username string) error {
+	return c.Delete(ctx, fmt.Sprintf("stream:%s", username), fmt.Sprintf("cooldown:%s", username))
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryExpiresKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	m.Set(ctx, "short", []byte("a"), time.Minute)
+	m.Set(ctx, "forever", []byte("b"), 0)
+	if value, found, err := m.Get(ctx, "short"); err != nil || !found || string(value) != "a" {
+		t.Fatalf("get before expiry: %q %v %v", value, found, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, found, _ := m.Get(ctx, "short"); found {
+		t.Fatalf("key should have expired")
+	}
+	if _, found, _ := m.Get(ctx, "forever"); !found {
+		t.Fatalf("key without a TTL should not expire")
+	}
+
+	m.Delete(ctx, "forever", "missing")
+	if _, found, _ := m.Get(ctx, "forever"); found {
+		t.Fatalf("deleted key is still cached")
+	}
+}
+
+func TestMemoryDeletesMatchingKeys(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	for _, key := range []string{"discord:member:100:303", "discord:member:101:303", "discord:member:100:304", "discord:guild:100"} {
+		m.Set(ctx, key, []byte("x"), 0)
+	}
+
+	m.DeleteMatching(ctx, "discord:member:*:303")
+	for key, want := range map[string]bool{"discord:member:100:303": false, "discord:member:101:303": false, "discord:member:100:304": true, "discord:guild:100": true} {
+		if _, found, _ := m.Get(ctx, key); found != want {
+			t.Errorf("%s: cached %v, want %v", key, found, want)
+		}
+	}
+	m.DeleteMatching(ctx, "discord:guild:100")
+	if _, found, _ := m.Get(ctx, "discord:guild:100"); found {
+		t.Errorf("a pattern without * didn't delete its key")
+	}
+}
+
+func TestStreamStatusAndCooldown(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	if status, err := GetStreamStatus(ctx, m, "goopy"); err != nil || status != nil {
+		t.Fatalf("expected no cached status, got %+v (err %v)", status, err)
+	}
+	if err := SetStreamStatus(ctx, m, "goopy", true); err != nil {
+		t.Fatalf("set stream status: %v", err)
+	}
+	status, err := GetStreamStatus(ctx, m, "goopy")
+	if err != nil || status == nil || !status.IsLive || status.Username != "goopy" {
+		t.Fatalf("unexpected cached status %+v (err %v)", status, err)
+	}
+
+	if IsStreamCheckOnCooldown(ctx, m, "goopy") {
+		t.Fatalf("nothing should be on cooldown yet")
+	}
+	SetStreamCheckCooldown(ctx, m, "goopy", time.Minute)
+	if !IsStreamCheckOnCooldown(ctx, m, "goopy") {
+		t.Fatalf("expected a cooldown")
+	}
+
+	if err := DeleteStream(ctx, m, "goopy"); err != nil {
+		t.Fatalf("delete stream: %v", err)
+	}
+	if status, _ := GetStreamStatus(ctx, m, "goopy"); status != nil || IsStreamCheckOnCooldown(ctx, m, "goopy") {
+		t.Fatalf("stream still cached after deleting it")
+	}
+}
+
+func TestRedisDegradesWhileUnreachable(t *testing.T) {
+	ctx := context.Background()
+	// Nothing listens on port 1, so every connection is refused straight away
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	r := NewRedis(ctx, client, nil)
+	defer r.Close()
+
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	if !r.Degraded() {
+		t.Fatalf("expected the cache to start degraded")
+	}
+	if _, _, err := r.Get(ctx, "key"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if err := SetJSON(ctx, r, "key", 1, time.Minute); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable from SetJSON, got %v", err)
+	}
+	if IsStreamCheckOnCooldown(ctx, r, "goopy") {
+		t.Fatalf("nothing is on cooldown while Redis is down")
+	}
+
+	// After the retry interval Redis is tried again, and is still down
+	now = now.Add(retryInterval)
+	if err := r.Set(ctx, "key", []byte("v"), 0); !errors.Is(err, ErrUnavailable) || !r.Degraded() {
+		t.Fatalf("expected Redis to still be unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many writes the in-memory cache takes between sweeps for expired keys
+const sweepEvery = 1000
+
+// memoryEntry is a cached value and when it expires (zero never expires)
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// Memory is a Cache in process memory, for single-instance deployments and tests
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	writes  int
+	now     func() time.Time
+}
+
+// NewMemory returns an empty in-memory cache
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// expired reports whether e has expired at t
+func (e memoryEntry) expired(t time.Time) bool {
+	return !e.expires.IsZero() && !t.Before(e.expires)
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(m.now()) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expires = now.Add(ttl)
+	}
+	m.entries[key] = entry
+
+	// Keys that are never read again would otherwise stay forever
+	m.writes++
+	if m.writes%sweepEvery == 0 {
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *Memory) DeleteMatching(_ context.Context, pattern string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.entries {
+		if matchPattern(pattern, key) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+// matchPattern reports whether key matches pattern, in which * stands for any run of characters
+func matchPattern(pattern, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return key == pattern
+	}
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i < 0 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+	return strings.HasSuffix(key, parts[len(parts)-1])
+}
+
+func (m *Memory) Ping(context.Context) error {
+	return nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
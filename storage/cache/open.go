@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"GoopBot/internal/config"
+	"GoopBot/internal/logging"
+	"GoopBot/redis/redisutil"
+	"context"
+)
+
+// Open returns the cache the configuration selects: Redis, or process memory when
+// cache is "memory". A Redis cache starts degraded if Redis can't be reached yet.
+func Open(ctx context.Context, cfg config.Config, logger logging.Logger) Cache {
+	if cfg.Cache == config.CacheMemory {
+		logging.OrNop(logger).Infof("Caching in process memory")
+		return NewMemory()
+	}
+	client := redisutil.NewClient(redisutil.Config{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	return NewRedis(ctx, client, logger)
+}
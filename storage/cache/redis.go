@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"GoopBot/internal/logging"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// retryInterval is how long the Redis cache waits after a failure before trying Redis again.
+// In between, every call fails fast with ErrUnavailable instead of waiting for a timeout.
+const retryInterval = 10 * time.Second
+
+// Redis is a Cache in Redis that keeps working, degraded, while Redis is down: calls return
+// ErrUnavailable, so callers fall back to the database until Redis answers again
+type Redis struct {
+	client *redis.Client
+	log    logging.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	downSince time.Time // Zero while Redis is reachable
+	lastTry   time.Time
+}
+
+// NewRedis wraps a Redis client. If Redis can't be reached the cache starts degraded
+// rather than failing, and recovers by itself once Redis is back.
+func NewRedis(ctx context.Context, client *redis.Client, logger logging.Logger) *Redis {
+	r := &Redis{client: client, log: logging.OrNop(logger), now: time.Now}
+	if err := r.Ping(ctx); err != nil {
+		return r
+	}
+	r.log.With("addr", client.Options().Addr, "db", client.Options().DB).Infof("Connected to Redis successfully")
+	return r
+}
+
+// Client returns the underlying Redis client
+func (r *Redis) Client() *redis.Client {
+	return r.client
+}
+
+// Degraded reports whether Redis was unreachable on the last attempt
+func (r *Redis) Degraded() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.downSince.IsZero()
+}
+
+// do runs op against Redis and records whether Redis answered. While degraded, op only
+// runs once per retryInterval. probe runs op regardless, for health checks.
+func (r *Redis) do(ctx context.Context, probe bool, op func() error) error {
+	r.mu.Lock()
+	if !probe && !r.downSince.IsZero() && r.now().Sub(r.lastTry) < retryInterval {
+		r.mu.Unlock()
+		return ErrUnavailable
+	}
+	r.lastTry = r.now()
+	r.mu.Unlock()
+
+	err := op()
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up; that says nothing about Redis
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if r.downSince.IsZero() {
+			r.downSince = r.now()
+			r.log.With(logging.KeyError, err).Warnf("Redis unavailable, falling back to the database until it is back")
+		}
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if !r.downSince.IsZero() {
+		r.log.Infof("Redis is reachable again after %s", r.now().Sub(r.downSince).Round(time.Second))
+		r.downSince = time.Time{}
+	}
+	return nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var data []byte
+	found := false
+	err := r.do(ctx, false, func() error {
+		value, err := r.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		data, found = value, err == nil
+		return err
+	})
+	return data, found, err
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.do(ctx, false, func() error {
+		return r.client.Set(ctx, key, value, ttl).Err()
+	})
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	return r.do(ctx, false, func() error {
+		return r.client.Del(ctx, keys...).Err()
+	})
+}
+
+// DeleteMatching scans for the matching keys, so it is fine on a busy server but not atomic:
+// keys written while it runs may stay
+func (r *Redis) DeleteMatching(ctx context.Context, pattern string) error {
+	return r.do(ctx, false, func() error {
+		iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		return r.client.Del(ctx, keys...).Err()
+	})
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.do(ctx, true, func() error {
+		return r.client.Ping(ctx).Err()
+	})
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
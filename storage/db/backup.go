@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Backup file names are the prefix, the UTC time the backup was taken and the suffix
+const (
+	backupPrefix     = "goopbot-"
+	backupSuffix     = ".db"
+	backupTimeFormat = "20060102-150405"
+)
+
+// BackupFile is a database backup in a backup directory
+type BackupFile struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// Backup writes a copy of a SQLite database into dir with VACUUM INTO, which reads a consistent
+// snapshot while the bot keeps using the database, and returns the new backup. The copy is
+// written under a temporary name first, so a failed backup never looks like a finished one.
+func Backup(ctx context.Context, conn *gorm.DB, dir string, now time.Time) (BackupFile, error) {
+	if IsPostgres(conn) {
+		return BackupFile{}, errors.New("backups need a SQLite database; back up Postgres with pg_dump")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return BackupFile{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+now.UTC().Format(backupTimeFormat)+backupSuffix)
+	partial := path + ".partial"
+	os.Remove(partial)
+	if err := conn.WithContext(ctx).Exec("VACUUM INTO ?", partial).Error; err != nil {
+		os.Remove(partial)
+		return BackupFile{}, fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return BackupFile{}, fmt.Errorf("failed to finish backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("failed to read backup: %w", err)
+	}
+	return BackupFile{Path: path, Time: now.UTC().Truncate(time.Second), Size: info.Size()}, nil
+}
+
+// ListBackups returns the backups in dir, newest first. A missing directory has no backups.
+func ListBackups(dir string) ([]BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []BackupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		taken, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupFile{Path: filepath.Join(dir, name), Time: taken, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Time.After(backups[j].Time) })
+	return backups, nil
+}
+
+// PruneBackups deletes all but the newest keep backups in dir and returns how many it deleted
+func PruneBackups(dir string, keep int) (int, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return deleted, fmt.Errorf("failed to delete old backup: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Restored describes a finished Restore
+type Restored struct {
+	SchemaVersion int
+	Pending       int    // Migrations the bot applies to the restored database when it starts
+	Previous      string // Where the replaced database was moved, or "" if there was none
+}
+
+// Restore replaces the SQLite database at dbPath with a backup. The backup is copied next to
+// the database and checked first: it must pass SQLite's integrity check and have a schema this
+// build can run, no newer than its latest migration. Only then is the current database moved
+// aside, with a timestamp suffix, and the copy swapped in. The bot must not be running.
+func Restore(ctx context.Context, backupPath, dbPath string, now time.Time) (Restored, error) {
+	if _, err := os.Stat(backupPath); err != nil {
+		return Restored{}, fmt.Errorf("failed to find backup: %w", err)
+	}
+	staged := dbPath + ".restore"
+	if err := copyFile(backupPath, staged); err != nil {
+		return Restored{}, err
+	}
+	swapped := false
+	defer func() {
+		if !swapped {
+			os.Remove(staged)
+		}
+	}()
+
+	restored, err := checkBackup(ctx, staged)
+	if err != nil {
+		return Restored{}, err
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		restored.Previous = dbPath + ".before-restore-" + now.UTC().Format(backupTimeFormat)
+		// The journal files belong to the database they sit next to, so they move with it
+		for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+			err := os.Rename(dbPath+suffix, restored.Previous+suffix)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return Restored{}, fmt.Errorf("failed to move the current database aside: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		return Restored{}, fmt.Errorf("failed to swap in the backup: %w", err)
+	}
+	swapped = true
+	return restored, nil
+}
+
+// checkBackup opens a copy of a backup and, if the bot can run on it, returns its schema version
+// and how many migrations it lacks
+func checkBackup(ctx context.Context, path string) (Restored, error) {
+	conn, err := Open(path)
+	if err != nil {
+		return Restored{}, err
+	}
+	if sqlDB, err := conn.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	conn = conn.WithContext(ctx)
+
+	var integrity string
+	if err := conn.Raw("PRAGMA integrity_check").Scan(&integrity).Error; err != nil {
+		return Restored{}, fmt.Errorf("backup is not a readable SQLite database: %w", err)
+	}
+	if integrity != "ok" {
+		return Restored{}, fmt.Errorf("backup failed the integrity check: %s", integrity)
+	}
+	if !conn.Migrator().HasTable(&schemaMigration{}) {
+		return Restored{}, errors.New("backup has no schema_migrations table; it is not a GoopBot backup")
+	}
+
+	migrator, err := NewMigrator(conn, Migrations(), nil)
+	if err != nil {
+		return Restored{}, err
+	}
+	// Version fails for a schema with migrations this build does not know
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return Restored{}, fmt.Errorf("backup cannot be restored: %w", err)
+	}
+	if version == 0 {
+		return Restored{}, errors.New("backup has no migrations applied; it is not a GoopBot backup")
+	}
+	return Restored{SchemaVersion: version, Pending: migrator.Latest() - version}, nil
+}
+
+// copyFile copies src to dst, replacing dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to stage backup: %w", err)
+	}
+	return nil
+}
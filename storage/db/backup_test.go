@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoopBot/internal/models"
+)
+
+func TestBackupAndPrune(t *testing.T) {
+	ctx := context.Background()
+	conn := migratedTestDB(t)
+	if IsPostgres(conn) {
+		t.Skip("backups are SQLite only")
+	}
+	conn.Create(&models.Birthday{DiscordID: "1", GuildID: "100", Month: 3, Day: 15})
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	start := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		backup, err := Backup(ctx, conn, dir, start.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatalf("backup %d: %v", i, err)
+		}
+		if backup.Size == 0 || filepath.Base(backup.Path) != fmt.Sprintf("goopbot-20260315-%d0000.db", 12+i) {
+			t.Fatalf("unexpected backup %+v", backup)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a backup"), 0o600)
+
+	backups, err := ListBackups(dir)
+	if err != nil || len(backups) != 3 || !backups[0].Time.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("unexpected backups %+v (err %v)", backups, err)
+	}
+	if deleted, err := PruneBackups(dir, 2); err != nil || deleted != 1 {
+		t.Fatalf("prune deleted %d (err %v), want 1", deleted, err)
+	}
+	backups, _ = ListBackups(dir)
+	if len(backups) != 2 || backups[1].Time.Equal(start) {
+		t.Fatalf("prune kept the wrong backups: %+v", backups)
+	}
+
+	// The backup is a complete database
+	restored, err := Open(backups[0].Path)
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	var count int64
+	restored.Model(&models.Birthday{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("backup has %d birthdays, want 1", count)
+	}
+	if sqlDB, err := restored.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	if backups, err := ListBackups(filepath.Join(dir, "missing")); err != nil || len(backups) != 0 {
+		t.Fatalf("missing directory: got %+v (err %v)", backups, err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "GoopBot.db")
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	conn, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := Migrate(ctx, conn, nil); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	conn.Create(&models.GoopCreator{DiscordID: "1", TwitchUsername: "before"})
+	backup, err := Backup(ctx, conn, filepath.Join(dir, "backups"), now)
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	conn.Create(&models.GoopCreator{DiscordID: "2", TwitchUsername: "after"})
+	if sqlDB, err := conn.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	restored, err := Restore(ctx, backup.Path, dbPath, now)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.Pending != 0 || restored.SchemaVersion == 0 || !strings.HasSuffix(restored.Previous, ".before-restore-20260315-120000") {
+		t.Fatalf("unexpected restore result %+v", restored)
+	}
+
+	for path, want := range map[string]int{dbPath: 1, restored.Previous: 2} {
+		conn, err := Open(path)
+		if err != nil {
+			t.Fatalf("open %s: %v", path, err)
+		}
+		var creators []models.GoopCreator
+		conn.Find(&creators)
+		if len(creators) != want {
+			t.Fatalf("%s has %d creators, want %d", path, len(creators), want)
+		}
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+}
+
+func TestRestoreRejectsBadBackups(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "GoopBot.db")
+	os.WriteFile(dbPath, []byte("current"), 0o600)
+
+	garbage := filepath.Join(dir, "garbage.db")
+	os.WriteFile(garbage, []byte("this is not a database at all, just some text"), 0o600)
+	if _, err := Restore(ctx, garbage, dbPath, time.Now()); err == nil {
+		t.Fatal("expected a file that is not a database to be rejected")
+	}
+
+	// A schema from a newer build can't be run by this one
+	newer := filepath.Join(dir, "newer.db")
+	conn, _ := Open(newer)
+	Migrate(ctx, conn, nil)
+	conn.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (999, 'future', CURRENT_TIMESTAMP)")
+	if sqlDB, err := conn.DB(); err == nil {
+		sqlDB.Close()
+	}
+	if _, err := Restore(ctx, newer, dbPath, time.Now()); err == nil || !strings.Contains(err.Error(), "upgrade GoopBot") {
+		t.Fatalf("expected a newer schema to be rejected, got %v", err)
+	}
+
+	// Rejected backups leave the current database alone
+	if data, _ := os.ReadFile(dbPath); string(data) != "current" {
+		t.Fatalf("current database was changed to %q", data)
+	}
+	if _, err := os.Stat(dbPath + ".restore"); !os.IsNotExist(err) {
+		t.Fatalf("staged copy was left behind: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// copyBatchSize is how many rows Copy reads and writes at a time
+const copyBatchSize = 500
+
+// Copy copies every row of every table from src to dst, keeping IDs and including
+// soft-deleted rows, and returns the number of rows copied per table. Both databases must be
+// migrated to the same version and the tables in dst must be empty. The copy runs in a single
+// transaction on dst, so a failed copy leaves dst empty.
+func Copy(ctx context.Context, src, dst *gorm.DB, logger logging.Logger) (map[string]int64, error) {
+	log := logging.OrNop(logger)
+	src, dst = src.WithContext(ctx), dst.WithContext(ctx)
+
+	srcVersion, err := schemaVersion(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source schema version: %w", err)
+	}
+	dstVersion, err := schemaVersion(ctx, dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target schema version: %w", err)
+	}
+	if srcVersion != dstVersion {
+		return nil, fmt.Errorf("source schema is at version %d but target is at version %d; migrate both first", srcVersion, dstVersion)
+	}
+
+	tables := models.All()
+	if err := requireEmpty(dst, tables); err != nil {
+		return nil, err
+	}
+
+	copied := make(map[string]int64)
+	err = dst.Transaction(func(tx *gorm.DB) error {
+		for _, model := range tables {
+			table, err := tableName(tx, model)
+			if err != nil {
+				return err
+			}
+
+			// A fresh slice of the model type receives each batch
+			rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem())).Interface()
+			result := src.Unscoped().Model(model).FindInBatches(rows, copyBatchSize, func(_ *gorm.DB, _ int) error {
+				return tx.Session(&gorm.Session{SkipHooks: true}).Create(rows).Error
+			})
+			if result.Error != nil {
+				return fmt.Errorf("failed to copy %s: %w", table, result.Error)
+			}
+			copied[table] = result.RowsAffected
+
+			if err := resetSequence(tx, table); err != nil {
+				return err
+			}
+			log.Infof("Copied %d rows into %s", result.RowsAffected, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// requireEmpty fails unless every table of models is empty in dst, soft-deleted rows included
+func requireEmpty(dst *gorm.DB, tables []interface{}) error {
+	for _, model := range tables {
+		var count int64
+		if err := dst.Unscoped().Model(model).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count rows in target: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("target table for %T already has %d rows; use an empty database", model, count)
+		}
+	}
+	return nil
+}
+
+// resetSequence moves the ID sequence of a table past rows that were inserted with explicit
+// IDs. SQLite does this by itself.
+func resetSequence(tx *gorm.DB, table string) error {
+	if !IsPostgres(tx) {
+		return nil
+	}
+	err := tx.Exec(fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence('%s', 'id'), (SELECT COALESCE(MAX(id), 0) + 1 FROM %s), false)",
+		table, table)).Error
+	if err != nil {
+		return fmt.Errorf("failed to reset the ID sequence of %s: %w", table, err)
+	}
+	return nil
+}
+
+// schemaVersion returns the migration version of a database
+func schemaVersion(ctx context.Context, conn *gorm.DB) (int, error) {
+	migrator, err := NewMigrator(conn, Migrations(), nil)
+	if err != nil {
+		return 0, err
+	}
+	return migrator.Version(ctx)
+}
+
+// tableName returns the table a model is stored in
+func tableName(conn *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: conn}
+	if err := stmt.Parse(model); err != nil {
+		return "", fmt.Errorf("failed to parse %T: %w", model, err)
+	}
+	return stmt.Schema.Table, nil
+}
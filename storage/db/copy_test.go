@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"GoopBot/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// migratedTestDB opens an empty database with the built-in migrations applied
+func migratedTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn := openTestDB(t)
+	if err := Migrate(context.Background(), conn, nil); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return conn
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	src, dst := migratedTestDB(t), migratedTestDB(t)
+
+	modules := "twitch"
+	src.Create(&models.GoopCreator{DiscordID: "1", TwitchUsername: "goopy", IsActive: true})
+	src.Create(&models.GoopCreator{DiscordID: "2", TwitchUsername: "gone", IsActive: true})
+	src.Where("discord_id = ?", "2").Delete(&models.GoopCreator{})
+	src.Create(&models.Birthday{DiscordID: "1", GuildID: "100", Month: 3, Day: 15})
+	src.Create(&models.GuildSettings{GuildID: "100", CommandPrefix: "?", EnabledModules: &modules})
+
+	copied, err := Copy(ctx, src, dst, nil)
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if copied["goop_creators"] != 2 || copied["birthdays"] != 1 || copied["guild_settings"] != 1 || copied["role_messages"] != 0 {
+		t.Fatalf("unexpected row counts: %v", copied)
+	}
+
+	// Soft-deleted rows keep their deletion time and IDs are preserved
+	var creators []models.GoopCreator
+	dst.Unscoped().Order("id").Find(&creators)
+	if len(creators) != 2 || creators[0].ID != 1 || creators[1].ID != 2 || !creators[1].DeletedAt.Valid {
+		t.Fatalf("unexpected creators in target: %+v", creators)
+	}
+	var settings models.GuildSettings
+	dst.First(&settings)
+	if settings.EnabledModules == nil || *settings.EnabledModules != "twitch" || settings.CommandPrefix != "?" {
+		t.Fatalf("unexpected settings in target: %+v", settings)
+	}
+
+	// New rows get IDs after the copied ones
+	next := models.GoopCreator{DiscordID: "3", TwitchUsername: "new"}
+	if err := dst.Create(&next).Error; err != nil || next.ID != 3 {
+		t.Fatalf("insert after copy got ID %d (err %v), want 3", next.ID, err)
+	}
+
+	if _, err := Copy(ctx, src, dst, nil); err == nil || !strings.Contains(err.Error(), "empty database") {
+		t.Fatalf("expected copying into a non-empty database to fail, got %v", err)
+	}
+}
+
+func TestCopyRequiresSameSchemaVersion(t *testing.T) {
+	src, dst := migratedTestDB(t), openTestDB(t)
+
+	if _, err := Copy(context.Background(), src, dst, nil); err == nil || !strings.Contains(err.Error(), "migrate both first") {
+		t.Fatalf("expected a schema version error, got %v", err)
+	}
+}
@@ -1,63 +1,37 @@
-// Package db provides database connection and migration utilities for GoopBot.
+// Package db opens the GoopBot database, SQLite or Postgres, and manages its schema through
+// numbered up/down migrations tracked in the schema_migrations table.
 package db
 
 import (
-	"database/sql"
+	"GoopBot/internal/config"
 	"fmt"
-	"log"
 
-	_ "github.com/mattn/go-sqlite3"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-// Config holds database configuration
-type Config struct {
-	Path string
-}
+// Open connects to the database named by dsn: a postgres:// URL opens Postgres and anything
+// else is the path of a SQLite file (see config.Config.Database). It does not migrate the schema.
+func Open(dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	if config.IsPostgresURL(dsn) {
+		dialector = postgres.Open(dsn)
+	} else {
+		dialector = sqlite.Open(dsn)
+	}
 
-// NewSQLiteDB creates a new SQLite database connection
-func NewSQLiteDB(cfg Config) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", cfg.Path)
+	conn, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-
-	// Ping to verify connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	return db, nil
+	return conn, nil
 }
 
-// Migrate applies necessary schema migrations
-func Migrate(db *sql.DB) error {
-	queries := []string{
-		`
-        CREATE TABLE IF NOT EXISTS twitch_subscriptions (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            discord_user_id TEXT NOT NULL,
-            twitch_username TEXT NOT NULL UNIQUE,
-            channel_id TEXT NOT NULL,
-            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-            FOREIGN KEY (channel_id) REFERENCES channels(id)
-        );
-        `,
-		`
-        CREATE TABLE IF NOT EXISTS channels (
-            id TEXT PRIMARY KEY,
-            name TEXT NOT NULL,
-            guild_id TEXT NOT NULL
-        );
-        `,
-	}
-
-	for _, q := range queries {
-		_, err := db.Exec(q)
-		if err != nil {
-			return fmt.Errorf("migration failed: %w", err)
-		}
-	}
-
-	log.Println("Database migration completed successfully")
-	return nil
+// IsPostgres reports whether conn is a Postgres connection
+func IsPostgres(conn *gorm.DB) bool {
+	return conn.Dialector.Name() == "postgres"
 }
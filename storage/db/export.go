@@ -0,0 +1,144 @@
+package db
+
+import (
+	"GoopBot/internal/logging"
+	"GoopBot/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Export is a portable copy of the database, written by WriteExport and read by Import
+type Export struct {
+	SchemaVersion int                        `json:"schema_version"`
+	ExportedAt    time.Time                  `json:"exported_at"`
+	Tables        map[string]json.RawMessage `json:"tables"`
+}
+
+// exportedModels are the tables an export holds. Dashboard logins are left out: only their
+// hashes are stored, and they expire within a day anyway.
+func exportedModels() []interface{} {
+	var tables []interface{}
+	for _, model := range models.All() {
+		if _, ok := model.(*models.DashboardToken); !ok {
+			tables = append(tables, model)
+		}
+	}
+	return tables
+}
+
+// WriteExport writes every row of every exported table as JSON to w, keeping IDs and
+// including soft-deleted rows, and returns the number of rows written per table
+func WriteExport(ctx context.Context, conn *gorm.DB, w io.Writer) (map[string]int64, error) {
+	conn = conn.WithContext(ctx)
+	version, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	export := Export{SchemaVersion: version, ExportedAt: time.Now().UTC(), Tables: make(map[string]json.RawMessage)}
+	counts := make(map[string]int64)
+	for _, model := range exportedModels() {
+		table, err := tableName(conn, model)
+		if err != nil {
+			return nil, err
+		}
+		rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+		if err := conn.Unscoped().Order("id").Find(rows.Interface()).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		data, err := json.Marshal(rows.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", table, err)
+		}
+		export.Tables[table] = data
+		counts[table] = int64(rows.Elem().Len())
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+	return counts, nil
+}
+
+// Import loads an export written by WriteExport into conn and returns the number of rows
+// imported per table. The database must be migrated to the version the export was taken at
+// and its tables must be empty. The import runs in a single transaction, so a failed import
+// leaves the database empty.
+func Import(ctx context.Context, conn *gorm.DB, r io.Reader, logger logging.Logger) (map[string]int64, error) {
+	log := logging.OrNop(logger)
+	conn = conn.WithContext(ctx)
+
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	version, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if export.SchemaVersion != version {
+		return nil, fmt.Errorf("export is at schema version %d but the database is at version %d; migrate the database to match", export.SchemaVersion, version)
+	}
+
+	tables := exportedModels()
+	known := make(map[string]bool)
+	for _, model := range tables {
+		table, err := tableName(conn, model)
+		if err != nil {
+			return nil, err
+		}
+		known[table] = true
+	}
+	for table := range export.Tables {
+		if !known[table] {
+			return nil, fmt.Errorf("export has unknown table %q", table)
+		}
+	}
+	if err := requireEmpty(conn, tables); err != nil {
+		return nil, err
+	}
+
+	imported := make(map[string]int64)
+	err = conn.Transaction(func(tx *gorm.DB) error {
+		for _, model := range tables {
+			table, err := tableName(tx, model)
+			if err != nil {
+				return err
+			}
+			data, ok := export.Tables[table]
+			if !ok {
+				continue
+			}
+
+			rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+			if err := json.Unmarshal(data, rows.Interface()); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", table, err)
+			}
+			n := rows.Elem().Len()
+			if n == 0 {
+				continue
+			}
+			if err := tx.Session(&gorm.Session{SkipHooks: true}).CreateInBatches(rows.Interface(), copyBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to import %s: %w", table, err)
+			}
+			if err := resetSequence(tx, table); err != nil {
+				return err
+			}
+			imported[table] = int64(n)
+			log.Infof("Imported %d rows into %s", n, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"GoopBot/internal/models"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	src, dst := migratedTestDB(t), migratedTestDB(t)
+
+	year := 1990
+	src.Create(&models.GoopCreator{DiscordID: "1", TwitchUsername: "goopy", IsActive: true})
+	src.Create(&models.GoopCreator{DiscordID: "2", TwitchUsername: "gone", IsActive: true})
+	src.Where("discord_id = ?", "2").Delete(&models.GoopCreator{})
+	src.Create(&models.Birthday{DiscordID: "1", GuildID: "100", Month: 3, Day: 15, Year: &year})
+	src.Create(&models.DashboardToken{GuildID: "100", TokenHash: "abc", ExpiresAt: time.Now().Add(time.Hour)})
+
+	var buf bytes.Buffer
+	written, err := WriteExport(ctx, src, &buf)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if written["goop_creators"] != 2 || written["birthdays"] != 1 {
+		t.Fatalf("unexpected exported row counts: %v", written)
+	}
+	if strings.Contains(buf.String(), "dashboard_tokens") {
+		t.Fatal("export includes dashboard logins")
+	}
+	data := buf.Bytes()
+
+	imported, err := Import(ctx, dst, bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if imported["goop_creators"] != 2 || imported["birthdays"] != 1 {
+		t.Fatalf("unexpected imported row counts: %v", imported)
+	}
+
+	var creators []models.GoopCreator
+	dst.Unscoped().Order("id").Find(&creators)
+	if len(creators) != 2 || creators[1].ID != 2 || !creators[1].DeletedAt.Valid {
+		t.Fatalf("unexpected creators after import: %+v", creators)
+	}
+	var birthday models.Birthday
+	dst.First(&birthday)
+	if birthday.Year == nil || *birthday.Year != 1990 || birthday.Day != 15 {
+		t.Fatalf("unexpected birthday after import: %+v", birthday)
+	}
+
+	if _, err := Import(ctx, dst, bytes.NewReader(data), nil); err == nil || !strings.Contains(err.Error(), "empty database") {
+		t.Fatalf("expected importing into a non-empty database to fail, got %v", err)
+	}
+}
+
+func TestImportRequiresSameSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	if _, err := WriteExport(ctx, migratedTestDB(t), &buf); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	dst := openTestDB(t)
+	if _, err := Import(ctx, dst, &buf, nil); err == nil || !strings.Contains(err.Error(), "migrate the database") {
+		t.Fatalf("expected a schema version error, got %v", err)
+	}
+}
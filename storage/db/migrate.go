@@ -0,0 +1,244 @@
+package db
+
+import (
+	"GoopBot/internal/logging"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one numbered schema change. Up applies it and Down reverts it; both run
+// in a transaction together with the schema_migrations bookkeeping.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationStatus describes a migration and whether it has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // Nil while pending
+}
+
+// schemaMigration is a row of the schema_migrations table
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and reverts migrations on a database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	log        logging.Logger
+}
+
+// NewMigrator returns a migrator for the given migrations, usually Migrations().
+// A nil logger discards log output.
+func NewMigrator(db *gorm.DB, migrations []Migration, logger logging.Logger) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version <= 0 || m.Up == nil || m.Down == nil {
+			return nil, fmt.Errorf("migration %d (%s) needs a positive version and up and down steps", m.Version, m.Name)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("migration version %d is used twice", m.Version)
+		}
+	}
+	return &Migrator{db: db, migrations: sorted, log: logging.OrNop(logger)}, nil
+}
+
+// migrationLockID identifies the Postgres advisory lock held while migrating, so bot
+// instances sharing a database do not apply the same migration at once
+const migrationLockID = 4_702_318_611
+
+// locked runs fn while holding the migration lock. SQLite locks the whole file on write,
+// so only Postgres needs one.
+func (m *Migrator) locked(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if !IsPostgres(db) {
+		return fn(db)
+	}
+
+	// Advisory locks belong to a session, so lock, migrate and unlock on one connection
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		return fn(conn)
+	})
+}
+
+// createTrackingTable creates the schema_migrations table if it doesn't exist yet
+func createTrackingTable(db *gorm.DB) error {
+	if err := db.Migrator().AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// applied returns the applied migrations by version. It only reads, so a database without
+// the tracking table has none applied.
+func (m *Migrator) applied(db *gorm.DB) (map[int]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[int]schemaMigration{}, nil
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	// Refuse to touch a database migrated by a newer build
+	for version := range applied {
+		if m.find(version) == nil {
+			return nil, fmt.Errorf("database has migration %d applied, which this build does not know; upgrade GoopBot first", version)
+		}
+	}
+	return applied, nil
+}
+
+// find returns the migration with this version, or nil
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// Version returns the highest applied migration version, or 0 for an empty database
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Latest returns the version the database has once every migration is applied
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists every migration in version order with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i] = MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			at := row.AppliedAt
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration in version order and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		if err := createTrackingTable(db); err != nil {
+			return err
+		}
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := mig.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d (%s): %w", mig.Version, mig.Name, err)
+			}
+			m.log.Infof("Applied migration %d: %s", mig.Version, mig.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverts the most recently applied migrations, at most steps of them, and returns
+// how many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		if err := createTrackingTable(db); err != nil {
+			return err
+		}
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := mig.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, mig.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to revert migration %d (%s): %w", mig.Version, mig.Name, err)
+			}
+			m.log.Infof("Reverted migration %d: %s", mig.Version, mig.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Migrate brings db up to date with the built-in migrations
+func Migrate(ctx context.Context, db *gorm.DB, logger logging.Logger) error {
+	migrator, err := NewMigrator(db, Migrations(), logger)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(ctx)
+	return err
+}
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"GoopBot/internal/dbtest"
+	"GoopBot/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB opens an empty database, see dbtest.Open
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return dbtest.Open(t)
+}
+
+// table returns a migration that creates and drops a one-column table
+func table(version int, name string) Migration {
+	return Migration{
+		Version: version,
+		Name:    "create_" + name,
+		Up:      func(tx *gorm.DB) error { return tx.Exec("CREATE TABLE " + name + " (id INTEGER)").Error },
+		Down:    func(tx *gorm.DB) error { return tx.Exec("DROP TABLE " + name).Error },
+	}
+}
+
+func TestUpDownAndStatus(t *testing.T) {
+	conn := openTestDB(t)
+	ctx := context.Background()
+
+	m, err := NewMigrator(conn, []Migration{table(2, "b"), table(1, "a"), table(3, "c")}, nil)
+	if err != nil {
+		t.Fatalf("new migrator: %v", err)
+	}
+
+	// Reading the version of an empty database doesn't create the tracking table
+	if v, err := m.Version(ctx); err != nil || v != 0 {
+		t.Fatalf("version of an empty database %d (err %v), want 0", v, err)
+	}
+	if statuses, err := m.Status(ctx); err != nil || len(statuses) != 3 || statuses[0].AppliedAt != nil {
+		t.Fatalf("unexpected status of an empty database: %+v (err %v)", statuses, err)
+	}
+	if conn.Migrator().HasTable("schema_migrations") {
+		t.Fatal("a read-only check created schema_migrations")
+	}
+
+	if n, err := m.Up(ctx); err != nil || n != 3 {
+		t.Fatalf("up applied %d (err %v), want 3", n, err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("second up applied %d (err %v), want 0", n, err)
+	}
+	if v, _ := m.Version(ctx); v != 3 {
+		t.Fatalf("version %d, want 3", v)
+	}
+
+	if n, err := m.Down(ctx, 2); err != nil || n != 2 {
+		t.Fatalf("down reverted %d (err %v), want 2", n, err)
+	}
+	if conn.Migrator().HasTable("b") || conn.Migrator().HasTable("c") || !conn.Migrator().HasTable("a") {
+		t.Fatal("down reverted the wrong migrations")
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(statuses) != 3 || statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil || statuses[2].AppliedAt != nil {
+		t.Fatalf("unexpected status: %+v", statuses)
+	}
+	if statuses[0].Version != 1 || statuses[2].Name != "create_c" {
+		t.Fatalf("status is not in version order: %+v", statuses)
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	conn := openTestDB(t)
+	ctx := context.Background()
+
+	broken := Migration{
+		Version: 2,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE half (id INTEGER)").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+		Down: func(tx *gorm.DB) error { return nil },
+	}
+	m, _ := NewMigrator(conn, []Migration{table(1, "a"), broken}, nil)
+
+	n, err := m.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "migration 2 (broken)") || n != 1 {
+		t.Fatalf("up applied %d with err %v, want 1 and a migration 2 error", n, err)
+	}
+	if conn.Migrator().HasTable("half") {
+		t.Fatal("failed migration was not rolled back")
+	}
+	if v, _ := m.Version(ctx); v != 1 {
+		t.Fatalf("version %d, want 1", v)
+	}
+}
+
+func TestRefusesDatabaseFromNewerBuild(t *testing.T) {
+	conn := openTestDB(t)
+	ctx := context.Background()
+
+	newer, _ := NewMigrator(conn, []Migration{table(1, "a"), table(2, "b")}, nil)
+	if _, err := newer.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	older, _ := NewMigrator(conn, []Migration{table(1, "a")}, nil)
+	if _, err := older.Up(ctx); err == nil || !strings.Contains(err.Error(), "upgrade GoopBot") {
+		t.Fatalf("expected an error for an unknown applied migration, got %v", err)
+	}
+}
+
+func TestNewMigratorRejectsInvalidMigrations(t *testing.T) {
+	conn := openTestDB(t)
+
+	if _, err := NewMigrator(conn, []Migration{table(1, "a"), table(1, "b")}, nil); err == nil {
+		t.Fatal("expected an error for a duplicate version")
+	}
+	if _, err := NewMigrator(conn, []Migration{{Version: 1, Name: "no_down", Up: func(*gorm.DB) error { return nil }}}, nil); err == nil {
+		t.Fatal("expected an error for a migration without a down step")
+	}
+}
+
+// allModels are the records the built-in migrations must create tables for
+var allModels = models.All()
+
+func TestMigrationsMatchModels(t *testing.T) {
+	conn := openTestDB(t)
+	if err := Migrate(context.Background(), conn, nil); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// Every model field needs a column, so the models and the latest migration stay in sync
+	for _, model := range allModels {
+		stmt := &gorm.Statement{DB: conn}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !conn.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("%s has no column %s for %T.%s; add a migration", stmt.Schema.Table, field.DBName, model, field.Name)
+			}
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if !conn.Migrator().HasIndex(model, idx.Name) {
+				t.Errorf("%s has no index %s; add a migration", stmt.Schema.Table, idx.Name)
+			}
+		}
+	}
+
+	// Reverting the initial schema removes every table
+	m, _ := NewMigrator(conn, Migrations(), nil)
+	if _, err := m.Down(context.Background(), len(Migrations())); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	for _, model := range allModels {
+		if conn.Migrator().HasTable(model) {
+			t.Errorf("table for %T still exists after reverting every migration", model)
+		}
+	}
+}
+
+func TestAdoptsAutoMigratedDatabase(t *testing.T) {
+	conn := openTestDB(t)
+	ctx := context.Background()
+
+	// Databases from before versioned migrations were created by AutoMigrate with the
+	// models of the time, which migration 1 snapshots
+	if err := conn.AutoMigrate(v1Tables...); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	if err := conn.Create(&models.GoopCreator{DiscordID: "1", TwitchUsername: "goopy", IsActive: true}).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	if err := Migrate(ctx, conn, nil); err != nil {
+		t.Fatalf("migrate existing database: %v", err)
+	}
+	var count int64
+	conn.Model(&models.GoopCreator{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("existing data was lost, %d creators left", count)
+	}
+	m, _ := NewMigrator(conn, Migrations(), nil)
+	if v, _ := m.Version(ctx); v != m.Latest() {
+		t.Fatalf("version %d, want %d", v, m.Latest())
+	}
+}
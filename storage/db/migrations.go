@@ -0,0 +1,200 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migrations returns the built-in schema migrations in version order.
+//
+// Migrations describe the schema with their own snapshot types instead of the structs in
+// internal/models, so a migration keeps doing the same thing when the models change later.
+// To change the schema, append a migration with the next version; never edit one that has shipped.
+func Migrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "initial_schema", Up: initialSchemaUp, Down: initialSchemaDown},
+		{Version: 2, Name: "dashboard_tokens", Up: dashboardTokensUp, Down: dashboardTokensDown},
+		{Version: 3, Name: "audit_log", Up: auditLogUp, Down: auditLogDown},
+		{Version: 4, Name: "permission_rules", Up: permissionRulesUp, Down: permissionRulesDown},
+	}
+}
+
+// Snapshots of the tables as of migration 1
+
+type v1GoopCreator struct {
+	gorm.Model
+	DiscordID      string `gorm:"uniqueIndex"`
+	Username       string
+	GuildID        string
+	TwitchUsername string
+	IsActive       bool
+}
+
+func (v1GoopCreator) TableName() string { return "goop_creators" }
+
+type v1TwitchStream struct {
+	gorm.Model
+	TwitchUsername string `gorm:"uniqueIndex"`
+	IsLive         bool
+	LastChecked    *time.Time
+	ViewerCount    int
+	GameName       string
+	StreamTitle    string
+	DiscordID      string
+}
+
+func (v1TwitchStream) TableName() string { return "twitch_streams" }
+
+type v1NotificationChannel struct {
+	gorm.Model
+	GuildID   string
+	ChannelID string `gorm:"uniqueIndex"`
+	IsActive  bool
+}
+
+func (v1NotificationChannel) TableName() string { return "notification_channels" }
+
+type v1Birthday struct {
+	gorm.Model
+	DiscordID string `gorm:"uniqueIndex"`
+	Username  string
+	GuildID   string
+	Month     int
+	Day       int
+	Year      *int
+	LastSent  time.Time
+}
+
+func (v1Birthday) TableName() string { return "birthdays" }
+
+type v1BirthdayChannel struct {
+	gorm.Model
+	GuildID   string
+	ChannelID string `gorm:"uniqueIndex"`
+	IsActive  bool
+}
+
+func (v1BirthdayChannel) TableName() string { return "birthday_channels" }
+
+type v1RoleMessage struct {
+	gorm.Model
+	GuildID   string
+	ChannelID string
+	MessageID string `gorm:"uniqueIndex"`
+	RoleName  string
+	IsActive  bool
+}
+
+func (v1RoleMessage) TableName() string { return "role_messages" }
+
+type v1GuildSettings struct {
+	gorm.Model
+	GuildID               string `gorm:"uniqueIndex"`
+	CreatorRole           string
+	MemberRole            string
+	CommandPrefix         string
+	EnabledModules        *string
+	NotificationChannelID string
+}
+
+func (v1GuildSettings) TableName() string { return "guild_settings" }
+
+var v1Tables = []interface{}{
+	&v1GoopCreator{}, &v1TwitchStream{}, &v1NotificationChannel{}, &v1Birthday{},
+	&v1BirthdayChannel{}, &v1RoleMessage{}, &v1GuildSettings{},
+}
+
+// initialSchemaUp creates the tables. Databases from before migrations existed were built
+// by AutoMigrate and already have them, so existing tables are brought up to the same
+// columns and indexes instead of failing.
+func initialSchemaUp(tx *gorm.DB) error {
+	return tx.Migrator().AutoMigrate(v1Tables...)
+}
+
+func initialSchemaDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(v1Tables...)
+}
+
+// Snapshot of the table added in migration 2
+
+type v2DashboardToken struct {
+	gorm.Model
+	GuildID   string `gorm:"index"`
+	TokenHash string `gorm:"uniqueIndex"`
+	IssuedBy  string
+	ExpiresAt time.Time
+}
+
+func (v2DashboardToken) TableName() string { return "dashboard_tokens" }
+
+func dashboardTokensUp(tx *gorm.DB) error {
+	return tx.Migrator().CreateTable(&v2DashboardToken{})
+}
+
+func dashboardTokensDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&v2DashboardToken{})
+}
+
+// Snapshots of the table and column added in migration 3
+
+type v3AuditEntry struct {
+	gorm.Model
+	GuildID   string `gorm:"index"`
+	ActorID   string
+	ActorName string
+	Action    string
+	Before    string
+	After     string
+	Via       string
+}
+
+func (v3AuditEntry) TableName() string { return "audit_entries" }
+
+type v3GuildSettings struct {
+	gorm.Model
+	GuildID               string `gorm:"uniqueIndex"`
+	CreatorRole           string
+	MemberRole            string
+	CommandPrefix         string
+	EnabledModules        *string
+	NotificationChannelID string
+	ModLogChannelID       string
+}
+
+func (v3GuildSettings) TableName() string { return "guild_settings" }
+
+func auditLogUp(tx *gorm.DB) error {
+	if err := tx.Migrator().CreateTable(&v3AuditEntry{}); err != nil {
+		return err
+	}
+	return tx.Migrator().AddColumn(&v3GuildSettings{}, "ModLogChannelID")
+}
+
+func auditLogDown(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&v3GuildSettings{}, "ModLogChannelID"); err != nil {
+		return err
+	}
+	return tx.Migrator().DropTable(&v3AuditEntry{})
+}
+
+// Snapshot of the table added in migration 4
+
+type v4PermissionRule struct {
+	gorm.Model
+	GuildID     string `gorm:"uniqueIndex:idx_permission_rule"`
+	Target      string `gorm:"uniqueIndex:idx_permission_rule"`
+	SubjectType string
+	SubjectID   string `gorm:"uniqueIndex:idx_permission_rule"`
+	Allow       bool
+}
+
+func (v4PermissionRule) TableName() string { return "permission_rules" }
+
+func permissionRulesUp(tx *gorm.DB) error {
+	return tx.Migrator().CreateTable(&v4PermissionRule{})
+}
+
+func permissionRulesDown(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&v4PermissionRule{})
+}
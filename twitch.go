@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"GoopBot/internal/twitch"
+)
+
+const checkTwitchUsage = `usage: goopbot check-twitch <user...> [flags]
+
+Asks the Twitch API whether each user is live, using TWITCH_CLIENT_ID and TWITCH_CLIENT_SECRET
+from the configuration.`
+
+// runCheckTwitch implements the check-twitch command and returns the process exit code
+func runCheckTwitch(args []string) int {
+	users, flags := splitArgs(args)
+	if len(users) == 0 {
+		fmt.Fprintln(os.Stderr, checkTwitchUsage)
+		return 2
+	}
+	if len(users) > 100 {
+		fmt.Fprintln(os.Stderr, "Twitch can check at most 100 users at once")
+		return 2
+	}
+
+	cfg, logger, err := setup(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfg.Twitch.ClientID == "" || cfg.Twitch.ClientSecret == "" {
+		fmt.Fprintln(os.Stderr, "Twitch credentials are required (set TWITCH_CLIENT_ID and TWITCH_CLIENT_SECRET)")
+		return 2
+	}
+
+	client, err := twitch.NewClient(twitch.Config{
+		ClientID:     cfg.Twitch.ClientID,
+		ClientSecret: cfg.Twitch.ClientSecret,
+		Logger:       logger,
+	})
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	streams, err := client.GetMultipleStreams(ctx, users)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return 1
+	}
+
+	live := make(map[string]twitch.StreamData, len(streams))
+	for _, stream := range streams {
+		live[strings.ToLower(stream.UserLogin)] = stream
+	}
+	for _, user := range users {
+		stream, ok := live[strings.ToLower(user)]
+		if !ok {
+			fmt.Printf("⚫ %s is offline\n", user)
+			continue
+		}
+		fmt.Printf("🔴 %s is LIVE!\n", stream.UserName)
+		fmt.Printf("   Title: %s\n", stream.Title)
+		fmt.Printf("   Game: %s\n", stream.GameName)
+		fmt.Printf("   Viewers: %d\n", stream.ViewerCount)
+	}
+	return 0
+}